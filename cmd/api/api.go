@@ -1,48 +1,136 @@
 package api
 
 import (
-	"database/sql"
+	"bytes"
+	"context"
+	"football-simulation/config"
+	"football-simulation/service/auth"
+	"football-simulation/service/calendar"
+	"football-simulation/service/event"
+	"football-simulation/service/export"
+	"football-simulation/service/fixture"
+	"football-simulation/service/job"
 	"football-simulation/service/league"
+	"football-simulation/service/openapi"
+	"football-simulation/service/playoff"
+	"football-simulation/service/sanction"
+	"football-simulation/service/schedule"
 	"football-simulation/service/simulation"
+	"football-simulation/service/snapshot"
 	"football-simulation/service/team"
+	"football-simulation/service/webhook"
+	"football-simulation/types"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
+// basePath is the prefix of every API route.
+const basePath = "/api/v1"
+
 type APIServer struct {
-	addr string
-	db   *sql.DB
+	addr   string
+	stores Stores
 }
 
-func NewAPIServer(addr string, db *sql.DB) *APIServer {
+func NewAPIServer(addr string, stores Stores) *APIServer {
 	return &APIServer{
-		addr: addr,
-		db:   db,
+		addr:   addr,
+		stores: stores,
 	}
 }
 
 func (s *APIServer) Run() error {
 	router := mux.NewRouter()
-	subRouter := router.PathPrefix("/api/v1").Subrouter()
+	subRouter := router.PathPrefix(basePath).Subrouter()
 
 	//Store
-	teamStore := team.NewStore(s.db)
-	leagueStore := league.NewStore(s.db)
-	simulationStore := simulation.NewStore(s.db)
+	transactor := s.stores.Transactor
+	teamStore := s.stores.Team
+	leagueStore := s.stores.League
+	simulationStore := s.stores.Simulation
+	sanctionStore := s.stores.Sanction
+	eventStore := s.stores.Event
+	webhookStore := s.stores.Webhook
+	scheduleStore := s.stores.Schedule
+	jobStore := s.stores.Job
+	calendarStore := s.stores.Calendar
+	fixtureStore := s.stores.Fixture
+	playoffStore := s.stores.Playoff
 
 	//Service
-	teamService := team.NewService(teamStore)
+	teamService := team.NewService(teamStore, leagueStore, eventStore, transactor)
 	simulationService := simulation.NewService(simulationStore)
-	leagueService := league.NewService(leagueStore, simulationService, teamService)
+	webhookService := webhook.NewService(webhookStore)
+	calendarService := calendar.NewService(calendarStore, leagueStore, teamStore, transactor)
+	fixtureService := fixture.NewService(fixtureStore, leagueStore, teamStore, simulationService)
+	leagueService := league.NewService(leagueStore, simulationService, teamService, eventStore, webhookService, calendarService, fixtureService, transactor)
+	playoffService := playoff.NewService(playoffStore, leagueStore, simulationService, eventStore, transactor)
+	sanctionService := sanction.NewService(sanctionStore, leagueStore, teamService, eventStore, webhookService, transactor)
+	eventService := event.NewService(eventStore, leagueStore, teamStore, transactor)
+	exportService := export.NewService(leagueStore, teamService)
+	snapshotService := snapshot.NewService(leagueStore, teamStore, simulationStore, playoffStore, eventStore, transactor)
+	scheduleService := schedule.NewService(scheduleStore, leagueService)
+	jobService := job.NewService(jobStore, leagueService)
+	authService := auth.NewService(s.stores.APIKey)
+
+	if key := config.Envs.AdminKey; key != "" {
+		// always the same entry, so a rotated key replaces the previous one
+		if err := authService.EnsureKey("AUTH_ADMIN_KEY", key, types.RoleAdmin); err != nil {
+			return err
+		}
+	}
+
+	if s.stores.SeedTeams {
+		if err := seedTeams(teamService); err != nil {
+			return err
+		}
+	}
 
 	//Handler
 	teamHandler := team.NewHandler(teamService)
 	leagueHandler := league.NewHandler(leagueService)
+	sanctionHandler := sanction.NewHandler(sanctionService)
+	eventHandler := event.NewHandler(eventService)
+	exportHandler := export.NewHandler(exportService)
+	snapshotHandler := snapshot.NewHandler(snapshotService)
+	webhookHandler := webhook.NewHandler(webhookService)
+	scheduleHandler := schedule.NewHandler(scheduleService)
+	jobHandler := job.NewHandler(jobService)
+	calendarHandler := calendar.NewHandler(calendarService)
+	fixtureHandler := fixture.NewHandler(fixtureService)
+	playoffHandler := playoff.NewHandler(playoffService)
+	openapiHandler := openapi.NewHandler()
+
+	validator, err := openapi.NewValidator()
+	if err != nil {
+		return err
+	}
+	authMiddleware := auth.NewMiddleware(authService, basePath, config.Envs.AnonymousReads)
+	subRouter.Use(authMiddleware.Authorize, validator.Middleware)
 
 	leagueHandler.RegisterRoutes(subRouter)
 	teamHandler.RegisterRoutes(subRouter)
+	sanctionHandler.RegisterRoutes(subRouter)
+	eventHandler.RegisterRoutes(subRouter)
+	exportHandler.RegisterRoutes(subRouter)
+	snapshotHandler.RegisterRoutes(subRouter)
+	webhookHandler.RegisterRoutes(subRouter)
+	scheduleHandler.RegisterRoutes(subRouter)
+	jobHandler.RegisterRoutes(subRouter)
+	calendarHandler.RegisterRoutes(subRouter)
+	fixtureHandler.RegisterRoutes(subRouter)
+	playoffHandler.RegisterRoutes(subRouter)
+	openapiHandler.RegisterRoutes(subRouter)
+
+	dispatcher := webhook.NewDispatcher(webhookStore, time.Second, config.Envs.WebhookTimeout, config.Envs.WebhookBackoff)
+	go dispatcher.Run(context.Background())
+
+	scheduler := schedule.NewScheduler(scheduleStore, leagueService, time.Second)
+	go scheduler.Run(context.Background())
+	go jobService.Run(context.Background())
 
 	log.Println("Listening on", s.addr)
 
@@ -52,3 +140,14 @@ func (s *APIServer) Run() error {
 	}
 	return nil
 }
+
+// seedTeams imports the bundled teams into a database without teams.
+func seedTeams(teamService types.TeamService) error {
+	teams, err := teamService.GetTeams()
+	if err != nil || len(teams) > 0 {
+		return err
+	}
+
+	_, err = teamService.ImportTeams(team.ImportFormatJSON, bytes.NewReader(team.DefaultTeams), types.TeamImportOptions{})
+	return err
+}
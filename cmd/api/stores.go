@@ -0,0 +1,105 @@
+package api
+
+import (
+	"database/sql"
+	"football-simulation/database"
+	"football-simulation/database/memory"
+	"football-simulation/service/auth"
+	"football-simulation/service/calendar"
+	"football-simulation/service/event"
+	"football-simulation/service/fixture"
+	"football-simulation/service/job"
+	"football-simulation/service/league"
+	"football-simulation/service/playoff"
+	"football-simulation/service/sanction"
+	"football-simulation/service/schedule"
+	"football-simulation/service/simulation"
+	"football-simulation/service/team"
+	"football-simulation/service/webhook"
+	"football-simulation/types"
+)
+
+// Stores is the storage backend the server runs on.
+type Stores struct {
+	Transactor types.Transactor
+	League     types.LeagueStore
+	Team       types.Teamstore
+	Simulation types.SimulationStore
+	Sanction   types.SanctionStore
+	Event      types.LeagueEventStore
+	APIKey     types.APIKeyStore
+	Webhook    types.WebhookStore
+	Schedule   types.ScheduleStore
+	Job        types.JobStore
+	Calendar   types.CalendarStore
+	Fixture    types.FixtureStore
+	Playoff    types.PlayoffStore
+
+	// SeedTeams loads the bundled default teams on start when there are no
+	// teams yet, for backends that are not set up with make seed.
+	SeedTeams bool
+}
+
+func NewPostgresStores(db *sql.DB) Stores {
+	return Stores{
+		Transactor: database.NewTransactor(db),
+		League:     league.NewStore(db),
+		Team:       team.NewStore(db),
+		Simulation: simulation.NewStore(db),
+		Sanction:   sanction.NewStore(db),
+		Event:      event.NewStore(db),
+		APIKey:     auth.NewStore(db),
+		Webhook:    webhook.NewStore(db),
+		Schedule:   schedule.NewStore(db),
+		Job:        job.NewStore(db),
+		Calendar:   calendar.NewStore(db),
+		Fixture:    fixture.NewStore(db),
+		Playoff:    playoff.NewStore(db),
+	}
+}
+
+// NewSQLiteStores returns stores on an SQLite database whose migrations
+// have been applied.
+func NewSQLiteStores(db *sql.DB) Stores {
+	conn := database.SQLite(db)
+
+	return Stores{
+		Transactor: database.NewSQLiteTransactor(db),
+		League:     league.NewStore(conn),
+		Team:       team.NewStore(conn),
+		Simulation: simulation.NewStore(conn),
+		Sanction:   sanction.NewStore(conn),
+		Event:      event.NewStore(conn),
+		APIKey:     auth.NewStore(conn),
+		Webhook:    webhook.NewStore(conn),
+		Schedule:   schedule.NewStore(conn),
+		Job:        job.NewStore(conn),
+		Calendar:   calendar.NewStore(conn),
+		Fixture:    fixture.NewStore(conn),
+		Playoff:    playoff.NewStore(conn),
+		SeedTeams:  true,
+	}
+}
+
+// NewMemoryStores returns stores on a fresh in-memory database seeded with
+// the default teams. Nothing survives a restart.
+func NewMemoryStores() Stores {
+	db := memory.NewDB()
+
+	return Stores{
+		Transactor: memory.NewTransactor(db),
+		League:     memory.NewLeagueStore(db),
+		Team:       memory.NewTeamStore(db),
+		Simulation: memory.NewSimulationStore(db),
+		Sanction:   memory.NewSanctionStore(db),
+		Event:      memory.NewEventStore(db),
+		APIKey:     memory.NewAPIKeyStore(db),
+		Webhook:    memory.NewWebhookStore(db),
+		Schedule:   memory.NewScheduleStore(db),
+		Job:        memory.NewJobStore(db),
+		Calendar:   memory.NewCalendarStore(db),
+		Fixture:    memory.NewFixtureStore(db),
+		Playoff:    memory.NewPlayoffStore(db),
+		SeedTeams:  true,
+	}
+}
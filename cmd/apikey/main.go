@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"football-simulation/config"
+	"football-simulation/database"
+	"football-simulation/service/auth"
+	"football-simulation/types"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+const usage = `usage:
+  apikey create -name <name> -role viewer|operator|admin
+  apikey list
+  apikey revoke -id <id>`
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	service := auth.NewService(openStore())
+
+	switch os.Args[1] {
+	case "create":
+		flags := flag.NewFlagSet("create", flag.ExitOnError)
+		name := flags.String("name", "", "name of the client the key is for")
+		role := flags.String("role", types.RoleViewer, "viewer, operator or admin")
+		flags.Parse(os.Args[2:])
+
+		key, apiKey, err := service.CreateKey(*name, *role)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("created key %d %q with the %s role\n", apiKey.ID, apiKey.Name, apiKey.Role)
+		fmt.Println("store it now, it cannot be shown again:")
+		fmt.Println(key)
+
+	case "list":
+		keys, err := service.GetKeys()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tROLE\tCREATED\tREVOKED")
+		for _, key := range keys {
+			revoked := "-"
+			if key.RevokedAt != nil {
+				revoked = key.RevokedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", key.ID, key.Name, key.Role, key.CreatedAt.Format(time.RFC3339), revoked)
+		}
+		w.Flush()
+
+	case "revoke":
+		flags := flag.NewFlagSet("revoke", flag.ExitOnError)
+		id := flags.Int("id", 0, "id of the key to revoke")
+		flags.Parse(os.Args[2:])
+
+		if *id == 0 {
+			log.Fatal(usage)
+		}
+
+		if err := service.RevokeKey(*id); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("revoked key %d\n", *id)
+
+	default:
+		log.Fatal(usage)
+	}
+}
+
+// openStore opens the database selected by DB_DRIVER. Keys of the in-memory
+// backend only live in the server process, so it has no CLI.
+func openStore() types.APIKeyStore {
+	switch config.Envs.Driver {
+	case "postgres":
+		db, err := database.NewPostgreSQLStorage(database.DBConfig{
+			User:     config.Envs.User,
+			Password: config.Envs.Password,
+			DBName:   config.Envs.DBName,
+			Host:     config.Envs.Host,
+			DBPort:   config.Envs.DBPort,
+			SSLMode:  config.Envs.SSLMode,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		return auth.NewStore(db)
+
+	case "sqlite":
+		db, err := database.NewSQLiteStorage(config.Envs.Path)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := database.MigrateSQLite(db, "up"); err != nil {
+			log.Fatal(err)
+		}
+		return auth.NewStore(database.SQLite(db))
+	}
+
+	log.Fatalf("API keys cannot be managed with DB_DRIVER %q, use AUTH_ADMIN_KEY instead", config.Envs.Driver)
+	return nil
+}
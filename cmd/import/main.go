@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"football-simulation/config"
+	"football-simulation/database"
+	"football-simulation/service/event"
+	"football-simulation/service/footballdata"
+	"football-simulation/service/league"
+	"football-simulation/service/simulation"
+	"football-simulation/service/team"
+	"football-simulation/types"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const usage = `usage:
+  import teams -file <path> [-format csv|json] [-dry-run] [-upsert]
+  import seed [-dry-run]
+  import results -file <path> [-league-name <name>] [-update-strength] [-dry-run]`
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	if os.Args[1] == "results" {
+		importResults(os.Args[2:])
+		return
+	}
+
+	var (
+		input   io.Reader
+		format  string
+		options types.TeamImportOptions
+	)
+
+	switch os.Args[1] {
+	case "teams":
+		flags := flag.NewFlagSet("teams", flag.ExitOnError)
+		file := flags.String("file", "", "CSV or JSON file to import")
+		formatFlag := flags.String("format", "", "csv or json, detected from the file extension when omitted")
+		flags.BoolVar(&options.DryRun, "dry-run", false, "validate and report without writing")
+		flags.BoolVar(&options.Upsert, "upsert", false, "update teams that already exist instead of rejecting them")
+		flags.Parse(os.Args[2:])
+
+		if *file == "" {
+			log.Fatal(usage)
+		}
+
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+
+		input = f
+		format = *formatFlag
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+		}
+
+	case "seed":
+		flags := flag.NewFlagSet("seed", flag.ExitOnError)
+		flags.BoolVar(&options.DryRun, "dry-run", false, "validate and report without writing")
+		flags.Parse(os.Args[2:])
+
+		input = bytes.NewReader(team.DefaultTeams)
+		format = team.ImportFormatJSON
+		options.Upsert = true
+
+	default:
+		log.Fatal(usage)
+	}
+
+	db, transactor := connect()
+	teamService := team.NewService(team.NewStore(db), league.NewStore(db), event.NewStore(db), transactor)
+
+	report, err := teamService.ImportTeams(format, input, options)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	printReport(report, len(report.Errors))
+}
+
+// importResults loads a completed season from a football-data.co.uk CSV.
+func importResults(args []string) {
+	var options types.SeasonImportOptions
+
+	flags := flag.NewFlagSet("results", flag.ExitOnError)
+	file := flags.String("file", "", "football-data.co.uk season CSV to import")
+	flags.StringVar(&options.LeagueName, "league-name", "", "rename the league, e.g. \"Premier League 2023/24\"")
+	flags.BoolVar(&options.UpdateStrength, "update-strength", false, "recalculate the strength of existing teams from the season")
+	flags.BoolVar(&options.DryRun, "dry-run", false, "validate and report without writing")
+	flags.Parse(args)
+
+	if *file == "" {
+		log.Fatal(usage)
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	db, transactor := connect()
+	leagueStore := league.NewStore(db)
+	eventStore := event.NewStore(db)
+	teamService := team.NewService(team.NewStore(db), leagueStore, eventStore, transactor)
+	importService := footballdata.NewService(leagueStore, simulation.NewStore(db), teamService, eventStore, transactor)
+
+	report, err := importService.ImportSeason(f, options)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	printReport(report, len(report.Errors))
+}
+
+// connect opens the database selected by DB_DRIVER and returns it with a
+// transactor for it. The in-memory backend only lives in the server process,
+// so there is nothing to import into.
+func connect() (types.DBTX, types.Transactor) {
+	switch config.Envs.Driver {
+	case "postgres":
+		db, err := database.NewPostgreSQLStorage(database.DBConfig{
+			User:     config.Envs.User,
+			Password: config.Envs.Password,
+			DBName:   config.Envs.DBName,
+			Host:     config.Envs.Host,
+			DBPort:   config.Envs.DBPort,
+			SSLMode:  config.Envs.SSLMode,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		return db, database.NewTransactor(db)
+
+	case "sqlite":
+		db, err := database.NewSQLiteStorage(config.Envs.Path)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := database.MigrateSQLite(db, "up"); err != nil {
+			log.Fatal(err)
+		}
+		return database.SQLite(db), database.NewSQLiteTransactor(db)
+	}
+
+	log.Fatalf("cannot import with DB_DRIVER %q, use postgres or sqlite", config.Envs.Driver)
+	return nil, nil
+}
+
+// printReport writes the import report as JSON and exits with a non-zero
+// status when rows were rejected.
+func printReport(report any, errorCount int) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+
+	if errorCount > 0 {
+		os.Exit(1)
+	}
+}
@@ -8,23 +8,50 @@ import (
 )
 
 func main() {
+	var stores api.Stores
 
-	dbConfig := database.DBConfig{
-		User:     config.Envs.User,
-		Password: config.Envs.Password,
-		DBName:   config.Envs.DBName,
-		Host:     config.Envs.Host,
-		DBPort:   config.Envs.DBPort,
-		SSLMode:  config.Envs.SSLMode,
-	}
+	switch config.Envs.Driver {
+	case "postgres":
+		dbConfig := database.DBConfig{
+			User:     config.Envs.User,
+			Password: config.Envs.Password,
+			DBName:   config.Envs.DBName,
+			Host:     config.Envs.Host,
+			DBPort:   config.Envs.DBPort,
+			SSLMode:  config.Envs.SSLMode,
+		}
 
-	db, err := database.NewPostgreSQLStorage(dbConfig)
+		db, err := database.NewPostgreSQLStorage(dbConfig)
 
-	if err != nil {
-		log.Fatal(err)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		stores = api.NewPostgresStores(db)
+
+	case "sqlite":
+		db, err := database.NewSQLiteStorage(config.Envs.Path)
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		//the migrations are embedded, so the binary sets up its own database
+		if err := database.MigrateSQLite(db, "up"); err != nil {
+			log.Fatal(err)
+		}
+
+		stores = api.NewSQLiteStores(db)
+
+	case "memory":
+		log.Println("DB: Using the in-memory store, data is lost on exit")
+		stores = api.NewMemoryStores()
+
+	default:
+		log.Fatalf("unknown DB_DRIVER %q, expected postgres, sqlite or memory", config.Envs.Driver)
 	}
 
-	server := api.NewAPIServer(":8080", db)
+	server := api.NewAPIServer(":8080", stores)
 
 	if err := server.Run(); err != nil {
 		log.Fatal(err)
@@ -12,6 +12,20 @@ import (
 )
 
 func main() {
+	cmd := os.Args[(len(os.Args) - 1)]
+
+	if config.Envs.Driver == "sqlite" {
+		db, err := database.NewSQLiteStorage(config.Envs.Path)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := database.MigrateSQLite(db, cmd); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	db, err := database.NewPostgreSQLStorage(database.DBConfig{
 		User:     config.Envs.User,
 		Password: config.Envs.Password,
@@ -40,8 +54,6 @@ func main() {
 		log.Fatal(err)
 	}
 
-	cmd := os.Args[(len(os.Args) - 1)]
-
 	if cmd == "up" {
 		if err := m.Up(); err != nil && err != migrate.ErrNoChange {
 			log.Fatal(err)
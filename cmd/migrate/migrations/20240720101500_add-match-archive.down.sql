@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS archived_matches;
+
+ALTER TABLE league DROP COLUMN IF EXISTS season;
\ No newline at end of file
@@ -0,0 +1,14 @@
+ALTER TABLE league ADD COLUMN IF NOT EXISTS season INT DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS archived_matches (
+    id SERIAL PRIMARY KEY,
+    season INT NOT NULL,
+    week INT NOT NULL,
+    team1_id INT NOT NULL,
+    team1_name VARCHAR(255) NOT NULL,
+    team2_id INT NOT NULL,
+    team2_name VARCHAR(255) NOT NULL,
+    team1_score INT DEFAULT 0,
+    team2_score INT DEFAULT 0,
+    archived_at TIMESTAMP DEFAULT NOW()
+);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS sanctions;
+
+ALTER TABLE teams DROP COLUMN IF EXISTS points_deducted;
\ No newline at end of file
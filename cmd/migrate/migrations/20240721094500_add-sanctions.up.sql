@@ -0,0 +1,12 @@
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS points_deducted INT DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS sanctions (
+    id SERIAL PRIMARY KEY,
+    team_id INT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    points INT DEFAULT 0,
+    match_id INT,
+    season INT DEFAULT 1,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+ALTER TABLE matches DROP COLUMN IF EXISTS version;
+
+ALTER TABLE league DROP COLUMN IF EXISTS version;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS match_events;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS match_events (
+    id SERIAL PRIMARY KEY,
+    match_id INT NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    before_team1_score INT DEFAULT 0,
+    before_team2_score INT DEFAULT 0,
+    before_played BOOLEAN DEFAULT FALSE,
+    after_team1_score INT DEFAULT 0,
+    after_team2_score INT DEFAULT 0,
+    after_played BOOLEAN DEFAULT FALSE,
+    actor VARCHAR(255) NOT NULL,
+    reason TEXT,
+    reverts_event_id INT,
+    created_at TIMESTAMP DEFAULT NOW()
+);
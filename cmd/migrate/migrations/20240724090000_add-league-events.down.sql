@@ -0,0 +1 @@
+DROP TABLE IF EXISTS league_events;
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS league_events (
+    id SERIAL PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
@@ -0,0 +1 @@
+ALTER TABLE teams DROP CONSTRAINT IF EXISTS teams_name_key;
\ No newline at end of file
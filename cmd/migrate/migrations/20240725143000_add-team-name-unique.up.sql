@@ -0,0 +1 @@
+ALTER TABLE teams ADD CONSTRAINT teams_name_key UNIQUE (name);
\ No newline at end of file
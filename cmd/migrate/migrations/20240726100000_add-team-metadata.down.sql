@@ -0,0 +1 @@
+ALTER TABLE teams DROP COLUMN IF EXISTS metadata;
\ No newline at end of file
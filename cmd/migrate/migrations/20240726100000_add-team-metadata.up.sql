@@ -0,0 +1,5 @@
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS metadata JSONB DEFAULT '{}';
+
+INSERT INTO league (name)
+SELECT 'Football League'
+WHERE NOT EXISTS (SELECT 1 FROM league);
@@ -0,0 +1 @@
+ALTER TABLE league DROP COLUMN IF EXISTS seed;
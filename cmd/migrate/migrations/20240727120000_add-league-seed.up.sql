@@ -0,0 +1 @@
+ALTER TABLE league ADD COLUMN IF NOT EXISTS seed BIGINT NOT NULL DEFAULT 0;
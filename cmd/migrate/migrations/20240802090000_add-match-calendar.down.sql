@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS matches_kickoff_at_idx;
+DROP TABLE IF EXISTS league_calendar;
+ALTER TABLE matches DROP COLUMN IF EXISTS kickoff_at;
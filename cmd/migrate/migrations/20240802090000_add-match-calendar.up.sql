@@ -0,0 +1,9 @@
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS kickoff_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS league_calendar (
+    league_id INT PRIMARY KEY REFERENCES league(id) ON DELETE CASCADE,
+    config JSONB NOT NULL,
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS matches_kickoff_at_idx ON matches (kickoff_at);
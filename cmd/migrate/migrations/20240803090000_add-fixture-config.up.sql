@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS league_fixture_config (
+    league_id INT PRIMARY KEY REFERENCES league(id) ON DELETE CASCADE,
+    config JSONB NOT NULL,
+    last_report JSONB,
+    updated_at TIMESTAMP DEFAULT NOW()
+);
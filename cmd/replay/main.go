@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"football-simulation/config"
+	"football-simulation/database"
+	"football-simulation/service/event"
+	"football-simulation/service/league"
+	"football-simulation/service/team"
+	"football-simulation/types"
+	"log"
+	"os"
+)
+
+func main() {
+	to := flag.Int("to", 0, "replay up to and including this event id (0 replays the whole log)")
+	apply := flag.Bool("apply", false, "rebuild the team statistics and the league row from the whole log")
+	flag.Parse()
+
+	if *apply && *to != 0 {
+		log.Fatal("-apply always replays the whole log and cannot be combined with -to")
+	}
+
+	db, transactor := connect()
+	eventService := event.NewService(event.NewStore(db), league.NewStore(db), team.NewStore(db), transactor)
+
+	var (
+		state *types.LeagueState
+		err   error
+	)
+	if *apply {
+		state, err = eventService.Rebuild()
+	} else {
+		state, err = eventService.Replay(*to)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(state); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// connect opens the database selected by DB_DRIVER and returns it with a
+// transactor for it. The in-memory backend only lives in the server process,
+// so it has no event log to replay.
+func connect() (types.DBTX, types.Transactor) {
+	switch config.Envs.Driver {
+	case "postgres":
+		db, err := database.NewPostgreSQLStorage(database.DBConfig{
+			User:     config.Envs.User,
+			Password: config.Envs.Password,
+			DBName:   config.Envs.DBName,
+			Host:     config.Envs.Host,
+			DBPort:   config.Envs.DBPort,
+			SSLMode:  config.Envs.SSLMode,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		return db, database.NewTransactor(db)
+
+	case "sqlite":
+		db, err := database.NewSQLiteStorage(config.Envs.Path)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := database.MigrateSQLite(db, "up"); err != nil {
+			log.Fatal(err)
+		}
+		return database.SQLite(db), database.NewSQLiteTransactor(db)
+	}
+
+	log.Fatalf("cannot replay the event log with DB_DRIVER %q, use postgres or sqlite", config.Envs.Driver)
+	return nil, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"football-simulation/config"
+	"football-simulation/database"
+	"football-simulation/service/event"
+	"football-simulation/service/league"
+	"football-simulation/service/playoff"
+	"football-simulation/service/simulation"
+	"football-simulation/service/snapshot"
+	"football-simulation/service/team"
+	"football-simulation/types"
+	"io"
+	"log"
+	"os"
+)
+
+const usage = `usage:
+  snapshot save [-file <path>]
+  snapshot load -file <path> [-replace]`
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	db, transactor := connect()
+	service := snapshot.NewService(league.NewStore(db), team.NewStore(db), simulation.NewStore(db), playoff.NewStore(db), event.NewStore(db), transactor)
+
+	switch os.Args[1] {
+	case "save":
+		flags := flag.NewFlagSet("save", flag.ExitOnError)
+		file := flags.String("file", "", "file to write, stdout when omitted")
+		flags.Parse(os.Args[2:])
+
+		state, err := service.SaveSnapshot()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var output io.Writer = os.Stdout
+		if *file != "" {
+			f, err := os.Create(*file)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer f.Close()
+			output = f
+		}
+
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(state); err != nil {
+			log.Fatal(err)
+		}
+
+	case "load":
+		var options types.SnapshotLoadOptions
+
+		flags := flag.NewFlagSet("load", flag.ExitOnError)
+		file := flags.String("file", "", "snapshot file to load")
+		flags.BoolVar(&options.Replace, "replace", false, "delete the current teams and fixture instead of requiring an empty league")
+		flags.Parse(os.Args[2:])
+
+		if *file == "" {
+			log.Fatal(usage)
+		}
+
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+
+		var state types.Snapshot
+		if err := json.NewDecoder(f).Decode(&state); err != nil {
+			log.Fatal(err)
+		}
+
+		loaded, err := service.LoadSnapshot(state, options)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Printf("loaded %d teams and %d matches into %q", len(loaded.Teams), len(loaded.Matches), loaded.League.Name)
+
+	default:
+		log.Fatal(usage)
+	}
+}
+
+// connect opens the database selected by DB_DRIVER and returns it with a
+// transactor for it. The in-memory backend only lives in the server process,
+// so its snapshots are taken through the API.
+func connect() (types.DBTX, types.Transactor) {
+	switch config.Envs.Driver {
+	case "postgres":
+		db, err := database.NewPostgreSQLStorage(database.DBConfig{
+			User:     config.Envs.User,
+			Password: config.Envs.Password,
+			DBName:   config.Envs.DBName,
+			Host:     config.Envs.Host,
+			DBPort:   config.Envs.DBPort,
+			SSLMode:  config.Envs.SSLMode,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		return db, database.NewTransactor(db)
+
+	case "sqlite":
+		db, err := database.NewSQLiteStorage(config.Envs.Path)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := database.MigrateSQLite(db, "up"); err != nil {
+			log.Fatal(err)
+		}
+		return database.SQLite(db), database.NewSQLiteTransactor(db)
+	}
+
+	log.Fatalf("cannot save or load snapshots with DB_DRIVER %q, use postgres or sqlite", config.Envs.Driver)
+	return nil, nil
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -15,6 +16,18 @@ type Config struct {
 	DBPort   string
 	Host     string
 	SSLMode  string
+	Driver   string
+	Path     string
+	// AdminKey is an API key with the admin role that the server stores on
+	// start, to reach a fresh database before any key has been created.
+	AdminKey string
+	// AnonymousReads lets requests without an API key read the league.
+	AnonymousReads bool
+	// WebhookTimeout bounds a single webhook delivery attempt.
+	WebhookTimeout time.Duration
+	// WebhookBackoff is the wait before the first retry of a failed
+	// delivery; it doubles with every further attempt.
+	WebhookBackoff time.Duration
 }
 
 var Envs = initConfig()
@@ -33,5 +46,33 @@ func initConfig() Config {
 		Host:     os.Getenv("DB_HOST"),
 		DBPort:   os.Getenv("DB_PORT"),
 		SSLMode:  os.Getenv("DB_SSLMODE"),
+		Driver:   getEnv("DB_DRIVER", "postgres"),
+		Path:     getEnv("DB_PATH", "football-simulation.db"),
+
+		AdminKey:       os.Getenv("AUTH_ADMIN_KEY"),
+		AnonymousReads: getEnv("AUTH_ANONYMOUS_READS", "true") == "true",
+
+		WebhookTimeout: getDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookBackoff: getDuration("WEBHOOK_BACKOFF", 30*time.Second),
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("invalid %s %q: %v", key, value, err)
 	}
+	return duration
 }
@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"fmt"
+	"football-simulation/types"
+	"strings"
+	"time"
+)
+
+// apiKeyRow is a stored API key with the hash it is looked up by.
+type apiKeyRow struct {
+	key  types.APIKey
+	hash string
+}
+
+type APIKeyStore struct {
+	db *DB
+}
+
+func NewAPIKeyStore(db *DB) *APIKeyStore {
+	return &APIKeyStore{db: db}
+}
+
+func (s *APIKeyStore) WithTx(tx types.DBTX) types.APIKeyStore {
+	return s
+}
+
+func (s *APIKeyStore) CreateAPIKey(key types.APIKey, hash string) (*types.APIKey, error) {
+	err := s.db.write(func(t *tables) error {
+		for _, row := range t.apiKeys {
+			if strings.EqualFold(row.key.Name, key.Name) || row.hash == hash {
+				return fmt.Errorf("duplicate API key %q", key.Name)
+			}
+		}
+
+		key.ID = t.nextAPIKeyID
+		key.CreatedAt = time.Now()
+		key.RevokedAt = nil
+		t.apiKeys = append(t.apiKeys, apiKeyRow{key: key, hash: hash})
+		t.nextAPIKeyID++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (s *APIKeyStore) GetAPIKeyByHash(hash string) (*types.APIKey, error) {
+	key := &types.APIKey{}
+	s.db.read(func(t *tables) {
+		for _, row := range t.apiKeys {
+			if row.hash == hash {
+				*key = row.key
+			}
+		}
+	})
+	return key, nil
+}
+
+func (s *APIKeyStore) GetAPIKeys() ([]types.APIKey, error) {
+	keys := make([]types.APIKey, 0)
+	s.db.read(func(t *tables) {
+		for _, row := range t.apiKeys {
+			keys = append(keys, row.key)
+		}
+	})
+	return keys, nil
+}
+
+func (s *APIKeyStore) ReplaceAPIKeyHash(id int, hash string) error {
+	return s.db.write(func(t *tables) error {
+		for _, row := range t.apiKeys {
+			if row.key.ID != id && row.hash == hash {
+				return fmt.Errorf("duplicate API key hash")
+			}
+		}
+
+		for i, row := range t.apiKeys {
+			if row.key.ID == id {
+				t.apiKeys[i].hash = hash
+				t.apiKeys[i].key.RevokedAt = nil
+			}
+		}
+		return nil
+	})
+}
+
+func (s *APIKeyStore) RevokeAPIKey(id int) error {
+	return s.db.write(func(t *tables) error {
+		for i, row := range t.apiKeys {
+			if row.key.ID == id && row.key.RevokedAt == nil {
+				revokedAt := time.Now()
+				t.apiKeys[i].key.RevokedAt = &revokedAt
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"fmt"
+	"football-simulation/types"
+	"sort"
+	"time"
+)
+
+type CalendarStore struct {
+	db *DB
+}
+
+func NewCalendarStore(db *DB) *CalendarStore {
+	return &CalendarStore{db: db}
+}
+
+func (s *CalendarStore) WithTx(tx types.DBTX) types.CalendarStore {
+	return s
+}
+
+func (s *CalendarStore) GetCalendar(leagueID int) (*types.LeagueCalendar, error) {
+	calendar := &types.LeagueCalendar{}
+	s.db.read(func(t *tables) {
+		if t.calendar != nil && t.calendar.LeagueID == leagueID {
+			*calendar = copyCalendar(*t.calendar)
+		}
+	})
+	return calendar, nil
+}
+
+func (s *CalendarStore) SaveCalendar(calendar types.LeagueCalendar) error {
+	return s.db.write(func(t *tables) error {
+		if calendar.LeagueID != t.league.ID {
+			return fmt.Errorf("league %d does not exist", calendar.LeagueID)
+		}
+
+		calendar = copyCalendar(calendar)
+		calendar.UpdatedAt = time.Now()
+		t.calendar = &calendar
+		return nil
+	})
+}
+
+func (s *CalendarStore) DeleteCalendar(leagueID int) error {
+	return s.db.write(func(t *tables) error {
+		if t.calendar != nil && t.calendar.LeagueID == leagueID {
+			t.calendar = nil
+		}
+		return nil
+	})
+}
+
+func (s *CalendarStore) GetMatchesBetween(from, to time.Time) ([]types.Match, error) {
+	matches := make([]types.Match, 0)
+	s.db.read(func(t *tables) {
+		for _, match := range t.sortedMatches() {
+			if match.KickoffAt != nil && !match.KickoffAt.Before(from) && match.KickoffAt.Before(to) {
+				matches = append(matches, match)
+			}
+		}
+	})
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].KickoffAt.Before(*matches[j].KickoffAt)
+	})
+	return matches, nil
+}
+
+func (s *CalendarStore) SetKickoff(matchID int, kickoffAt *time.Time) error {
+	return s.db.write(func(t *tables) error {
+		if match, ok := t.matches[matchID]; ok {
+			match.KickoffAt = copyTime(kickoffAt)
+			t.matches[matchID] = match
+		}
+		return nil
+	})
+}
+
+func copyCalendar(calendar types.LeagueCalendar) types.LeagueCalendar {
+	calendar.Matchdays = append([]string(nil), calendar.Matchdays...)
+	calendar.KickoffTimes = append([]string(nil), calendar.KickoffTimes...)
+	calendar.MidweekRounds = append([]int(nil), calendar.MidweekRounds...)
+	calendar.MidweekMatchdays = append([]string(nil), calendar.MidweekMatchdays...)
+	calendar.MidweekKickoffTimes = append([]string(nil), calendar.MidweekKickoffTimes...)
+	calendar.Blackouts = append([]types.Blackout(nil), calendar.Blackouts...)
+	return calendar
+}
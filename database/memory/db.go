@@ -0,0 +1,149 @@
+// Package memory implements the stores on an in-process database, for tests
+// and demos that should run without PostgreSQL. It mirrors the behaviour of
+// the SQL stores, including version bumps, cascades and foreign keys.
+package memory
+
+import (
+	"encoding/json"
+	"football-simulation/types"
+	"sync"
+)
+
+// DB holds every table of the in-memory database. Each store call locks mu
+// for its duration; txMu is held by the Transactor for a whole transaction,
+// so transactions run one at a time. Reads outside a transaction may see
+// changes of a transaction that is still running.
+type DB struct {
+	mu   sync.Mutex
+	txMu sync.Mutex
+	data *tables
+}
+
+type tables struct {
+	league         types.League
+	teams          map[int]types.Team
+	matches        map[int]types.Match
+	archived       []types.HeadToHeadMatch
+	matchEvents    []types.MatchEvent
+	sanctions      []types.Sanction
+	events         []types.LeagueEvent
+	apiKeys        []apiKeyRow
+	webhooks       []types.Webhook
+	deliveries     []types.WebhookDelivery
+	schedule       *types.LeagueSchedule
+	jobs           []types.Job
+	calendar       *types.LeagueCalendar
+	fixtureConfig  *types.LeagueFixtureConfig
+	playoffConfig  *types.LeaguePlayoffConfig
+	playoffMatches []types.PlayoffMatch
+
+	nextTeamID         int
+	nextMatchID        int
+	nextMatchEventID   int
+	nextSanctionID     int
+	nextEventID        int
+	nextAPIKeyID       int
+	nextWebhookID      int
+	nextDeliveryID     int
+	nextJobID          int
+	nextPlayoffMatchID int
+}
+
+// NewDB returns an empty database with the default league row, like a
+// freshly migrated PostgreSQL database.
+func NewDB() *DB {
+	return &DB{
+		data: &tables{
+			league:             types.League{ID: 1, Name: "Football League", Season: 1, Version: 1},
+			teams:              make(map[int]types.Team),
+			matches:            make(map[int]types.Match),
+			nextTeamID:         1,
+			nextMatchID:        1,
+			nextMatchEventID:   1,
+			nextSanctionID:     1,
+			nextEventID:        1,
+			nextAPIKeyID:       1,
+			nextWebhookID:      1,
+			nextDeliveryID:     1,
+			nextJobID:          1,
+			nextPlayoffMatchID: 1,
+		},
+	}
+}
+
+// clone returns a deep copy of the tables, used to roll a transaction back.
+func (t *tables) clone() *tables {
+	c := *t
+
+	c.teams = make(map[int]types.Team, len(t.teams))
+	for id, team := range t.teams {
+		c.teams[id] = copyTeam(team)
+	}
+
+	c.matches = make(map[int]types.Match, len(t.matches))
+	for id, match := range t.matches {
+		c.matches[id] = match
+	}
+
+	c.archived = append([]types.HeadToHeadMatch(nil), t.archived...)
+	c.matchEvents = append([]types.MatchEvent(nil), t.matchEvents...)
+	c.sanctions = append([]types.Sanction(nil), t.sanctions...)
+	c.apiKeys = append([]apiKeyRow(nil), t.apiKeys...)
+	c.webhooks = append([]types.Webhook(nil), t.webhooks...)
+	c.deliveries = append([]types.WebhookDelivery(nil), t.deliveries...)
+	c.jobs = make([]types.Job, len(t.jobs))
+	for i, job := range t.jobs {
+		c.jobs[i] = copyJob(job)
+	}
+	if t.schedule != nil {
+		schedule := copySchedule(*t.schedule)
+		c.schedule = &schedule
+	}
+	if t.calendar != nil {
+		calendar := copyCalendar(*t.calendar)
+		c.calendar = &calendar
+	}
+	if t.fixtureConfig != nil {
+		fixtureConfig := copyFixtureConfig(*t.fixtureConfig)
+		c.fixtureConfig = &fixtureConfig
+	}
+	if t.playoffConfig != nil {
+		playoffConfig := copyPlayoffConfig(*t.playoffConfig)
+		c.playoffConfig = &playoffConfig
+	}
+	c.playoffMatches = make([]types.PlayoffMatch, len(t.playoffMatches))
+	for i, match := range t.playoffMatches {
+		c.playoffMatches[i] = copyPlayoffMatch(match)
+	}
+
+	c.events = make([]types.LeagueEvent, len(t.events))
+	for i, event := range t.events {
+		event.Payload = append(json.RawMessage(nil), event.Payload...)
+		c.events[i] = event
+	}
+
+	return &c
+}
+
+func (db *DB) read(fn func(t *tables)) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	fn(db.data)
+}
+
+func (db *DB) write(fn func(t *tables) error) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return fn(db.data)
+}
+
+// copyTeam returns team with its own metadata map. Metadata is never nil,
+// matching the '{}' default of the metadata column.
+func copyTeam(team types.Team) types.Team {
+	metadata := make(map[string]string, len(team.Metadata))
+	for key, value := range team.Metadata {
+		metadata[key] = value
+	}
+	team.Metadata = metadata
+	return team
+}
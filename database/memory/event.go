@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"encoding/json"
+	"football-simulation/types"
+	"time"
+)
+
+type EventStore struct {
+	db *DB
+}
+
+func NewEventStore(db *DB) *EventStore {
+	return &EventStore{db: db}
+}
+
+func (s *EventStore) WithTx(tx types.DBTX) types.LeagueEventStore {
+	return s
+}
+
+func (s *EventStore) AppendEvent(eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return s.db.write(func(t *tables) error {
+		t.events = append(t.events, types.LeagueEvent{
+			ID:        t.nextEventID,
+			Type:      eventType,
+			Payload:   data,
+			CreatedAt: time.Now(),
+		})
+		t.nextEventID++
+		return nil
+	})
+}
+
+// GetEvents returns the log in order, up to and including toEventID. A
+// toEventID of 0 returns the whole log.
+func (s *EventStore) GetEvents(toEventID int) ([]types.LeagueEvent, error) {
+	events := make([]types.LeagueEvent, 0)
+	s.db.read(func(t *tables) {
+		for _, event := range t.events {
+			if toEventID == 0 || event.ID <= toEventID {
+				events = append(events, event)
+			}
+		}
+	})
+	return events, nil
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"fmt"
+	"football-simulation/types"
+	"time"
+)
+
+type FixtureStore struct {
+	db *DB
+}
+
+func NewFixtureStore(db *DB) *FixtureStore {
+	return &FixtureStore{db: db}
+}
+
+func (s *FixtureStore) WithTx(tx types.DBTX) types.FixtureStore {
+	return s
+}
+
+func (s *FixtureStore) GetFixtureConfig(leagueID int) (*types.LeagueFixtureConfig, error) {
+	config := &types.LeagueFixtureConfig{}
+	s.db.read(func(t *tables) {
+		if t.fixtureConfig != nil && t.fixtureConfig.LeagueID == leagueID {
+			*config = copyFixtureConfig(*t.fixtureConfig)
+		}
+	})
+	return config, nil
+}
+
+func (s *FixtureStore) SaveFixtureConfig(config types.LeagueFixtureConfig) error {
+	return s.db.write(func(t *tables) error {
+		if config.LeagueID != t.league.ID {
+			return fmt.Errorf("league %d does not exist", config.LeagueID)
+		}
+
+		config = copyFixtureConfig(config)
+		config.UpdatedAt = time.Now()
+		t.fixtureConfig = &config
+		return nil
+	})
+}
+
+func copyFixtureConfig(config types.LeagueFixtureConfig) types.LeagueFixtureConfig {
+	config.Derbies = append([]types.Derby(nil), config.Derbies...)
+	if config.LastReport != nil {
+		report := *config.LastReport
+		report.Unmet = make([]types.UnmetConstraint, len(report.Unmet))
+		copy(report.Unmet, config.LastReport.Unmet)
+		config.LastReport = &report
+	}
+	return config
+}
@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"encoding/json"
+	"football-simulation/types"
+	"time"
+)
+
+type JobStore struct {
+	db *DB
+}
+
+func NewJobStore(db *DB) *JobStore {
+	return &JobStore{db: db}
+}
+
+func (s *JobStore) WithTx(tx types.DBTX) types.JobStore {
+	return s
+}
+
+func (s *JobStore) CreateJob(job types.Job) (*types.Job, error) {
+	s.db.write(func(t *tables) error {
+		job.ID = t.nextJobID
+		job.CreatedAt = time.Now()
+		t.jobs = append(t.jobs, copyJob(job))
+		t.nextJobID++
+		return nil
+	})
+	return &job, nil
+}
+
+func (s *JobStore) GetJob(id int) (*types.Job, error) {
+	job := &types.Job{}
+	s.db.read(func(t *tables) {
+		for _, existing := range t.jobs {
+			if existing.ID == id {
+				*job = copyJob(existing)
+			}
+		}
+	})
+	return job, nil
+}
+
+func (s *JobStore) GetJobs(limit int) ([]types.Job, error) {
+	jobs := make([]types.Job, 0)
+	s.db.read(func(t *tables) {
+		for i := len(t.jobs) - 1; i >= 0 && len(jobs) < limit; i-- {
+			jobs = append(jobs, copyJob(t.jobs[i]))
+		}
+	})
+	return jobs, nil
+}
+
+func (s *JobStore) UpdateJob(job types.Job) error {
+	return s.db.write(func(t *tables) error {
+		for i, existing := range t.jobs {
+			if existing.ID == job.ID {
+				job.CancelRequested = existing.CancelRequested
+				job.HeartbeatAt = existing.HeartbeatAt
+				job.CreatedAt = existing.CreatedAt
+				t.jobs[i] = copyJob(job)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *JobStore) RequestCancel(id int) error {
+	return s.db.write(func(t *tables) error {
+		for i, job := range t.jobs {
+			if job.ID == id && (job.Status == types.JobQueued || job.Status == types.JobRunning) {
+				t.jobs[i].CancelRequested = true
+			}
+		}
+		return nil
+	})
+}
+
+func (s *JobStore) Heartbeat(ids []int, now time.Time) error {
+	return s.db.write(func(t *tables) error {
+		for i, job := range t.jobs {
+			for _, id := range ids {
+				if job.ID == id {
+					t.jobs[i].HeartbeatAt = now
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (s *JobStore) FailStaleJobs(before time.Time, message string) error {
+	return s.db.write(func(t *tables) error {
+		for i, job := range t.jobs {
+			if (job.Status == types.JobQueued || job.Status == types.JobRunning) && job.HeartbeatAt.Before(before) {
+				finishedAt := time.Now()
+				t.jobs[i].Status = types.JobFailed
+				t.jobs[i].Error = message
+				t.jobs[i].ErrorCode = "job_interrupted"
+				t.jobs[i].FinishedAt = &finishedAt
+			}
+		}
+		return nil
+	})
+}
+
+func copyJob(job types.Job) types.Job {
+	if job.Result != nil {
+		job.Result = append(json.RawMessage(nil), job.Result...)
+	}
+	job.StartedAt = copyTime(job.StartedAt)
+	job.FinishedAt = copyTime(job.FinishedAt)
+	return job
+}
@@ -0,0 +1,265 @@
+package memory
+
+import (
+	"football-simulation/types"
+	"sort"
+	"time"
+)
+
+type LeagueStore struct {
+	db *DB
+}
+
+func NewLeagueStore(db *DB) *LeagueStore {
+	return &LeagueStore{db: db}
+}
+
+func (s *LeagueStore) WithTx(tx types.DBTX) types.LeagueStore {
+	return s
+}
+
+func (s *LeagueStore) GetLeagueInfo() (types.League, error) {
+	var league types.League
+	s.db.read(func(t *tables) {
+		league = t.league
+	})
+	return league, nil
+}
+
+func (s *LeagueStore) ClearFixtures() error {
+	return s.db.write(func(t *tables) error {
+		t.matches = make(map[int]types.Match)
+		t.matchEvents = nil
+		return nil
+	})
+}
+
+func (s *LeagueStore) ArchiveFixtures() error {
+	return s.db.write(func(t *tables) error {
+		for _, match := range t.sortedMatches() {
+			if !match.Played {
+				continue
+			}
+
+			t.archived = append(t.archived, types.HeadToHeadMatch{
+				Season:     t.league.Season,
+				Archived:   true,
+				Week:       match.Week,
+				Team1ID:    match.Team1ID,
+				Team1Name:  t.teams[match.Team1ID].Name,
+				Team2ID:    match.Team2ID,
+				Team2Name:  t.teams[match.Team2ID].Name,
+				Team1Score: match.Team1Score,
+				Team2Score: match.Team2Score,
+				Played:     true,
+			})
+		}
+		return nil
+	})
+}
+
+// TryLockLeague always succeeds: the Transactor already runs transactions
+// one at a time.
+func (s *LeagueStore) TryLockLeague(leagueID int) (bool, error) {
+	return true, nil
+}
+
+func (s *LeagueStore) UpdateLeague(league types.League) error {
+	return s.db.write(func(t *tables) error {
+		if league.ID != t.league.ID {
+			return nil
+		}
+
+		league.Version = t.league.Version + 1
+		t.league = league
+		return nil
+	})
+}
+
+func (s *LeagueStore) GetStandings() ([]types.Team, error) {
+	var standings []types.Team
+	s.db.read(func(t *tables) {
+		standings = t.sortedTeams()
+	})
+
+	// the standings query does not select the strength and metadata
+	for i := range standings {
+		standings[i].Strength = 0
+		standings[i].Metadata = nil
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Points != standings[j].Points {
+			return standings[i].Points > standings[j].Points
+		}
+		if standings[i].GoalsDifference != standings[j].GoalsDifference {
+			return standings[i].GoalsDifference > standings[j].GoalsDifference
+		}
+		return standings[i].GoalsFor > standings[j].GoalsFor
+	})
+
+	return standings, nil
+}
+
+func (s *LeagueStore) GetCurrentWeek() (int, error) {
+	league, err := s.GetLeagueInfo()
+	if err != nil {
+		return 0, err
+	}
+	return league.CurrentWeek, nil
+}
+
+func (s *LeagueStore) GetMatchesForNextWeek() ([]types.Match, error) {
+	var matches []types.Match
+	s.db.read(func(t *tables) {
+		for _, match := range t.sortedMatches() {
+			if !match.Played && match.Week == t.league.CurrentWeek {
+				matches = append(matches, match)
+			}
+		}
+	})
+	return matches, nil
+}
+
+func (s *LeagueStore) GetMatchesByWeek(week int) ([]types.Match, error) {
+	var matches []types.Match
+	s.db.read(func(t *tables) {
+		for _, match := range t.sortedMatches() {
+			if match.Played && match.Week == week {
+				matches = append(matches, match)
+			}
+		}
+	})
+	return matches, nil
+}
+
+func (s *LeagueStore) GetMatchByID(id int) (*types.Match, error) {
+	match := new(types.Match)
+	s.db.read(func(t *tables) {
+		if stored, ok := t.matches[id]; ok {
+			*match = stored
+		}
+	})
+	return match, nil
+}
+
+func (s *LeagueStore) UpdateMatch(match types.Match) error {
+	return s.db.write(func(t *tables) error {
+		stored, ok := t.matches[match.ID]
+		if !ok {
+			return nil
+		}
+
+		stored.Team1Score = match.Team1Score
+		stored.Team2Score = match.Team2Score
+		stored.Played = match.Played
+		stored.Version++
+		t.matches[match.ID] = stored
+		return nil
+	})
+}
+
+func (s *LeagueStore) SaveMatchResult(match types.Match) error {
+	match.Played = true
+	return s.UpdateMatch(match)
+}
+
+func (s *LeagueStore) SaveMatchEvent(event types.MatchEvent) error {
+	return s.db.write(func(t *tables) error {
+		event.ID = t.nextMatchEventID
+		event.CreatedAt = time.Now()
+		t.matchEvents = append(t.matchEvents, event)
+		t.nextMatchEventID++
+		return nil
+	})
+}
+
+func (s *LeagueStore) GetMatchEvents(matchID int) ([]types.MatchEvent, error) {
+	events := make([]types.MatchEvent, 0)
+	s.db.read(func(t *tables) {
+		for _, event := range t.matchEvents {
+			if event.MatchID == matchID {
+				events = append(events, event)
+			}
+		}
+	})
+	return events, nil
+}
+
+func (s *LeagueStore) IncrementWeek() error {
+	return s.db.write(func(t *tables) error {
+		t.league.CurrentWeek++
+		t.league.Version++
+		return nil
+	})
+}
+
+func (s *LeagueStore) GetAllMatches() ([]types.Match, error) {
+	var matches []types.Match
+	s.db.read(func(t *tables) {
+		matches = t.sortedMatches()
+	})
+	return matches, nil
+}
+
+func (s *LeagueStore) GetHeadToHeadMatches(teamID, opponentID int) ([]types.HeadToHeadMatch, error) {
+	matches := make([]types.HeadToHeadMatch, 0)
+	isMeeting := func(team1ID, team2ID int) bool {
+		return (team1ID == teamID && team2ID == opponentID) || (team1ID == opponentID && team2ID == teamID)
+	}
+
+	s.db.read(func(t *tables) {
+		for _, match := range t.sortedMatches() {
+			if !isMeeting(match.Team1ID, match.Team2ID) {
+				continue
+			}
+
+			matches = append(matches, types.HeadToHeadMatch{
+				Season:     t.league.Season,
+				Week:       match.Week,
+				Team1ID:    match.Team1ID,
+				Team1Name:  t.teams[match.Team1ID].Name,
+				Team2ID:    match.Team2ID,
+				Team2Name:  t.teams[match.Team2ID].Name,
+				Team1Score: match.Team1Score,
+				Team2Score: match.Team2Score,
+				Played:     match.Played,
+			})
+		}
+
+		for _, archived := range t.archived {
+			if isMeeting(archived.Team1ID, archived.Team2ID) {
+				matches = append(matches, archived)
+			}
+		}
+	})
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Season != matches[j].Season {
+			return matches[i].Season < matches[j].Season
+		}
+		return matches[i].Week < matches[j].Week
+	})
+
+	return matches, nil
+}
+
+func (s *LeagueStore) GetPredictions() ([]types.Prediction, error) {
+	return nil, nil
+}
+
+func (t *tables) sortedMatches() []types.Match {
+	matches := make([]types.Match, 0, len(t.matches))
+	for _, match := range t.matches {
+		matches = append(matches, match)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Week != matches[j].Week {
+			return matches[i].Week < matches[j].Week
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	return matches
+}
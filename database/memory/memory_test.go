@@ -0,0 +1,29 @@
+package memory_test
+
+import (
+	"football-simulation/database/memory"
+	"football-simulation/database/storetest"
+	"testing"
+)
+
+func TestStores(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storetest.Stores {
+		db := memory.NewDB()
+
+		return storetest.Stores{
+			Transactor: memory.NewTransactor(db),
+			League:     memory.NewLeagueStore(db),
+			Team:       memory.NewTeamStore(db),
+			Simulation: memory.NewSimulationStore(db),
+			Webhook:    memory.NewWebhookStore(db),
+			Schedule:   memory.NewScheduleStore(db),
+			Job:        memory.NewJobStore(db),
+			Calendar:   memory.NewCalendarStore(db),
+			Fixture:    memory.NewFixtureStore(db),
+			Playoff:    memory.NewPlayoffStore(db),
+			APIKey:     memory.NewAPIKeyStore(db),
+			Sanction:   memory.NewSanctionStore(db),
+			Event:      memory.NewEventStore(db),
+		}
+	})
+}
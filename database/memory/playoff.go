@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"fmt"
+	"football-simulation/types"
+	"sort"
+	"time"
+)
+
+type PlayoffStore struct {
+	db *DB
+}
+
+func NewPlayoffStore(db *DB) *PlayoffStore {
+	return &PlayoffStore{db: db}
+}
+
+func (s *PlayoffStore) WithTx(tx types.DBTX) types.PlayoffStore {
+	return s
+}
+
+func (s *PlayoffStore) GetPlayoffConfig(leagueID int) (*types.LeaguePlayoffConfig, error) {
+	config := &types.LeaguePlayoffConfig{}
+	s.db.read(func(t *tables) {
+		if t.playoffConfig != nil && t.playoffConfig.LeagueID == leagueID {
+			*config = copyPlayoffConfig(*t.playoffConfig)
+		}
+	})
+	return config, nil
+}
+
+func (s *PlayoffStore) SavePlayoffConfig(config types.LeaguePlayoffConfig) error {
+	return s.db.write(func(t *tables) error {
+		if config.LeagueID != t.league.ID {
+			return fmt.Errorf("league %d does not exist", config.LeagueID)
+		}
+
+		config = copyPlayoffConfig(config)
+		config.UpdatedAt = time.Now()
+		t.playoffConfig = &config
+		return nil
+	})
+}
+
+func (s *PlayoffStore) GetPlayoffMatches(season int) ([]types.PlayoffMatch, error) {
+	matches := []types.PlayoffMatch{}
+	s.db.read(func(t *tables) {
+		for _, match := range t.playoffMatches {
+			if match.Season == season {
+				matches = append(matches, copyPlayoffMatch(match))
+			}
+		}
+	})
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Round != matches[j].Round {
+			return matches[i].Round < matches[j].Round
+		}
+		return matches[i].Slot < matches[j].Slot
+	})
+	return matches, nil
+}
+
+func (s *PlayoffStore) SavePlayoffMatches(matches []types.PlayoffMatch) ([]types.PlayoffMatch, error) {
+	saved := make([]types.PlayoffMatch, 0, len(matches))
+
+	err := s.db.write(func(t *tables) error {
+		for _, match := range matches {
+			for _, existing := range t.playoffMatches {
+				if existing.Season == match.Season && existing.Round == match.Round && existing.Slot == match.Slot {
+					return fmt.Errorf("season %d already has a match in round %d, slot %d", match.Season, match.Round, match.Slot)
+				}
+			}
+
+			match = copyPlayoffMatch(match)
+			match.ID = t.nextPlayoffMatchID
+			match.Played = true
+			t.nextPlayoffMatchID++
+			t.playoffMatches = append(t.playoffMatches, match)
+			saved = append(saved, copyPlayoffMatch(match))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (s *PlayoffStore) DeletePlayoffMatches(fromSeason int) error {
+	return s.db.write(func(t *tables) error {
+		var kept []types.PlayoffMatch
+		for _, match := range t.playoffMatches {
+			if match.Season < fromSeason {
+				kept = append(kept, match)
+			}
+		}
+		t.playoffMatches = kept
+		return nil
+	})
+}
+
+func copyPlayoffConfig(config types.LeaguePlayoffConfig) types.LeaguePlayoffConfig {
+	config.Pairings = append([][2]int(nil), config.Pairings...)
+	return config
+}
+
+// copyPlayoffMatch returns match with its own penalty scores.
+func copyPlayoffMatch(match types.PlayoffMatch) types.PlayoffMatch {
+	if match.Team1Penalties != nil && match.Team2Penalties != nil {
+		team1, team2 := *match.Team1Penalties, *match.Team2Penalties
+		match.Team1Penalties, match.Team2Penalties = &team1, &team2
+	}
+	return match
+}
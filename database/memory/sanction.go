@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"fmt"
+	"football-simulation/types"
+	"time"
+)
+
+type SanctionStore struct {
+	db *DB
+}
+
+func NewSanctionStore(db *DB) *SanctionStore {
+	return &SanctionStore{db: db}
+}
+
+func (s *SanctionStore) WithTx(tx types.DBTX) types.SanctionStore {
+	return s
+}
+
+func (s *SanctionStore) CreateSanction(sanction types.Sanction) (*types.Sanction, error) {
+	err := s.db.write(func(t *tables) error {
+		if _, ok := t.teams[sanction.TeamID]; !ok {
+			return fmt.Errorf("sanction references unknown team %d", sanction.TeamID)
+		}
+
+		sanction.ID = t.nextSanctionID
+		sanction.Season = t.league.Season
+		sanction.CreatedAt = time.Now()
+		t.sanctions = append(t.sanctions, sanction)
+		t.nextSanctionID++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &sanction, nil
+}
+
+func (s *SanctionStore) GetSanctions() ([]types.Sanction, error) {
+	return s.getSanctions(func(types.Sanction) bool { return true }), nil
+}
+
+func (s *SanctionStore) GetSanctionsByTeam(teamID int) ([]types.Sanction, error) {
+	return s.getSanctions(func(sanction types.Sanction) bool { return sanction.TeamID == teamID }), nil
+}
+
+func (s *SanctionStore) getSanctions(keep func(types.Sanction) bool) []types.Sanction {
+	sanctions := make([]types.Sanction, 0)
+	s.db.read(func(t *tables) {
+		for _, sanction := range t.sanctions {
+			if keep(sanction) {
+				sanctions = append(sanctions, sanction)
+			}
+		}
+	})
+	return sanctions
+}
@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"fmt"
+	"football-simulation/types"
+	"time"
+)
+
+type ScheduleStore struct {
+	db *DB
+}
+
+func NewScheduleStore(db *DB) *ScheduleStore {
+	return &ScheduleStore{db: db}
+}
+
+func (s *ScheduleStore) WithTx(tx types.DBTX) types.ScheduleStore {
+	return s
+}
+
+func (s *ScheduleStore) GetSchedule(leagueID int) (*types.LeagueSchedule, error) {
+	schedule := &types.LeagueSchedule{}
+	s.db.read(func(t *tables) {
+		if t.schedule != nil && t.schedule.LeagueID == leagueID {
+			*schedule = copySchedule(*t.schedule)
+		}
+	})
+	return schedule, nil
+}
+
+func (s *ScheduleStore) SaveSchedule(schedule types.LeagueSchedule) error {
+	return s.db.write(func(t *tables) error {
+		if schedule.LeagueID != t.league.ID {
+			return fmt.Errorf("league %d does not exist", schedule.LeagueID)
+		}
+
+		schedule = copySchedule(schedule)
+		schedule.UpdatedAt = time.Now()
+		t.schedule = &schedule
+		return nil
+	})
+}
+
+func (s *ScheduleStore) DeleteSchedule(leagueID int) error {
+	return s.db.write(func(t *tables) error {
+		if t.schedule != nil && t.schedule.LeagueID == leagueID {
+			t.schedule = nil
+		}
+		return nil
+	})
+}
+
+func (s *ScheduleStore) SetPaused(leagueID int, paused bool, nextRunAt *time.Time) error {
+	return s.db.write(func(t *tables) error {
+		if t.schedule != nil && t.schedule.LeagueID == leagueID {
+			t.schedule.Paused = paused
+			t.schedule.NextRunAt = copyTime(nextRunAt)
+			t.schedule.UpdatedAt = time.Now()
+		}
+		return nil
+	})
+}
+
+func (s *ScheduleStore) ClaimRun(leagueID int, nextRunAt, followingRunAt time.Time) (bool, error) {
+	claimed := false
+	s.db.write(func(t *tables) error {
+		schedule := t.schedule
+		if schedule != nil && schedule.LeagueID == leagueID && !schedule.Paused &&
+			schedule.NextRunAt != nil && schedule.NextRunAt.Equal(nextRunAt) {
+			schedule.NextRunAt = &followingRunAt
+			claimed = true
+		}
+		return nil
+	})
+	return claimed, nil
+}
+
+func (s *ScheduleStore) RecordRun(leagueID int, ranAt time.Time, runErr string) error {
+	return s.db.write(func(t *tables) error {
+		if t.schedule != nil && t.schedule.LeagueID == leagueID {
+			t.schedule.LastRunAt = &ranAt
+			t.schedule.LastError = runErr
+		}
+		return nil
+	})
+}
+
+func copySchedule(schedule types.LeagueSchedule) types.LeagueSchedule {
+	schedule.NextRunAt = copyTime(schedule.NextRunAt)
+	schedule.LastRunAt = copyTime(schedule.LastRunAt)
+	return schedule
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
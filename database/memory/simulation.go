@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"fmt"
+	"football-simulation/types"
+)
+
+type SimulationStore struct {
+	db *DB
+}
+
+func NewSimulationStore(db *DB) *SimulationStore {
+	return &SimulationStore{db: db}
+}
+
+func (s *SimulationStore) WithTx(tx types.DBTX) types.SimulationStore {
+	return s
+}
+
+func (s *SimulationStore) SaveFixture(matches []types.Match) ([]types.Match, error) {
+	savedMatches := make([]types.Match, 0, len(matches))
+
+	err := s.db.write(func(t *tables) error {
+		for _, match := range matches {
+			for _, teamID := range []int{match.Team1ID, match.Team2ID} {
+				if _, ok := t.teams[teamID]; !ok {
+					return fmt.Errorf("match references unknown team %d", teamID)
+				}
+			}
+
+			match.ID = t.nextMatchID
+			match.Version = 1
+			match.KickoffAt = copyTime(match.KickoffAt)
+			t.matches[match.ID] = match
+			t.nextMatchID++
+			savedMatches = append(savedMatches, match)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return savedMatches, nil
+}
@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"fmt"
+	"football-simulation/types"
+	"sort"
+)
+
+type TeamStore struct {
+	db *DB
+}
+
+func NewTeamStore(db *DB) *TeamStore {
+	return &TeamStore{db: db}
+}
+
+func (s *TeamStore) WithTx(tx types.DBTX) types.Teamstore {
+	return s
+}
+
+func (s *TeamStore) GetTeams() ([]types.Team, error) {
+	var teams []types.Team
+	s.db.read(func(t *tables) {
+		teams = t.sortedTeams()
+	})
+	return teams, nil
+}
+
+func (s *TeamStore) GetTeamByID(id int) (*types.Team, error) {
+	team := new(types.Team)
+	s.db.read(func(t *tables) {
+		if stored, ok := t.teams[id]; ok {
+			*team = copyTeam(stored)
+		}
+	})
+	return team, nil
+}
+
+func (s *TeamStore) GetTeamByName(name string) (*types.Team, error) {
+	team := new(types.Team)
+	s.db.read(func(t *tables) {
+		if stored, ok := t.teamByName(name); ok {
+			*team = copyTeam(stored)
+		}
+	})
+	return team, nil
+}
+
+func (s *TeamStore) CreateTeam(team types.Team) (*types.Team, error) {
+	err := s.db.write(func(t *tables) error {
+		if _, ok := t.teamByName(team.Name); ok {
+			return fmt.Errorf("duplicate key value violates unique constraint \"teams_name_key\"")
+		}
+
+		team = copyTeam(types.Team{
+			ID:       t.nextTeamID,
+			Name:     team.Name,
+			Strength: team.Strength,
+			Metadata: team.Metadata,
+		})
+		t.teams[team.ID] = team
+		t.nextTeamID++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created := copyTeam(team)
+	return &created, nil
+}
+
+func (s *TeamStore) DeleteTeam(id int) error {
+	return s.db.write(func(t *tables) error {
+		for _, match := range t.matches {
+			if match.Team1ID == id || match.Team2ID == id {
+				return fmt.Errorf("team %d is still referenced by match %d", id, match.ID)
+			}
+		}
+
+		delete(t.teams, id)
+
+		sanctions := t.sanctions[:0]
+		for _, sanction := range t.sanctions {
+			if sanction.TeamID != id {
+				sanctions = append(sanctions, sanction)
+			}
+		}
+		t.sanctions = sanctions
+		return nil
+	})
+}
+
+func (s *TeamStore) UpdateTeam(team types.Team) error {
+	return s.db.write(func(t *tables) error {
+		if _, ok := t.teams[team.ID]; !ok {
+			return nil
+		}
+
+		if other, ok := t.teamByName(team.Name); ok && other.ID != team.ID {
+			return fmt.Errorf("duplicate key value violates unique constraint \"teams_name_key\"")
+		}
+
+		t.teams[team.ID] = copyTeam(team)
+		return nil
+	})
+}
+
+func (s *TeamStore) ResetTeams() error {
+	return s.db.write(func(t *tables) error {
+		for id, team := range t.teams {
+			t.teams[id] = types.Team{ID: team.ID, Name: team.Name, Strength: team.Strength, Metadata: team.Metadata}
+		}
+		return nil
+	})
+}
+
+func (t *tables) sortedTeams() []types.Team {
+	teams := make([]types.Team, 0, len(t.teams))
+	for _, team := range t.teams {
+		teams = append(teams, copyTeam(team))
+	}
+
+	sort.Slice(teams, func(i, j int) bool { return teams[i].ID < teams[j].ID })
+	return teams
+}
+
+func (t *tables) teamByName(name string) (types.Team, bool) {
+	for _, team := range t.teams {
+		if team.Name == name {
+			return team, true
+		}
+	}
+	return types.Team{}, false
+}
@@ -0,0 +1,44 @@
+package memory
+
+import "football-simulation/types"
+
+type Transactor struct {
+	db *DB
+}
+
+func NewTransactor(db *DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// WithinTransaction runs fn while holding the transaction lock and restores
+// the tables as they were before fn when it fails or panics. The memory
+// stores ignore tx, so fn receives nil.
+func (t *Transactor) WithinTransaction(fn func(tx types.DBTX) error) error {
+	t.db.txMu.Lock()
+	defer t.db.txMu.Unlock()
+
+	var snapshot *tables
+	t.db.read(func(data *tables) {
+		snapshot = data.clone()
+	})
+
+	rollback := func() {
+		t.db.mu.Lock()
+		t.db.data = snapshot
+		t.db.mu.Unlock()
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(nil); err != nil {
+		rollback()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"football-simulation/types"
+	"sort"
+	"time"
+)
+
+type WebhookStore struct {
+	db *DB
+}
+
+func NewWebhookStore(db *DB) *WebhookStore {
+	return &WebhookStore{db: db}
+}
+
+func (s *WebhookStore) WithTx(tx types.DBTX) types.WebhookStore {
+	return s
+}
+
+func (s *WebhookStore) CreateWebhook(webhook types.Webhook) (*types.Webhook, error) {
+	s.db.write(func(t *tables) error {
+		webhook.ID = t.nextWebhookID
+		webhook.EventTypes = append([]string(nil), webhook.EventTypes...)
+		webhook.CreatedAt = time.Now()
+		t.webhooks = append(t.webhooks, webhook)
+		t.nextWebhookID++
+		return nil
+	})
+	return &webhook, nil
+}
+
+func (s *WebhookStore) GetWebhooks() ([]types.Webhook, error) {
+	webhooks := make([]types.Webhook, 0)
+	s.db.read(func(t *tables) {
+		for _, webhook := range t.webhooks {
+			webhook.EventTypes = append([]string(nil), webhook.EventTypes...)
+			webhooks = append(webhooks, webhook)
+		}
+	})
+	return webhooks, nil
+}
+
+func (s *WebhookStore) GetWebhookByID(id int) (*types.Webhook, error) {
+	webhook := &types.Webhook{}
+	s.db.read(func(t *tables) {
+		for _, existing := range t.webhooks {
+			if existing.ID == id {
+				*webhook = existing
+				webhook.EventTypes = append([]string(nil), existing.EventTypes...)
+			}
+		}
+	})
+	return webhook, nil
+}
+
+func (s *WebhookStore) DeleteWebhook(id int) error {
+	return s.db.write(func(t *tables) error {
+		webhooks := t.webhooks[:0]
+		for _, webhook := range t.webhooks {
+			if webhook.ID != id {
+				webhooks = append(webhooks, webhook)
+			}
+		}
+		t.webhooks = webhooks
+
+		deliveries := t.deliveries[:0]
+		for _, delivery := range t.deliveries {
+			if delivery.WebhookID != id {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+		t.deliveries = deliveries
+		return nil
+	})
+}
+
+func (s *WebhookStore) CreateDelivery(delivery types.WebhookDelivery) (*types.WebhookDelivery, error) {
+	err := s.db.write(func(t *tables) error {
+		found := false
+		for _, webhook := range t.webhooks {
+			found = found || webhook.ID == delivery.WebhookID
+		}
+		if !found {
+			return fmt.Errorf("webhook %d does not exist", delivery.WebhookID)
+		}
+
+		delivery.ID = t.nextDeliveryID
+		delivery.Payload = append(json.RawMessage(nil), delivery.Payload...)
+		delivery.CreatedAt = time.Now()
+		t.deliveries = append(t.deliveries, delivery)
+		t.nextDeliveryID++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (s *WebhookStore) GetDeliveries(webhookID int) ([]types.WebhookDelivery, error) {
+	deliveries := make([]types.WebhookDelivery, 0)
+	s.db.read(func(t *tables) {
+		for i := len(t.deliveries) - 1; i >= 0; i-- {
+			if t.deliveries[i].WebhookID == webhookID {
+				deliveries = append(deliveries, copyDelivery(t.deliveries[i]))
+			}
+		}
+	})
+	return deliveries, nil
+}
+
+func (s *WebhookStore) GetDueDeliveries(now time.Time, limit int) ([]types.WebhookDelivery, error) {
+	deliveries := make([]types.WebhookDelivery, 0)
+	s.db.read(func(t *tables) {
+		for _, delivery := range t.deliveries {
+			if delivery.Status == types.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+				deliveries = append(deliveries, copyDelivery(delivery))
+			}
+		}
+	})
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (s *WebhookStore) ClaimDelivery(id int, nextAttemptAt, leaseUntil time.Time) (bool, error) {
+	claimed := false
+	s.db.write(func(t *tables) error {
+		for i, delivery := range t.deliveries {
+			if delivery.ID == id && delivery.Status == types.DeliveryPending && delivery.NextAttemptAt.Equal(nextAttemptAt) {
+				t.deliveries[i].NextAttemptAt = leaseUntil
+				claimed = true
+			}
+		}
+		return nil
+	})
+	return claimed, nil
+}
+
+func (s *WebhookStore) UpdateDelivery(delivery types.WebhookDelivery, leaseUntil time.Time) (bool, error) {
+	updated := false
+	s.db.write(func(t *tables) error {
+		for i, existing := range t.deliveries {
+			if existing.ID == delivery.ID && existing.Status == types.DeliveryPending && existing.NextAttemptAt.Equal(leaseUntil) {
+				existing.Status = delivery.Status
+				existing.Attempts = delivery.Attempts
+				existing.ResponseStatus = delivery.ResponseStatus
+				existing.Error = delivery.Error
+				existing.NextAttemptAt = delivery.NextAttemptAt
+				existing.DeliveredAt = delivery.DeliveredAt
+				t.deliveries[i] = existing
+				updated = true
+			}
+		}
+		return nil
+	})
+	return updated, nil
+}
+
+func copyDelivery(delivery types.WebhookDelivery) types.WebhookDelivery {
+	delivery.Payload = append(json.RawMessage(nil), delivery.Payload...)
+	return delivery
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS league_events;
+DROP TABLE IF EXISTS match_events;
+DROP TABLE IF EXISTS sanctions;
+DROP TABLE IF EXISTS archived_matches;
+DROP TABLE IF EXISTS matches;
+DROP TABLE IF EXISTS league;
+DROP TABLE IF EXISTS teams;
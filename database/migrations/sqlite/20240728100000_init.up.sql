@@ -0,0 +1,88 @@
+CREATE TABLE IF NOT EXISTS teams (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    strength INT DEFAULT 0,
+    points INT DEFAULT 0,
+    matches INT DEFAULT 0,
+    wins INT DEFAULT 0,
+    draws INT DEFAULT 0,
+    losses INT DEFAULT 0,
+    goals_for INT DEFAULT 0,
+    goals_against INT DEFAULT 0,
+    goals_difference INT DEFAULT 0,
+    temporary_drop INT DEFAULT 0,
+    points_deducted INT DEFAULT 0,
+    metadata TEXT DEFAULT '{}'
+);
+
+CREATE TABLE IF NOT EXISTS league (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(255) NOT NULL,
+    current_week INT DEFAULT 0,
+    total_weeks INT DEFAULT 0,
+    champion_team_name VARCHAR(255),
+    season INT DEFAULT 1,
+    seed BIGINT NOT NULL DEFAULT 0,
+    version INT NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS matches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    week INT NOT NULL,
+    team1_id INT REFERENCES teams(id),
+    team2_id INT REFERENCES teams(id),
+    team1_score INT DEFAULT 0,
+    team2_score INT DEFAULT 0,
+    played BOOLEAN DEFAULT FALSE,
+    version INT NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS archived_matches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    season INT NOT NULL,
+    week INT NOT NULL,
+    team1_id INT NOT NULL,
+    team1_name VARCHAR(255) NOT NULL,
+    team2_id INT NOT NULL,
+    team2_name VARCHAR(255) NOT NULL,
+    team1_score INT DEFAULT 0,
+    team2_score INT DEFAULT 0,
+    archived_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS sanctions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    team_id INT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    points INT DEFAULT 0,
+    match_id INT,
+    season INT DEFAULT 1,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS match_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    match_id INT NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    before_team1_score INT DEFAULT 0,
+    before_team2_score INT DEFAULT 0,
+    before_played BOOLEAN DEFAULT FALSE,
+    after_team1_score INT DEFAULT 0,
+    after_team2_score INT DEFAULT 0,
+    after_played BOOLEAN DEFAULT FALSE,
+    actor VARCHAR(255) NOT NULL,
+    reason TEXT,
+    reverts_event_id INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS league_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type VARCHAR(64) NOT NULL,
+    payload TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO league (name)
+SELECT 'Football League'
+WHERE NOT EXISTS (SELECT 1 FROM league);
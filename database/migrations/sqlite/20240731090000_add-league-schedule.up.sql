@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS league_schedule (
+    league_id INT PRIMARY KEY REFERENCES league(id) ON DELETE CASCADE,
+    cron VARCHAR(255) NOT NULL DEFAULT '',
+    run_interval VARCHAR(64) NOT NULL DEFAULT '',
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    next_run_at TIMESTAMP,
+    last_run_at TIMESTAMP,
+    last_error TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
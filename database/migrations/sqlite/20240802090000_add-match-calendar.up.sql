@@ -0,0 +1,9 @@
+ALTER TABLE matches ADD COLUMN kickoff_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS league_calendar (
+    league_id INT PRIMARY KEY REFERENCES league(id) ON DELETE CASCADE,
+    config TEXT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS matches_kickoff_at_idx ON matches (kickoff_at);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS league_fixture_config (
+    league_id INT PRIMARY KEY REFERENCES league(id) ON DELETE CASCADE,
+    config TEXT NOT NULL,
+    last_report TEXT,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
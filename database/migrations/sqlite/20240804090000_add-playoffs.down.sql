@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS playoff_matches;
+DROP TABLE IF EXISTS league_playoff_config;
+ALTER TABLE league DROP COLUMN playoff_champion_team_name;
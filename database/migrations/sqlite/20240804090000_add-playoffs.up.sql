@@ -0,0 +1,26 @@
+ALTER TABLE league ADD COLUMN playoff_champion_team_name VARCHAR(255);
+
+CREATE TABLE IF NOT EXISTS league_playoff_config (
+    league_id INT PRIMARY KEY REFERENCES league(id) ON DELETE CASCADE,
+    config TEXT NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS playoff_matches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    season INT NOT NULL,
+    round INT NOT NULL,
+    slot INT NOT NULL,
+    team1_id INT NOT NULL,
+    team1_name VARCHAR(255) NOT NULL,
+    team1_seed INT NOT NULL,
+    team2_id INT NOT NULL,
+    team2_name VARCHAR(255) NOT NULL,
+    team2_seed INT NOT NULL,
+    team1_score INT NOT NULL DEFAULT 0,
+    team2_score INT NOT NULL DEFAULT 0,
+    extra_time BOOLEAN NOT NULL DEFAULT FALSE,
+    team1_penalties INT,
+    team2_penalties INT,
+    UNIQUE (season, round, slot)
+);
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"football-simulation/types"
+	"log"
+	"regexp"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteMigrations are embedded so a single binary can create its database.
+//
+//go:embed migrations/sqlite/*.sql
+var sqliteMigrations embed.FS
+
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+// NewSQLiteStorage opens the SQLite database at path, creating it when
+// missing. SQLite allows a single writer, so the pool keeps one connection
+// and transactions queue up instead of failing with "database is locked".
+func NewSQLiteStorage(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000", path))
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+
+	err = db.Ping()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("DB: Successfully opened", path)
+	return db, nil
+}
+
+// MigrateSQLite applies the embedded SQLite migrations, "up" or "down".
+func MigrateSQLite(db *sql.DB, direction string) error {
+	source, err := iofs.New(sqliteMigrations, "migrations/sqlite")
+	if err != nil {
+		return err
+	}
+
+	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
+	if err != nil {
+		return err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite3", driver)
+	if err != nil {
+		return err
+	}
+
+	switch direction {
+	case "up":
+		err = m.Up()
+	case "down":
+		err = m.Down()
+	default:
+		return fmt.Errorf("unknown migration direction %q", direction)
+	}
+
+	if err != nil && err != migrate.ErrNoChange {
+		return err
+	}
+	return nil
+}
+
+// SQLite adapts db to the stores, whose queries use PostgreSQL's $n
+// placeholders. They are rewritten to ?n, which SQLite binds by number too.
+func SQLite(db types.DBTX) types.DBTX {
+	return sqliteDB{db: db}
+}
+
+// IsSQLite reports whether db was adapted by SQLite, for the few queries
+// that have no common syntax.
+func IsSQLite(db types.DBTX) bool {
+	_, ok := db.(sqliteDB)
+	return ok
+}
+
+type sqliteDB struct {
+	db types.DBTX
+}
+
+func (s sqliteDB) Exec(query string, args ...any) (sql.Result, error) {
+	return s.db.Exec(rebind(query), args...)
+}
+
+func (s sqliteDB) Query(query string, args ...any) (*sql.Rows, error) {
+	return s.db.Query(rebind(query), args...)
+}
+
+func (s sqliteDB) QueryRow(query string, args ...any) *sql.Row {
+	return s.db.QueryRow(rebind(query), args...)
+}
+
+func rebind(query string) string {
+	return placeholder.ReplaceAllString(query, "?$1")
+}
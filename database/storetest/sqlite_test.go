@@ -0,0 +1,52 @@
+package storetest_test
+
+import (
+	"football-simulation/database"
+	"football-simulation/database/storetest"
+	"football-simulation/service/auth"
+	"football-simulation/service/calendar"
+	"football-simulation/service/event"
+	"football-simulation/service/fixture"
+	"football-simulation/service/job"
+	"football-simulation/service/league"
+	"football-simulation/service/playoff"
+	"football-simulation/service/sanction"
+	"football-simulation/service/schedule"
+	"football-simulation/service/simulation"
+	"football-simulation/service/team"
+	"football-simulation/service/webhook"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteStores(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) storetest.Stores {
+		db, err := database.NewSQLiteStorage(filepath.Join(t.TempDir(), "football.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		if err := database.MigrateSQLite(db, "up"); err != nil {
+			t.Fatal(err)
+		}
+
+		conn := database.SQLite(db)
+
+		return storetest.Stores{
+			Transactor: database.NewSQLiteTransactor(db),
+			League:     league.NewStore(conn),
+			Team:       team.NewStore(conn),
+			Simulation: simulation.NewStore(conn),
+			Webhook:    webhook.NewStore(conn),
+			Schedule:   schedule.NewStore(conn),
+			Job:        job.NewStore(conn),
+			Calendar:   calendar.NewStore(conn),
+			Fixture:    fixture.NewStore(conn),
+			Playoff:    playoff.NewStore(conn),
+			APIKey:     auth.NewStore(conn),
+			Sanction:   sanction.NewStore(conn),
+			Event:      event.NewStore(conn),
+		}
+	})
+}
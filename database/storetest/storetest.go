@@ -0,0 +1,1309 @@
+// Package storetest is the conformance suite every storage backend must
+// pass, so the services behave the same whichever backend they run on.
+package storetest
+
+import (
+	"encoding/json"
+	"errors"
+	"football-simulation/types"
+	"testing"
+	"time"
+)
+
+// Stores is the backend under test. All stores must share one database.
+type Stores struct {
+	Transactor types.Transactor
+	League     types.LeagueStore
+	Team       types.Teamstore
+	Simulation types.SimulationStore
+	Webhook    types.WebhookStore
+	Schedule   types.ScheduleStore
+	Job        types.JobStore
+	Calendar   types.CalendarStore
+	Fixture    types.FixtureStore
+	Playoff    types.PlayoffStore
+	APIKey     types.APIKeyStore
+	Sanction   types.SanctionStore
+	Event      types.LeagueEventStore
+}
+
+// Run runs the suite. newStores is called for every test and must return
+// stores on an empty database holding only the default league row.
+func Run(t *testing.T, newStores func(t *testing.T) Stores) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s Stores)
+	}{
+		{"CreateAndGetTeams", testCreateAndGetTeams},
+		{"UpdateAndResetTeams", testUpdateAndResetTeams},
+		{"DeleteTeam", testDeleteTeam},
+		{"League", testLeague},
+		{"Fixture", testFixture},
+		{"MatchResults", testMatchResults},
+		{"MatchEvents", testMatchEvents},
+		{"Standings", testStandings},
+		{"HeadToHead", testHeadToHead},
+		{"Transactions", testTransactions},
+		{"Webhooks", testWebhooks},
+		{"WebhookDeliveries", testWebhookDeliveries},
+		{"Schedule", testSchedule},
+		{"Jobs", testJobs},
+		{"Calendar", testCalendar},
+		{"FixtureConfig", testFixtureConfig},
+		{"Playoffs", testPlayoffs},
+		{"APIKeys", testAPIKeys},
+		{"Sanctions", testSanctions},
+		{"LeagueEvents", testLeagueEvents},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fn(t, newStores(t))
+		})
+	}
+}
+
+func testCreateAndGetTeams(t *testing.T, s Stores) {
+	chelsea := createTeam(t, s, types.Team{Name: "Chelsea", Strength: 80, Points: 9, Metadata: map[string]string{"city": "London"}})
+	arsenal := createTeam(t, s, types.Team{Name: "Arsenal", Strength: 85})
+
+	if chelsea.ID == 0 || arsenal.ID == 0 || chelsea.ID == arsenal.ID {
+		t.Fatalf("CreateTeam ids = %d, %d, want distinct non-zero ids", chelsea.ID, arsenal.ID)
+	}
+
+	got := getTeamByID(t, s, chelsea.ID)
+	if got.Name != "Chelsea" || got.Strength != 80 || got.Metadata["city"] != "London" {
+		t.Errorf("GetTeamByID = %+v, want Chelsea with strength 80 and metadata", got)
+	}
+
+	if got.Points != 0 {
+		t.Errorf("CreateTeam stored points %d, want statistics to start at 0", got.Points)
+	}
+
+	byName, err := s.Team.GetTeamByName("Arsenal")
+	if err != nil {
+		t.Fatalf("GetTeamByName: %v", err)
+	}
+	if byName.ID != arsenal.ID {
+		t.Errorf("GetTeamByName id = %d, want %d", byName.ID, arsenal.ID)
+	}
+
+	teams, err := s.Team.GetTeams()
+	if err != nil {
+		t.Fatalf("GetTeams: %v", err)
+	}
+	if len(teams) != 2 {
+		t.Errorf("GetTeams returned %d teams, want 2", len(teams))
+	}
+
+	missing := getTeamByID(t, s, arsenal.ID+chelsea.ID+100)
+	if missing.ID != 0 {
+		t.Errorf("GetTeamByID of a missing team = %+v, want a zero team", missing)
+	}
+
+	byName, err = s.Team.GetTeamByName("Tottenham")
+	if err != nil || byName.ID != 0 {
+		t.Errorf("GetTeamByName of a missing team = %+v, %v, want a zero team", byName, err)
+	}
+
+	if _, err := s.Team.CreateTeam(types.Team{Name: "Chelsea", Strength: 70}); err == nil {
+		t.Error("CreateTeam with a duplicate name succeeded, want an error")
+	}
+}
+
+func testUpdateAndResetTeams(t *testing.T, s Stores) {
+	team := createTeam(t, s, types.Team{Name: "Chelsea", Strength: 80})
+
+	team.Name = "Chelsea FC"
+	team.Strength = 82
+	team.Points = 7
+	team.Matches = 3
+	team.Wins = 2
+	team.Draws = 1
+	team.GoalsFor = 5
+	team.GoalsAgainst = 2
+	team.GoalsDifference = 3
+	team.PointsDeducted = 2
+	team.Metadata = map[string]string{"stadium": "Stamford Bridge"}
+
+	if err := s.Team.UpdateTeam(team); err != nil {
+		t.Fatalf("UpdateTeam: %v", err)
+	}
+
+	got := getTeamByID(t, s, team.ID)
+	if got.Name != team.Name || got.Strength != 82 || got.Points != 7 || got.Matches != 3 || got.Wins != 2 ||
+		got.Draws != 1 || got.GoalsFor != 5 || got.GoalsAgainst != 2 || got.GoalsDifference != 3 ||
+		got.PointsDeducted != 2 || got.Metadata["stadium"] != "Stamford Bridge" {
+		t.Errorf("GetTeamByID after UpdateTeam = %+v, want %+v", got, team)
+	}
+
+	if err := s.Team.ResetTeams(); err != nil {
+		t.Fatalf("ResetTeams: %v", err)
+	}
+
+	got = getTeamByID(t, s, team.ID)
+	if got.Name != team.Name || got.Strength != 82 || got.Metadata["stadium"] != "Stamford Bridge" {
+		t.Errorf("ResetTeams changed the team itself: %+v", got)
+	}
+	if got.Points != 0 || got.Matches != 0 || got.Wins != 0 || got.Draws != 0 || got.GoalsFor != 0 ||
+		got.GoalsAgainst != 0 || got.GoalsDifference != 0 || got.PointsDeducted != 0 {
+		t.Errorf("ResetTeams left statistics: %+v", got)
+	}
+}
+
+func testDeleteTeam(t *testing.T, s Stores) {
+	chelsea := createTeam(t, s, types.Team{Name: "Chelsea", Strength: 80})
+	arsenal := createTeam(t, s, types.Team{Name: "Arsenal", Strength: 85})
+	liverpool := createTeam(t, s, types.Team{Name: "Liverpool", Strength: 88})
+
+	saveFixture(t, s, types.Match{Week: 1, Team1ID: chelsea.ID, Team2ID: arsenal.ID})
+
+	if err := s.Team.DeleteTeam(chelsea.ID); err == nil {
+		t.Error("DeleteTeam of a team with matches succeeded, want an error")
+	}
+
+	if err := s.Team.DeleteTeam(liverpool.ID); err != nil {
+		t.Fatalf("DeleteTeam: %v", err)
+	}
+
+	if got := getTeamByID(t, s, liverpool.ID); got.ID != 0 {
+		t.Errorf("GetTeamByID after DeleteTeam = %+v, want a zero team", got)
+	}
+}
+
+func testLeague(t *testing.T, s Stores) {
+	league := getLeague(t, s)
+	if league.ID == 0 || league.Version == 0 {
+		t.Fatalf("GetLeagueInfo = %+v, want the default league row", league)
+	}
+
+	update := types.League{
+		ID:                      league.ID,
+		Name:                    "Premier League",
+		CurrentWeek:             3,
+		TotalWeeks:              6,
+		ChampionTeamName:        "Arsenal",
+		PlayoffChampionTeamName: "Chelsea",
+		Season:                  2,
+		Seed:                    42,
+	}
+	if err := s.League.UpdateLeague(update); err != nil {
+		t.Fatalf("UpdateLeague: %v", err)
+	}
+
+	got := getLeague(t, s)
+	update.Version = league.Version + 1
+	if got != update {
+		t.Errorf("GetLeagueInfo after UpdateLeague = %+v, want %+v", got, update)
+	}
+
+	if err := s.League.IncrementWeek(); err != nil {
+		t.Fatalf("IncrementWeek: %v", err)
+	}
+
+	week, err := s.League.GetCurrentWeek()
+	if err != nil {
+		t.Fatalf("GetCurrentWeek: %v", err)
+	}
+	if week != 4 {
+		t.Errorf("GetCurrentWeek after IncrementWeek = %d, want 4", week)
+	}
+
+	if got := getLeague(t, s); got.Version != league.Version+2 {
+		t.Errorf("IncrementWeek left version %d, want %d", got.Version, league.Version+2)
+	}
+
+	locked, err := s.League.TryLockLeague(league.ID)
+	if err != nil || !locked {
+		t.Errorf("TryLockLeague = %v, %v, want the lock", locked, err)
+	}
+}
+
+func testFixture(t *testing.T, s Stores) {
+	chelsea := createTeam(t, s, types.Team{Name: "Chelsea", Strength: 80})
+	arsenal := createTeam(t, s, types.Team{Name: "Arsenal", Strength: 85})
+
+	saved := saveFixture(t, s,
+		types.Match{Week: 2, Team1ID: arsenal.ID, Team2ID: chelsea.ID},
+		types.Match{Week: 1, Team1ID: chelsea.ID, Team2ID: arsenal.ID},
+		types.Match{Week: 1, Team1ID: arsenal.ID, Team2ID: chelsea.ID, Team1Score: 1, Team2Score: 1, Played: true},
+	)
+
+	for _, match := range saved {
+		if match.ID == 0 || match.Version != 1 {
+			t.Errorf("SaveFixture returned %+v, want an id and version 1", match)
+		}
+	}
+
+	matches, err := s.League.GetAllMatches()
+	if err != nil {
+		t.Fatalf("GetAllMatches: %v", err)
+	}
+
+	wantOrder := []int{saved[1].ID, saved[2].ID, saved[0].ID}
+	if len(matches) != len(wantOrder) {
+		t.Fatalf("GetAllMatches returned %d matches, want %d", len(matches), len(wantOrder))
+	}
+	for i, id := range wantOrder {
+		if matches[i].ID != id {
+			t.Errorf("GetAllMatches[%d].ID = %d, want %d (ordered by week and id)", i, matches[i].ID, id)
+		}
+	}
+
+	got := getMatch(t, s, saved[2].ID)
+	if got != saved[2] {
+		t.Errorf("GetMatchByID = %+v, want %+v", got, saved[2])
+	}
+
+	if missing := getMatch(t, s, saved[0].ID+saved[1].ID+saved[2].ID+100); missing.ID != 0 {
+		t.Errorf("GetMatchByID of a missing match = %+v, want a zero match", missing)
+	}
+
+	setCurrentWeek(t, s, 1)
+
+	next, err := s.League.GetMatchesForNextWeek()
+	if err != nil {
+		t.Fatalf("GetMatchesForNextWeek: %v", err)
+	}
+	if len(next) != 1 || next[0].ID != saved[1].ID {
+		t.Errorf("GetMatchesForNextWeek = %+v, want only the unplayed match of week 1", next)
+	}
+
+	if err := s.League.ClearFixtures(); err != nil {
+		t.Fatalf("ClearFixtures: %v", err)
+	}
+
+	matches, err = s.League.GetAllMatches()
+	if err != nil {
+		t.Fatalf("GetAllMatches: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("GetAllMatches after ClearFixtures returned %d matches, want none", len(matches))
+	}
+}
+
+func testMatchResults(t *testing.T, s Stores) {
+	chelsea := createTeam(t, s, types.Team{Name: "Chelsea", Strength: 80})
+	arsenal := createTeam(t, s, types.Team{Name: "Arsenal", Strength: 85})
+
+	saved := saveFixture(t, s,
+		types.Match{Week: 1, Team1ID: chelsea.ID, Team2ID: arsenal.ID},
+		types.Match{Week: 1, Team1ID: arsenal.ID, Team2ID: chelsea.ID},
+	)
+
+	match := saved[0]
+	match.Team1Score = 2
+	match.Team2Score = 1
+	if err := s.League.SaveMatchResult(match); err != nil {
+		t.Fatalf("SaveMatchResult: %v", err)
+	}
+
+	got := getMatch(t, s, match.ID)
+	if !got.Played || got.Team1Score != 2 || got.Team2Score != 1 || got.Version != 2 {
+		t.Errorf("GetMatchByID after SaveMatchResult = %+v, want a played 2-1 at version 2", got)
+	}
+
+	played, err := s.League.GetMatchesByWeek(1)
+	if err != nil {
+		t.Fatalf("GetMatchesByWeek: %v", err)
+	}
+	if len(played) != 1 || played[0].ID != match.ID {
+		t.Errorf("GetMatchesByWeek = %+v, want only the played match", played)
+	}
+
+	got.Team1Score = 0
+	got.Team2Score = 0
+	got.Played = false
+	if err := s.League.UpdateMatch(got); err != nil {
+		t.Fatalf("UpdateMatch: %v", err)
+	}
+
+	got = getMatch(t, s, match.ID)
+	if got.Played || got.Team1Score != 0 || got.Team2Score != 0 || got.Version != 3 {
+		t.Errorf("GetMatchByID after UpdateMatch = %+v, want an unplayed match at version 3", got)
+	}
+}
+
+func testMatchEvents(t *testing.T, s Stores) {
+	chelsea := createTeam(t, s, types.Team{Name: "Chelsea", Strength: 80})
+	arsenal := createTeam(t, s, types.Team{Name: "Arsenal", Strength: 85})
+	match := saveFixture(t, s, types.Match{Week: 1, Team1ID: chelsea.ID, Team2ID: arsenal.ID})[0]
+
+	first := types.MatchEvent{MatchID: match.ID, AfterTeam1Score: 2, AfterTeam2Score: 1, AfterPlayed: true, Actor: types.SimulationActor, Reason: "simulated"}
+	if err := s.League.SaveMatchEvent(first); err != nil {
+		t.Fatalf("SaveMatchEvent: %v", err)
+	}
+
+	events := getMatchEvents(t, s, match.ID)
+	if len(events) != 1 {
+		t.Fatalf("GetMatchEvents returned %d events, want 1", len(events))
+	}
+
+	second := types.MatchEvent{
+		MatchID:          match.ID,
+		BeforeTeam1Score: 2,
+		BeforeTeam2Score: 1,
+		BeforePlayed:     true,
+		AfterTeam1Score:  1,
+		AfterTeam2Score:  1,
+		AfterPlayed:      true,
+		Actor:            "jane",
+		RevertsEventID:   events[0].ID,
+	}
+	if err := s.League.SaveMatchEvent(second); err != nil {
+		t.Fatalf("SaveMatchEvent: %v", err)
+	}
+
+	events = getMatchEvents(t, s, match.ID)
+	if len(events) != 2 {
+		t.Fatalf("GetMatchEvents returned %d events, want 2", len(events))
+	}
+
+	for i, want := range []types.MatchEvent{first, second} {
+		got := events[i]
+		if got.ID == 0 || got.CreatedAt.IsZero() {
+			t.Errorf("event %d = %+v, want an id and a creation time", i, got)
+		}
+
+		got.ID = 0
+		got.CreatedAt = want.CreatedAt
+		if got != want {
+			t.Errorf("event %d = %+v, want %+v", i, got, want)
+		}
+	}
+
+	if events[0].ID >= events[1].ID {
+		t.Errorf("GetMatchEvents ids = %d, %d, want them in order", events[0].ID, events[1].ID)
+	}
+
+	if err := s.League.ClearFixtures(); err != nil {
+		t.Fatalf("ClearFixtures: %v", err)
+	}
+
+	if events := getMatchEvents(t, s, match.ID); len(events) != 0 {
+		t.Errorf("GetMatchEvents after ClearFixtures returned %d events, want none", len(events))
+	}
+}
+
+func testStandings(t *testing.T, s Stores) {
+	stats := []types.Team{
+		{Name: "Chelsea", Points: 6, GoalsFor: 5, GoalsAgainst: 5},
+		{Name: "Arsenal", Points: 9, GoalsFor: 4, GoalsAgainst: 3},
+		{Name: "Liverpool", Points: 6, GoalsFor: 7, GoalsAgainst: 5},
+		{Name: "Everton", Points: 6, GoalsFor: 8, GoalsAgainst: 6},
+	}
+
+	for _, team := range stats {
+		created := createTeam(t, s, types.Team{Name: team.Name, Strength: 50})
+		team.ID = created.ID
+		team.Strength = 50
+		team.GoalsDifference = team.GoalsFor - team.GoalsAgainst
+		if err := s.Team.UpdateTeam(team); err != nil {
+			t.Fatalf("UpdateTeam: %v", err)
+		}
+	}
+
+	standings, err := s.League.GetStandings()
+	if err != nil {
+		t.Fatalf("GetStandings: %v", err)
+	}
+
+	want := []string{"Arsenal", "Everton", "Liverpool", "Chelsea"}
+	if len(standings) != len(want) {
+		t.Fatalf("GetStandings returned %d teams, want %d", len(standings), len(want))
+	}
+	for i, name := range want {
+		if standings[i].Name != name {
+			t.Errorf("GetStandings[%d] = %s, want %s (points, goal difference, goals for)", i, standings[i].Name, name)
+		}
+	}
+}
+
+func testHeadToHead(t *testing.T, s Stores) {
+	chelsea := createTeam(t, s, types.Team{Name: "Chelsea", Strength: 80})
+	arsenal := createTeam(t, s, types.Team{Name: "Arsenal", Strength: 85})
+	liverpool := createTeam(t, s, types.Team{Name: "Liverpool", Strength: 88})
+
+	saveFixture(t, s,
+		types.Match{Week: 1, Team1ID: chelsea.ID, Team2ID: arsenal.ID, Team1Score: 3, Team2Score: 1, Played: true},
+		types.Match{Week: 2, Team1ID: arsenal.ID, Team2ID: chelsea.ID},
+		types.Match{Week: 2, Team1ID: chelsea.ID, Team2ID: liverpool.ID, Team1Score: 0, Team2Score: 2, Played: true},
+	)
+
+	if err := s.League.ArchiveFixtures(); err != nil {
+		t.Fatalf("ArchiveFixtures: %v", err)
+	}
+	if err := s.League.ClearFixtures(); err != nil {
+		t.Fatalf("ClearFixtures: %v", err)
+	}
+
+	league := getLeague(t, s)
+	league.Season++
+	if err := s.League.UpdateLeague(league); err != nil {
+		t.Fatalf("UpdateLeague: %v", err)
+	}
+
+	saveFixture(t, s, types.Match{Week: 1, Team1ID: arsenal.ID, Team2ID: chelsea.ID, Team1Score: 2, Team2Score: 2, Played: true})
+
+	matches, err := s.League.GetHeadToHeadMatches(chelsea.ID, arsenal.ID)
+	if err != nil {
+		t.Fatalf("GetHeadToHeadMatches: %v", err)
+	}
+
+	want := []types.HeadToHeadMatch{
+		{Season: league.Season - 1, Archived: true, Week: 1, Team1ID: chelsea.ID, Team1Name: "Chelsea", Team2ID: arsenal.ID, Team2Name: "Arsenal", Team1Score: 3, Team2Score: 1, Played: true},
+		{Season: league.Season, Week: 1, Team1ID: arsenal.ID, Team1Name: "Arsenal", Team2ID: chelsea.ID, Team2Name: "Chelsea", Team1Score: 2, Team2Score: 2, Played: true},
+	}
+
+	if len(matches) != len(want) {
+		t.Fatalf("GetHeadToHeadMatches returned %+v, want %+v", matches, want)
+	}
+	for i := range want {
+		if matches[i] != want[i] {
+			t.Errorf("GetHeadToHeadMatches[%d] = %+v, want %+v", i, matches[i], want[i])
+		}
+	}
+}
+
+func testTransactions(t *testing.T, s Stores) {
+	errAbort := errors.New("abort")
+
+	err := s.Transactor.WithinTransaction(func(tx types.DBTX) error {
+		if _, err := s.Team.WithTx(tx).CreateTeam(types.Team{Name: "Chelsea", Strength: 80}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("WithinTransaction = %v, want %v", err, errAbort)
+	}
+
+	if team, _ := s.Team.GetTeamByName("Chelsea"); team.ID != 0 {
+		t.Errorf("team created in a rolled back transaction exists: %+v", team)
+	}
+
+	err = s.Transactor.WithinTransaction(func(tx types.DBTX) error {
+		arsenal, err := s.Team.WithTx(tx).CreateTeam(types.Team{Name: "Arsenal", Strength: 85})
+		if err != nil {
+			return err
+		}
+
+		liverpool, err := s.Team.WithTx(tx).CreateTeam(types.Team{Name: "Liverpool", Strength: 88})
+		if err != nil {
+			return err
+		}
+
+		league, err := s.League.WithTx(tx).GetLeagueInfo()
+		if err != nil {
+			return err
+		}
+
+		locked, err := s.League.WithTx(tx).TryLockLeague(league.ID)
+		if err != nil || !locked {
+			t.Errorf("TryLockLeague in a transaction = %v, %v, want the lock", locked, err)
+		}
+
+		_, err = s.Simulation.WithTx(tx).SaveFixture([]types.Match{{Week: 1, Team1ID: arsenal.ID, Team2ID: liverpool.ID}})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithinTransaction: %v", err)
+	}
+
+	if team, _ := s.Team.GetTeamByName("Arsenal"); team.ID == 0 {
+		t.Error("team created in a committed transaction is missing")
+	}
+
+	matches, err := s.League.GetAllMatches()
+	if err != nil {
+		t.Fatalf("GetAllMatches: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Errorf("GetAllMatches after commit returned %d matches, want 1", len(matches))
+	}
+}
+
+func testWebhooks(t *testing.T, s Stores) {
+	created, err := s.Webhook.CreateWebhook(types.Webhook{
+		URL:        "http://example.com/hook",
+		Secret:     "0123456789abcdef",
+		EventTypes: []string{types.WebhookWeekPlayed, types.WebhookChampionDecided},
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	if created.ID == 0 || created.CreatedAt.IsZero() {
+		t.Errorf("CreateWebhook = %+v, want an id and creation time", created)
+	}
+
+	webhook, err := s.Webhook.GetWebhookByID(created.ID)
+	if err != nil {
+		t.Fatalf("GetWebhookByID: %v", err)
+	}
+	if webhook.URL != created.URL || webhook.Secret != created.Secret || len(webhook.EventTypes) != 2 || webhook.EventTypes[1] != types.WebhookChampionDecided {
+		t.Errorf("GetWebhookByID = %+v, want %+v", webhook, created)
+	}
+
+	if _, err := s.Webhook.CreateDelivery(types.WebhookDelivery{
+		WebhookID:     created.ID,
+		EventType:     types.WebhookWeekPlayed,
+		Payload:       json.RawMessage(`{"week":1}`),
+		Status:        types.DeliveryPending,
+		NextAttemptAt: time.Now().UTC(),
+	}); err != nil {
+		t.Fatalf("CreateDelivery: %v", err)
+	}
+
+	if err := s.Webhook.DeleteWebhook(created.ID); err != nil {
+		t.Fatalf("DeleteWebhook: %v", err)
+	}
+
+	if webhook, _ := s.Webhook.GetWebhookByID(created.ID); webhook.ID != 0 {
+		t.Errorf("deleted webhook still exists: %+v", webhook)
+	}
+
+	deliveries, err := s.Webhook.GetDeliveries(created.ID)
+	if err != nil {
+		t.Fatalf("GetDeliveries: %v", err)
+	}
+	if len(deliveries) != 0 {
+		t.Errorf("deleting a webhook left %d deliveries, want 0", len(deliveries))
+	}
+}
+
+func testWebhookDeliveries(t *testing.T, s Stores) {
+	webhook, err := s.Webhook.CreateWebhook(types.Webhook{URL: "http://example.com/hook", Secret: "0123456789abcdef", EventTypes: []string{types.WebhookWeekPlayed}})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	due, err := s.Webhook.CreateDelivery(types.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventType:     types.WebhookWeekPlayed,
+		Payload:       json.RawMessage(`{"week":1}`),
+		Status:        types.DeliveryPending,
+		NextAttemptAt: now.Add(-time.Second),
+	})
+	if err != nil {
+		t.Fatalf("CreateDelivery: %v", err)
+	}
+
+	if _, err := s.Webhook.CreateDelivery(types.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventType:     types.WebhookWeekPlayed,
+		Payload:       json.RawMessage(`{"week":2}`),
+		Status:        types.DeliveryPending,
+		NextAttemptAt: now.Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("CreateDelivery: %v", err)
+	}
+
+	deliveries, err := s.Webhook.GetDueDeliveries(now, 10)
+	if err != nil {
+		t.Fatalf("GetDueDeliveries: %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].ID != due.ID {
+		t.Fatalf("GetDueDeliveries = %+v, want only delivery %d", deliveries, due.ID)
+	}
+
+	var payload struct{ Week int }
+	if err := json.Unmarshal(deliveries[0].Payload, &payload); err != nil || payload.Week != 1 {
+		t.Errorf("due delivery payload = %s, want week 1", deliveries[0].Payload)
+	}
+
+	lease := now.Add(time.Minute)
+	claimed, err := s.Webhook.ClaimDelivery(due.ID, deliveries[0].NextAttemptAt, lease)
+	if err != nil || !claimed {
+		t.Fatalf("ClaimDelivery = %v, %v, want the claim", claimed, err)
+	}
+
+	claimed, err = s.Webhook.ClaimDelivery(due.ID, deliveries[0].NextAttemptAt, lease)
+	if err != nil || claimed {
+		t.Errorf("second ClaimDelivery = %v, %v, want no claim", claimed, err)
+	}
+
+	if deliveries, _ := s.Webhook.GetDueDeliveries(now, 10); len(deliveries) != 0 {
+		t.Errorf("claimed delivery is still due: %+v", deliveries)
+	}
+
+	deliveredAt := now
+	delivery := deliveries[0]
+	delivery.Status = types.DeliveryDelivered
+	delivery.Attempts = 1
+	delivery.ResponseStatus = 204
+	delivery.DeliveredAt = &deliveredAt
+	// the update only lands while the lease is held
+	if updated, err := s.Webhook.UpdateDelivery(delivery, lease.Add(time.Second)); err != nil || updated {
+		t.Errorf("UpdateDelivery with another lease = %v, %v, want no update", updated, err)
+	}
+	if updated, err := s.Webhook.UpdateDelivery(delivery, lease); err != nil || !updated {
+		t.Fatalf("UpdateDelivery = %v, %v, want the update", updated, err)
+	}
+	if updated, err := s.Webhook.UpdateDelivery(delivery, lease); err != nil || updated {
+		t.Errorf("UpdateDelivery of a delivered delivery = %v, %v, want no update", updated, err)
+	}
+
+	deliveries, err = s.Webhook.GetDeliveries(webhook.ID)
+	if err != nil {
+		t.Fatalf("GetDeliveries: %v", err)
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("GetDeliveries returned %d deliveries, want 2", len(deliveries))
+	}
+
+	updated := deliveries[1]
+	if deliveries[0].ID == due.ID {
+		updated = deliveries[0]
+	}
+	if updated.Status != types.DeliveryDelivered || updated.Attempts != 1 || updated.ResponseStatus != 204 || updated.DeliveredAt == nil {
+		t.Errorf("updated delivery = %+v, want delivered after one attempt", updated)
+	}
+}
+
+func testSchedule(t *testing.T, s Stores) {
+	league := getLeague(t, s)
+
+	if schedule, err := s.Schedule.GetSchedule(league.ID); err != nil || schedule.LeagueID != 0 {
+		t.Fatalf("GetSchedule before one is saved = %+v, %v, want an empty schedule", schedule, err)
+	}
+
+	next := time.Now().UTC().Truncate(time.Microsecond)
+	err := s.Schedule.SaveSchedule(types.LeagueSchedule{LeagueID: league.ID, Interval: "1h", NextRunAt: &next})
+	if err != nil {
+		t.Fatalf("SaveSchedule: %v", err)
+	}
+
+	schedule, err := s.Schedule.GetSchedule(league.ID)
+	if err != nil {
+		t.Fatalf("GetSchedule: %v", err)
+	}
+	if schedule.LeagueID != league.ID || schedule.Interval != "1h" || schedule.Paused || schedule.NextRunAt == nil {
+		t.Fatalf("GetSchedule = %+v, want the saved interval schedule", schedule)
+	}
+
+	following := next.Add(time.Hour)
+	claimed, err := s.Schedule.ClaimRun(league.ID, *schedule.NextRunAt, following)
+	if err != nil || !claimed {
+		t.Fatalf("ClaimRun = %v, %v, want the claim", claimed, err)
+	}
+
+	claimed, err = s.Schedule.ClaimRun(league.ID, *schedule.NextRunAt, following)
+	if err != nil || claimed {
+		t.Errorf("second ClaimRun = %v, %v, want no claim", claimed, err)
+	}
+
+	if err := s.Schedule.RecordRun(league.ID, next, "league_busy"); err != nil {
+		t.Fatalf("RecordRun: %v", err)
+	}
+
+	if err := s.Schedule.SetPaused(league.ID, true, nil); err != nil {
+		t.Fatalf("SetPaused: %v", err)
+	}
+
+	schedule, err = s.Schedule.GetSchedule(league.ID)
+	if err != nil {
+		t.Fatalf("GetSchedule: %v", err)
+	}
+	if !schedule.Paused || schedule.NextRunAt != nil || schedule.LastRunAt == nil || schedule.LastError != "league_busy" {
+		t.Errorf("paused schedule = %+v, want it paused with the recorded run", schedule)
+	}
+
+	if claimed, _ := s.Schedule.ClaimRun(league.ID, following, following.Add(time.Hour)); claimed {
+		t.Error("ClaimRun claimed a run of a paused schedule")
+	}
+
+	err = s.Schedule.SaveSchedule(types.LeagueSchedule{LeagueID: league.ID, Cron: "0 20 * * fri", NextRunAt: &following, LastRunAt: schedule.LastRunAt})
+	if err != nil {
+		t.Fatalf("SaveSchedule over an existing schedule: %v", err)
+	}
+
+	schedule, err = s.Schedule.GetSchedule(league.ID)
+	if err != nil {
+		t.Fatalf("GetSchedule: %v", err)
+	}
+	if schedule.Cron != "0 20 * * fri" || schedule.Interval != "" || schedule.Paused || schedule.LastError != "" {
+		t.Errorf("replaced schedule = %+v, want the active cron schedule", schedule)
+	}
+
+	if err := s.Schedule.DeleteSchedule(league.ID); err != nil {
+		t.Fatalf("DeleteSchedule: %v", err)
+	}
+
+	if schedule, _ := s.Schedule.GetSchedule(league.ID); schedule.LeagueID != 0 {
+		t.Errorf("deleted schedule still exists: %+v", schedule)
+	}
+}
+
+func testJobs(t *testing.T, s Stores) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	created, err := s.Job.CreateJob(types.Job{
+		Type:        types.JobPredictions,
+		Status:      types.JobQueued,
+		Simulations: 5000,
+		Progress:    types.JobProgress{Total: 5000, Unit: "simulations"},
+		HeartbeatAt: now,
+	})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+
+	if err := s.Job.RequestCancel(created.ID); err != nil {
+		t.Fatalf("RequestCancel: %v", err)
+	}
+
+	job := *created
+	job.Status = types.JobSucceeded
+	job.Progress.Done = 5000
+	job.Result = json.RawMessage(`[{"team_id":1}]`)
+	job.StartedAt = &now
+	job.FinishedAt = &now
+	if err := s.Job.UpdateJob(job); err != nil {
+		t.Fatalf("UpdateJob: %v", err)
+	}
+
+	stored, err := s.Job.GetJob(created.ID)
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	if stored.Status != types.JobSucceeded || stored.Progress != job.Progress || stored.Simulations != 5000 || stored.FinishedAt == nil {
+		t.Errorf("GetJob = %+v, want the updated job", stored)
+	}
+	if !stored.CancelRequested {
+		t.Error("UpdateJob dropped the requested cancellation")
+	}
+
+	var result []types.Prediction
+	if err := json.Unmarshal(stored.Result, &result); err != nil || len(result) != 1 {
+		t.Errorf("stored result = %s, want one prediction", stored.Result)
+	}
+
+	stale, err := s.Job.CreateJob(types.Job{Type: types.JobPlayAll, Status: types.JobRunning, HeartbeatAt: now.Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+
+	alive, err := s.Job.CreateJob(types.Job{Type: types.JobPlayAll, Status: types.JobQueued, HeartbeatAt: now.Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+
+	if err := s.Job.Heartbeat([]int{alive.ID}, now); err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+
+	if err := s.Job.FailStaleJobs(now.Add(-time.Minute), "interrupted"); err != nil {
+		t.Fatalf("FailStaleJobs: %v", err)
+	}
+
+	jobs, err := s.Job.GetJobs(10)
+	if err != nil {
+		t.Fatalf("GetJobs: %v", err)
+	}
+	if len(jobs) != 3 || jobs[0].ID != alive.ID || jobs[1].ID != stale.ID {
+		t.Fatalf("GetJobs = %+v, want the three jobs newest first", jobs)
+	}
+	if jobs[0].Status != types.JobQueued {
+		t.Errorf("job with a fresh heartbeat = %s, want it still queued", jobs[0].Status)
+	}
+	if jobs[1].Status != types.JobFailed || jobs[1].Error != "interrupted" || jobs[1].ErrorCode == "" {
+		t.Errorf("stale job = %+v, want it failed", jobs[1])
+	}
+	if jobs[2].Status != types.JobSucceeded {
+		t.Errorf("finished job = %s, want it left alone", jobs[2].Status)
+	}
+}
+
+func testCalendar(t *testing.T, s Stores) {
+	league := getLeague(t, s)
+
+	if calendar, err := s.Calendar.GetCalendar(league.ID); err != nil || calendar.LeagueID != 0 {
+		t.Fatalf("GetCalendar before one is saved = %+v, %v, want an empty calendar", calendar, err)
+	}
+
+	config := types.CalendarConfig{
+		StartDate:     "2024-08-10",
+		Timezone:      "Europe/London",
+		Matchdays:     []string{"saturday", "sunday"},
+		KickoffTimes:  []string{"12:30", "15:00"},
+		MidweekRounds: []int{3},
+		Blackouts:     []types.Blackout{{From: "2024-09-02", To: "2024-09-10", Reason: "international break"}},
+	}
+	if err := s.Calendar.SaveCalendar(types.LeagueCalendar{LeagueID: league.ID, CalendarConfig: config}); err != nil {
+		t.Fatalf("SaveCalendar: %v", err)
+	}
+
+	calendar, err := s.Calendar.GetCalendar(league.ID)
+	if err != nil {
+		t.Fatalf("GetCalendar: %v", err)
+	}
+	if calendar.LeagueID != league.ID || calendar.Timezone != "Europe/London" || len(calendar.KickoffTimes) != 2 ||
+		len(calendar.Blackouts) != 1 || calendar.Blackouts[0].Reason != "international break" || calendar.UpdatedAt.IsZero() {
+		t.Fatalf("GetCalendar = %+v, want the saved calendar", calendar)
+	}
+
+	config.Timezone = ""
+	if err := s.Calendar.SaveCalendar(types.LeagueCalendar{LeagueID: league.ID, CalendarConfig: config}); err != nil {
+		t.Fatalf("SaveCalendar over an existing calendar: %v", err)
+	}
+	if calendar, _ := s.Calendar.GetCalendar(league.ID); calendar.Timezone != "" {
+		t.Errorf("replaced calendar = %+v, want it without a timezone", calendar)
+	}
+
+	chelsea := createTeam(t, s, types.Team{Name: "Chelsea", Strength: 80})
+	arsenal := createTeam(t, s, types.Team{Name: "Arsenal", Strength: 85})
+	saturday := time.Date(2024, 8, 10, 15, 0, 0, 0, time.UTC)
+	fixture := saveFixture(t, s,
+		types.Match{Week: 1, Team1ID: chelsea.ID, Team2ID: arsenal.ID, KickoffAt: &saturday},
+		types.Match{Week: 2, Team1ID: arsenal.ID, Team2ID: chelsea.ID},
+	)
+
+	if got := getMatch(t, s, fixture[0].ID); got.KickoffAt == nil || !got.KickoffAt.Equal(saturday) {
+		t.Errorf("saved kick-off = %v, want %v", got.KickoffAt, saturday)
+	}
+	if got := getMatch(t, s, fixture[1].ID); got.KickoffAt != nil {
+		t.Errorf("match without a kick-off has %v", got.KickoffAt)
+	}
+
+	nextSaturday := saturday.AddDate(0, 0, 7)
+	if err := s.Calendar.SetKickoff(fixture[1].ID, &nextSaturday); err != nil {
+		t.Fatalf("SetKickoff: %v", err)
+	}
+
+	matches, err := s.Calendar.GetMatchesBetween(saturday, nextSaturday)
+	if err != nil {
+		t.Fatalf("GetMatchesBetween: %v", err)
+	}
+	if len(matches) != 1 || matches[0].ID != fixture[0].ID {
+		t.Errorf("GetMatchesBetween = %+v, want only the first match, the end being excluded", matches)
+	}
+
+	matches, err = s.Calendar.GetMatchesBetween(saturday.AddDate(0, 0, -1), nextSaturday.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("GetMatchesBetween: %v", err)
+	}
+	if len(matches) != 2 || matches[0].ID != fixture[0].ID || matches[1].KickoffAt == nil || !matches[1].KickoffAt.Equal(nextSaturday) {
+		t.Errorf("GetMatchesBetween = %+v, want both matches in kick-off order", matches)
+	}
+
+	if err := s.Calendar.SetKickoff(fixture[0].ID, nil); err != nil {
+		t.Fatalf("SetKickoff: %v", err)
+	}
+	if got := getMatch(t, s, fixture[0].ID); got.KickoffAt != nil {
+		t.Errorf("cleared kick-off = %v, want none", got.KickoffAt)
+	}
+
+	if err := s.Calendar.DeleteCalendar(league.ID); err != nil {
+		t.Fatalf("DeleteCalendar: %v", err)
+	}
+	if calendar, _ := s.Calendar.GetCalendar(league.ID); calendar.LeagueID != 0 {
+		t.Errorf("deleted calendar still exists: %+v", calendar)
+	}
+}
+
+func testFixtureConfig(t *testing.T, s Stores) {
+	league := getLeague(t, s)
+
+	if config, err := s.Fixture.GetFixtureConfig(league.ID); err != nil || config.LeagueID != 0 || config.LastReport != nil {
+		t.Fatalf("GetFixtureConfig before one is saved = %+v, %v, want an empty configuration", config, err)
+	}
+
+	config := types.FixtureConfig{
+		MaxConsecutive: 3,
+		Shuffle:        true,
+		Derbies:        []types.Derby{{Team1ID: 1, Team2ID: 2, Week: 4}},
+	}
+	if err := s.Fixture.SaveFixtureConfig(types.LeagueFixtureConfig{LeagueID: league.ID, FixtureConfig: config}); err != nil {
+		t.Fatalf("SaveFixtureConfig: %v", err)
+	}
+
+	saved, err := s.Fixture.GetFixtureConfig(league.ID)
+	if err != nil {
+		t.Fatalf("GetFixtureConfig: %v", err)
+	}
+	if saved.LeagueID != league.ID || saved.MaxConsecutive != 3 || !saved.Shuffle || len(saved.Derbies) != 1 ||
+		saved.Derbies[0].Week != 4 || saved.LastReport != nil || saved.UpdatedAt.IsZero() {
+		t.Fatalf("GetFixtureConfig = %+v, want the saved configuration", saved)
+	}
+
+	report := &types.FixtureReport{
+		Attempts: 50,
+		Unmet: []types.UnmetConstraint{
+			{Constraint: types.ConstraintDerbyWeek, Week: 4, Teams: []string{"Chelsea", "Arsenal"}, Message: "Chelsea and Arsenal do not meet in week 4"},
+		},
+	}
+	config.Shuffle = false
+	if err := s.Fixture.SaveFixtureConfig(types.LeagueFixtureConfig{LeagueID: league.ID, FixtureConfig: config, LastReport: report}); err != nil {
+		t.Fatalf("SaveFixtureConfig over an existing configuration: %v", err)
+	}
+
+	saved, err = s.Fixture.GetFixtureConfig(league.ID)
+	if err != nil {
+		t.Fatalf("GetFixtureConfig: %v", err)
+	}
+	if saved.Shuffle || saved.LastReport == nil || saved.LastReport.Attempts != 50 || len(saved.LastReport.Unmet) != 1 ||
+		saved.LastReport.Unmet[0].Constraint != types.ConstraintDerbyWeek || len(saved.LastReport.Unmet[0].Teams) != 2 {
+		t.Errorf("replaced configuration = %+v, want it with the report", saved)
+	}
+}
+
+func testPlayoffs(t *testing.T, s Stores) {
+	league := getLeague(t, s)
+
+	if config, err := s.Playoff.GetPlayoffConfig(league.ID); err != nil || config.LeagueID != 0 {
+		t.Fatalf("GetPlayoffConfig before one is saved = %+v, %v, want an empty configuration", config, err)
+	}
+
+	config := types.PlayoffConfig{Pairings: [][2]int{{3, 6}, {4, 5}}}
+	if err := s.Playoff.SavePlayoffConfig(types.LeaguePlayoffConfig{LeagueID: league.ID, PlayoffConfig: config}); err != nil {
+		t.Fatalf("SavePlayoffConfig: %v", err)
+	}
+	config.Pairings = [][2]int{{1, 2}}
+	if err := s.Playoff.SavePlayoffConfig(types.LeaguePlayoffConfig{LeagueID: league.ID, PlayoffConfig: config}); err != nil {
+		t.Fatalf("SavePlayoffConfig over an existing configuration: %v", err)
+	}
+
+	saved, err := s.Playoff.GetPlayoffConfig(league.ID)
+	if err != nil {
+		t.Fatalf("GetPlayoffConfig: %v", err)
+	}
+	if saved.LeagueID != league.ID || len(saved.Pairings) != 1 || saved.Pairings[0] != [2]int{1, 2} || saved.UpdatedAt.IsZero() {
+		t.Fatalf("GetPlayoffConfig = %+v, want the replaced configuration", saved)
+	}
+
+	four, five := 4, 2
+	matches, err := s.Playoff.SavePlayoffMatches([]types.PlayoffMatch{
+		{Season: 2, Round: 1, Slot: 2, Team1ID: 4, Team1Name: "Everton", Team1Seed: 4, Team2ID: 5, Team2Name: "Fulham", Team2Seed: 5,
+			KnockoutResult: types.KnockoutResult{Team1Score: 1, Team2Score: 1, ExtraTime: true, Team1Penalties: &four, Team2Penalties: &five}},
+		{Season: 2, Round: 1, Slot: 1, Team1ID: 3, Team1Name: "Chelsea", Team1Seed: 3, Team2ID: 6, Team2Name: "Leeds", Team2Seed: 6,
+			KnockoutResult: types.KnockoutResult{Team1Score: 2, Team2Score: 0}},
+		{Season: 1, Round: 1, Slot: 1, Team1ID: 1, Team1Name: "Arsenal", Team1Seed: 1, Team2ID: 2, Team2Name: "Burnley", Team2Seed: 2},
+	})
+	if err != nil {
+		t.Fatalf("SavePlayoffMatches: %v", err)
+	}
+	if len(matches) != 3 || matches[0].ID == 0 || matches[0].ID == matches[1].ID || !matches[0].Played {
+		t.Fatalf("SavePlayoffMatches = %+v, want three played matches with ids", matches)
+	}
+
+	if _, err := s.Playoff.SavePlayoffMatches([]types.PlayoffMatch{{Season: 2, Round: 1, Slot: 1, Team1ID: 1, Team1Name: "Arsenal", Team2ID: 2, Team2Name: "Burnley"}}); err == nil {
+		t.Errorf("SavePlayoffMatches saved a second match in the same slot")
+	}
+
+	season, err := s.Playoff.GetPlayoffMatches(2)
+	if err != nil {
+		t.Fatalf("GetPlayoffMatches: %v", err)
+	}
+	if len(season) != 2 || season[0].Slot != 1 || season[1].Slot != 2 {
+		t.Fatalf("GetPlayoffMatches = %+v, want the two matches of season 2 by slot", season)
+	}
+	if season[0].Team1Penalties != nil || season[0].ExtraTime || season[0].Team1Name != "Chelsea" || !season[0].Played {
+		t.Errorf("match decided in normal time = %+v", season[0])
+	}
+	if !season[1].ExtraTime || season[1].Team1Penalties == nil || *season[1].Team1Penalties != 4 || *season[1].Team2Penalties != 2 {
+		t.Errorf("match decided on penalties = %+v", season[1])
+	}
+
+	if err := s.Playoff.DeletePlayoffMatches(2); err != nil {
+		t.Fatalf("DeletePlayoffMatches: %v", err)
+	}
+	if season, err := s.Playoff.GetPlayoffMatches(2); err != nil || len(season) != 0 {
+		t.Errorf("GetPlayoffMatches of a deleted season = %+v, %v, want no matches", season, err)
+	}
+	if season, err := s.Playoff.GetPlayoffMatches(1); err != nil || len(season) != 1 {
+		t.Errorf("GetPlayoffMatches of an earlier season = %+v, %v, want it kept", season, err)
+	}
+}
+
+func testAPIKeys(t *testing.T, s Stores) {
+	if key, err := s.APIKey.GetAPIKeyByHash("unknown"); err != nil || key.ID != 0 {
+		t.Fatalf("GetAPIKeyByHash of an unknown hash = %+v, %v, want an empty key", key, err)
+	}
+
+	scoreboard, err := s.APIKey.CreateAPIKey(types.APIKey{Name: "scoreboard", Role: types.RoleViewer}, "hash-scoreboard")
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	admin, err := s.APIKey.CreateAPIKey(types.APIKey{Name: "admin", Role: types.RoleAdmin}, "hash-admin")
+	if err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	if scoreboard.ID == 0 || scoreboard.ID == admin.ID || scoreboard.CreatedAt.IsZero() || scoreboard.RevokedAt != nil {
+		t.Fatalf("CreateAPIKey = %+v, %+v, want distinct ids and a creation time", scoreboard, admin)
+	}
+
+	if _, err := s.APIKey.CreateAPIKey(types.APIKey{Name: "scoreboard", Role: types.RoleAdmin}, "hash-other"); err == nil {
+		t.Error("CreateAPIKey with a duplicate name succeeded, want an error")
+	}
+	if _, err := s.APIKey.CreateAPIKey(types.APIKey{Name: "dashboard", Role: types.RoleAdmin}, "hash-admin"); err == nil {
+		t.Error("CreateAPIKey with a duplicate hash succeeded, want an error")
+	}
+
+	got, err := s.APIKey.GetAPIKeyByHash("hash-admin")
+	if err != nil {
+		t.Fatalf("GetAPIKeyByHash: %v", err)
+	}
+	if got.ID != admin.ID || got.Name != "admin" || got.Role != types.RoleAdmin || got.RevokedAt != nil {
+		t.Errorf("GetAPIKeyByHash = %+v, want the admin key", got)
+	}
+
+	if err := s.APIKey.RevokeAPIKey(admin.ID); err != nil {
+		t.Fatalf("RevokeAPIKey: %v", err)
+	}
+	revoked, err := s.APIKey.GetAPIKeyByHash("hash-admin")
+	if err != nil {
+		t.Fatalf("GetAPIKeyByHash: %v", err)
+	}
+	if revoked.RevokedAt == nil {
+		t.Fatal("RevokeAPIKey left the key active")
+	}
+
+	// revoking again keeps the first revocation time
+	if err := s.APIKey.RevokeAPIKey(admin.ID); err != nil {
+		t.Fatalf("RevokeAPIKey of a revoked key: %v", err)
+	}
+
+	keys, err := s.APIKey.GetAPIKeys()
+	if err != nil {
+		t.Fatalf("GetAPIKeys: %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != scoreboard.ID || keys[1].ID != admin.ID {
+		t.Fatalf("GetAPIKeys = %+v, want both keys by id", keys)
+	}
+	if keys[0].RevokedAt != nil || keys[1].RevokedAt == nil || !keys[1].RevokedAt.Equal(*revoked.RevokedAt) {
+		t.Errorf("GetAPIKeys = %+v, want only the admin key revoked, at its first revocation", keys)
+	}
+
+	// replacing the hash retires the old one and reactivates the key
+	if err := s.APIKey.ReplaceAPIKeyHash(admin.ID, "hash-scoreboard"); err == nil {
+		t.Error("ReplaceAPIKeyHash with the hash of another key succeeded, want an error")
+	}
+	if err := s.APIKey.ReplaceAPIKeyHash(admin.ID, "hash-rotated"); err != nil {
+		t.Fatalf("ReplaceAPIKeyHash: %v", err)
+	}
+	if old, err := s.APIKey.GetAPIKeyByHash("hash-admin"); err != nil || old.ID != 0 {
+		t.Errorf("GetAPIKeyByHash of the replaced hash = %+v, %v, want an empty key", old, err)
+	}
+	rotated, err := s.APIKey.GetAPIKeyByHash("hash-rotated")
+	if err != nil {
+		t.Fatalf("GetAPIKeyByHash: %v", err)
+	}
+	if rotated.ID != admin.ID || rotated.Name != "admin" || rotated.Role != types.RoleAdmin || rotated.RevokedAt != nil {
+		t.Errorf("GetAPIKeyByHash of the new hash = %+v, want the admin key active again", rotated)
+	}
+}
+
+func testSanctions(t *testing.T, s Stores) {
+	chelsea := createTeam(t, s, types.Team{Name: "Chelsea", Strength: 80})
+	arsenal := createTeam(t, s, types.Team{Name: "Arsenal", Strength: 85})
+	match := saveFixture(t, s, types.Match{Week: 1, Team1ID: chelsea.ID, Team2ID: arsenal.ID})[0]
+
+	deduction, err := s.Sanction.CreateSanction(types.Sanction{TeamID: chelsea.ID, Type: types.SanctionPointsDeduction, Points: 3, Reason: "financial breach"})
+	if err != nil {
+		t.Fatalf("CreateSanction: %v", err)
+	}
+	if deduction.ID == 0 || deduction.Season != 1 || deduction.CreatedAt.IsZero() {
+		t.Fatalf("CreateSanction = %+v, want an id, the current season and a creation time", deduction)
+	}
+
+	// sanctions are filed under the season the league is in when they are created
+	league := getLeague(t, s)
+	league.Season = 2
+	if err := s.League.UpdateLeague(league); err != nil {
+		t.Fatalf("UpdateLeague: %v", err)
+	}
+
+	forfeit, err := s.Sanction.CreateSanction(types.Sanction{TeamID: arsenal.ID, Type: types.SanctionForfeit, MatchID: match.ID, Reason: "fielded an ineligible player"})
+	if err != nil {
+		t.Fatalf("CreateSanction: %v", err)
+	}
+	if _, err := s.Sanction.CreateSanction(types.Sanction{TeamID: chelsea.ID, Type: types.SanctionPointsDeduction, Points: 1, Reason: "fixture delay"}); err != nil {
+		t.Fatalf("CreateSanction: %v", err)
+	}
+
+	sanctions, err := s.Sanction.GetSanctions()
+	if err != nil {
+		t.Fatalf("GetSanctions: %v", err)
+	}
+	if len(sanctions) != 3 || sanctions[0].ID != deduction.ID || sanctions[1].ID != forfeit.ID {
+		t.Fatalf("GetSanctions = %+v, want all three in the order they were created", sanctions)
+	}
+	if sanctions[0].Season != 1 || sanctions[1].Season != 2 || sanctions[2].Season != 2 {
+		t.Errorf("GetSanctions seasons = %d, %d, %d, want 1, 2, 2", sanctions[0].Season, sanctions[1].Season, sanctions[2].Season)
+	}
+
+	// a sanction without a match is stored without one and read back as 0
+	if sanctions[0].MatchID != 0 || sanctions[1].MatchID != match.ID {
+		t.Errorf("GetSanctions match ids = %d, %d, want 0 and %d", sanctions[0].MatchID, sanctions[1].MatchID, match.ID)
+	}
+	if sanctions[0].Points != 3 || sanctions[0].Type != types.SanctionPointsDeduction || sanctions[0].Reason != "financial breach" {
+		t.Errorf("GetSanctions()[0] = %+v, want the points deduction", sanctions[0])
+	}
+
+	byTeam, err := s.Sanction.GetSanctionsByTeam(chelsea.ID)
+	if err != nil {
+		t.Fatalf("GetSanctionsByTeam: %v", err)
+	}
+	if len(byTeam) != 2 || byTeam[0].ID != deduction.ID || byTeam[1].Points != 1 {
+		t.Errorf("GetSanctionsByTeam = %+v, want both Chelsea sanctions in order", byTeam)
+	}
+
+	if none, err := s.Sanction.GetSanctionsByTeam(999); err != nil || none == nil || len(none) != 0 {
+		t.Errorf("GetSanctionsByTeam of an unknown team = %v, %v, want an empty list", none, err)
+	}
+
+	if _, err := s.Sanction.CreateSanction(types.Sanction{TeamID: 999, Type: types.SanctionPointsDeduction, Points: 3, Reason: "unknown"}); err == nil {
+		t.Error("CreateSanction for an unknown team succeeded, want an error")
+	}
+
+	// deleting a team deletes its sanctions
+	if err := s.League.ClearFixtures(); err != nil {
+		t.Fatalf("ClearFixtures: %v", err)
+	}
+	if err := s.Team.DeleteTeam(chelsea.ID); err != nil {
+		t.Fatalf("DeleteTeam: %v", err)
+	}
+	sanctions, err = s.Sanction.GetSanctions()
+	if err != nil {
+		t.Fatalf("GetSanctions: %v", err)
+	}
+	if len(sanctions) != 1 || sanctions[0].ID != forfeit.ID {
+		t.Errorf("GetSanctions after deleting Chelsea = %+v, want only the Arsenal forfeit", sanctions)
+	}
+}
+
+func testLeagueEvents(t *testing.T, s Stores) {
+	if events, err := s.Event.GetEvents(0); err != nil || events == nil || len(events) != 0 {
+		t.Fatalf("GetEvents of an empty log = %v, %v, want an empty list", events, err)
+	}
+
+	payloads := []types.FixtureGeneratedEvent{
+		{CurrentWeek: 1, TotalWeeks: 6, Seed: 42},
+		{CurrentWeek: 1, TotalWeeks: 6, Seed: 43},
+		{CurrentWeek: 2, TotalWeeks: 6, Seed: 44},
+	}
+	for _, payload := range payloads {
+		if err := s.Event.AppendEvent(types.EventFixtureGenerated, payload); err != nil {
+			t.Fatalf("AppendEvent: %v", err)
+		}
+	}
+
+	errAbort := errors.New("abort")
+	err := s.Transactor.WithinTransaction(func(tx types.DBTX) error {
+		if err := s.Event.WithTx(tx).AppendEvent(types.EventLeagueRestarted, struct{}{}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("WithinTransaction = %v, want %v", err, errAbort)
+	}
+
+	events, err := s.Event.GetEvents(0)
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	if len(events) != len(payloads) {
+		t.Fatalf("GetEvents returned %d events, want %d without the rolled back one", len(events), len(payloads))
+	}
+
+	for i, event := range events {
+		if i > 0 && event.ID <= events[i-1].ID {
+			t.Errorf("GetEvents ids = %d after %d, want ascending ids", event.ID, events[i-1].ID)
+		}
+		if event.Type != types.EventFixtureGenerated || event.CreatedAt.IsZero() {
+			t.Errorf("GetEvents()[%d] = %+v, want a fixture_generated event with a creation time", i, event)
+		}
+
+		var payload types.FixtureGeneratedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			t.Fatalf("decoding payload %s: %v", event.Payload, err)
+		}
+		if payload.Seed != payloads[i].Seed || payload.CurrentWeek != payloads[i].CurrentWeek {
+			t.Errorf("GetEvents()[%d] payload = %+v, want %+v", i, payload, payloads[i])
+		}
+	}
+
+	// the log is read up to and including toEventID
+	upTo, err := s.Event.GetEvents(events[1].ID)
+	if err != nil {
+		t.Fatalf("GetEvents(%d): %v", events[1].ID, err)
+	}
+	if len(upTo) != 2 || upTo[0].ID != events[0].ID || upTo[1].ID != events[1].ID {
+		t.Errorf("GetEvents(%d) = %+v, want the first two events", events[1].ID, upTo)
+	}
+}
+
+func createTeam(t *testing.T, s Stores, team types.Team) types.Team {
+	t.Helper()
+
+	created, err := s.Team.CreateTeam(team)
+	if err != nil {
+		t.Fatalf("CreateTeam(%s): %v", team.Name, err)
+	}
+	return *created
+}
+
+func getTeamByID(t *testing.T, s Stores, id int) types.Team {
+	t.Helper()
+
+	team, err := s.Team.GetTeamByID(id)
+	if err != nil {
+		t.Fatalf("GetTeamByID(%d): %v", id, err)
+	}
+	return *team
+}
+
+func getLeague(t *testing.T, s Stores) types.League {
+	t.Helper()
+
+	league, err := s.League.GetLeagueInfo()
+	if err != nil {
+		t.Fatalf("GetLeagueInfo: %v", err)
+	}
+	return league
+}
+
+func setCurrentWeek(t *testing.T, s Stores, week int) {
+	t.Helper()
+
+	league := getLeague(t, s)
+	league.CurrentWeek = week
+	if err := s.League.UpdateLeague(league); err != nil {
+		t.Fatalf("UpdateLeague: %v", err)
+	}
+}
+
+func saveFixture(t *testing.T, s Stores, matches ...types.Match) []types.Match {
+	t.Helper()
+
+	saved, err := s.Simulation.SaveFixture(matches)
+	if err != nil {
+		t.Fatalf("SaveFixture: %v", err)
+	}
+	if len(saved) != len(matches) {
+		t.Fatalf("SaveFixture returned %d matches, want %d", len(saved), len(matches))
+	}
+	return saved
+}
+
+func getMatch(t *testing.T, s Stores, id int) types.Match {
+	t.Helper()
+
+	match, err := s.League.GetMatchByID(id)
+	if err != nil {
+		t.Fatalf("GetMatchByID(%d): %v", id, err)
+	}
+	return *match
+}
+
+func getMatchEvents(t *testing.T, s Stores, matchID int) []types.MatchEvent {
+	t.Helper()
+
+	events, err := s.League.GetMatchEvents(matchID)
+	if err != nil {
+		t.Fatalf("GetMatchEvents(%d): %v", matchID, err)
+	}
+	return events
+}
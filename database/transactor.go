@@ -0,0 +1,51 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"football-simulation/types"
+)
+
+type Transactor struct {
+	db *sql.DB
+	// adapt wraps each transaction the way the stores' connection is wrapped.
+	adapt func(types.DBTX) types.DBTX
+}
+
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// NewSQLiteTransactor returns a Transactor whose transactions are adapted
+// with SQLite, for stores created on SQLite(db).
+func NewSQLiteTransactor(db *sql.DB) *Transactor {
+	return &Transactor{db: db, adapt: SQLite}
+}
+
+func (t *Transactor) WithinTransaction(fn func(tx types.DBTX) error) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	var dbtx types.DBTX = tx
+	if t.adapt != nil {
+		dbtx = t.adapt(tx)
+	}
+
+	if err := fn(dbtx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("%v (rollback failed: %v)", err, rollbackErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,303 @@
+package auth
+
+import (
+	"errors"
+	"football-simulation/database/memory"
+	"football-simulation/types"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+const basePath = "/api/v1"
+
+// newRouter returns a router with a few routes behind the middleware, and
+// keys for every role.
+func newRouter(t *testing.T, anonymousReads bool) (*mux.Router, map[string]string) {
+	t.Helper()
+
+	service := NewService(memory.NewAPIKeyStore(memory.NewDB()))
+	keys := make(map[string]string)
+	for _, role := range []string{types.RoleViewer, types.RoleOperator, types.RoleAdmin} {
+		key, _, err := service.CreateKey(role+" client", role)
+		if err != nil {
+			t.Fatalf("CreateKey(%s): %v", role, err)
+		}
+		keys[role] = key
+	}
+
+	revoked, apiKey, err := service.CreateKey("revoked client", types.RoleAdmin)
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+	if err := service.RevokeKey(apiKey.ID); err != nil {
+		t.Fatalf("RevokeKey: %v", err)
+	}
+	keys["revoked"] = revoked
+
+	router := mux.NewRouter()
+	subRouter := router.PathPrefix(basePath).Subrouter()
+	subRouter.Use(NewMiddleware(service, basePath, anonymousReads).Authorize)
+
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	subRouter.HandleFunc("/openapi.json", ok).Methods("GET")
+	subRouter.HandleFunc("/league", ok).Methods("GET")
+	subRouter.HandleFunc("/league/nextweek", ok).Methods("POST")
+	subRouter.HandleFunc("/league/match/{id}", ok).Methods("PUT")
+	subRouter.HandleFunc("/webhooks", ok).Methods("GET")
+
+	return router, keys
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name           string
+		anonymousReads bool
+		method, path   string
+		key            string
+		want           int
+	}{
+		{"public route without a key", false, "GET", "/openapi.json", "", http.StatusOK},
+		{"anonymous read allowed", true, "GET", "/league", "", http.StatusOK},
+		{"anonymous read disabled", false, "GET", "/league", "", http.StatusUnauthorized},
+		{"anonymous change", true, "POST", "/league/nextweek", "", http.StatusUnauthorized},
+		{"viewer reads", false, "GET", "/league", types.RoleViewer, http.StatusOK},
+		{"viewer plays a week", false, "POST", "/league/nextweek", types.RoleViewer, http.StatusForbidden},
+		{"operator plays a week", false, "POST", "/league/nextweek", types.RoleOperator, http.StatusOK},
+		{"operator edits a result", false, "PUT", "/league/match/7", types.RoleOperator, http.StatusForbidden},
+		{"admin edits a result", false, "PUT", "/league/match/7", types.RoleAdmin, http.StatusOK},
+		{"viewer reads webhooks", true, "GET", "/webhooks", types.RoleViewer, http.StatusForbidden},
+		{"operator reads webhooks", false, "GET", "/webhooks", types.RoleOperator, http.StatusForbidden},
+		{"anonymous reads webhooks", true, "GET", "/webhooks", "", http.StatusUnauthorized},
+		{"admin reads webhooks", false, "GET", "/webhooks", types.RoleAdmin, http.StatusOK},
+		{"unknown key", true, "GET", "/league", "unknown", http.StatusUnauthorized},
+		{"revoked key", true, "GET", "/league", "revoked", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, keys := newRouter(t, tt.anonymousReads)
+
+			req := httptest.NewRequest(tt.method, basePath+tt.path, nil)
+			switch tt.key {
+			case "":
+			case "unknown":
+				req.Header.Set("X-API-Key", "fsk_unknown")
+			default:
+				req.Header.Set("Authorization", "Bearer "+keys[tt.key])
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d: %s", tt.method, tt.path, rec.Code, tt.want, rec.Body.String())
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 response without a WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestAuthorizeSetsKey(t *testing.T) {
+	service := NewService(memory.NewAPIKeyStore(memory.NewDB()))
+	key, _, err := service.CreateKey("scoreboard", types.RoleAdmin)
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+
+	var got *types.APIKey
+	router := mux.NewRouter()
+	router.Use(NewMiddleware(service, "", true).Authorize)
+	router.HandleFunc("/league", func(w http.ResponseWriter, r *http.Request) {
+		got = KeyFromContext(r.Context())
+	}).Methods("GET")
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/league", nil))
+	if got != nil {
+		t.Errorf("KeyFromContext of an anonymous read = %+v, want nil", got)
+	}
+
+	req := httptest.NewRequest("GET", "/league", nil)
+	req.Header.Set("X-API-Key", key)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if got == nil || got.Name != "scoreboard" {
+		t.Errorf("KeyFromContext = %+v, want the scoreboard key", got)
+	}
+}
+
+func TestRequiredRole(t *testing.T) {
+	m := NewMiddleware(nil, basePath, true)
+
+	tests := []struct {
+		method, template string
+		want             string
+	}{
+		{"GET", "/openapi.json", ""},
+		{"GET", "/league/standings", types.RoleViewer},
+		{"HEAD", "/league", types.RoleViewer},
+		{"POST", "/league/nextweek", types.RoleOperator},
+		{"POST", "/league/playall", types.RoleOperator},
+		{"POST", "/league/playoffs/play", types.RoleOperator},
+		{"POST", "/jobs/{id}/cancel", types.RoleOperator},
+		{"PUT", "/league/match/{id}", types.RoleAdmin},
+		{"POST", "/league/restart", types.RoleAdmin},
+		{"DELETE", "/teams/{id}", types.RoleAdmin},
+		{"GET", "/webhooks", types.RoleAdmin},
+		{"GET", "/webhooks/{id}/deliveries", types.RoleAdmin},
+	}
+
+	for _, tt := range tests {
+		router := mux.NewRouter()
+		var got string
+		router.HandleFunc(basePath+tt.template, func(w http.ResponseWriter, r *http.Request) {
+			_, got = m.requiredRole(r)
+		}).Methods(tt.method)
+
+		// request a concrete path, so the role is looked up by route template
+		path := strings.ReplaceAll(basePath+tt.template, "{id}", "7")
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, path, nil))
+		if got != tt.want {
+			t.Errorf("requiredRole(%s %s) = %q, want %q", tt.method, tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		role, required string
+		want           bool
+	}{
+		{types.RoleViewer, types.RoleViewer, true},
+		{types.RoleViewer, types.RoleOperator, false},
+		{types.RoleOperator, types.RoleViewer, true},
+		{types.RoleOperator, types.RoleAdmin, false},
+		{types.RoleAdmin, types.RoleOperator, true},
+		{"", types.RoleViewer, false},
+		{"superuser", types.RoleViewer, false},
+	}
+
+	for _, tt := range tests {
+		if got := Allows(tt.role, tt.required); got != tt.want {
+			t.Errorf("Allows(%q, %q) = %v, want %v", tt.role, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	service := NewService(memory.NewAPIKeyStore(memory.NewDB()))
+
+	key, created, err := service.CreateKey("  scoreboard ", types.RoleOperator)
+	if err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+	if created.Name != "scoreboard" || len(key) <= len(keyPrefix) || key[:len(keyPrefix)] != keyPrefix {
+		t.Fatalf("CreateKey = %q, %+v, want a prefixed key named scoreboard", key, created)
+	}
+
+	apiKey, err := service.Authenticate(key)
+	if err != nil || apiKey.ID != created.ID || apiKey.Role != types.RoleOperator {
+		t.Fatalf("Authenticate = %+v, %v, want the scoreboard key", apiKey, err)
+	}
+
+	for _, unknown := range []string{"", "fsk_unknown", key + "x"} {
+		if _, err := service.Authenticate(unknown); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("Authenticate(%q) = %v, want %v", unknown, err, ErrUnauthorized)
+		}
+	}
+
+	if err := service.RevokeKey(created.ID); err != nil {
+		t.Fatalf("RevokeKey: %v", err)
+	}
+	if _, err := service.Authenticate(key); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Authenticate of a revoked key = %v, want %v", err, ErrUnauthorized)
+	}
+	if err := service.RevokeKey(created.ID + 1); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("RevokeKey of an unknown key = %v, want %v", err, ErrAPIKeyNotFound)
+	}
+}
+
+func TestCreateKeyValidation(t *testing.T) {
+	service := NewService(memory.NewAPIKeyStore(memory.NewDB()))
+	if _, _, err := service.CreateKey("scoreboard", types.RoleViewer); err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+
+	tests := []struct {
+		name, role string
+		want       error
+	}{
+		{" ", types.RoleViewer, ErrKeyNameBlank},
+		{"dashboard", "superuser", ErrInvalidRole},
+		{"Scoreboard", types.RoleAdmin, ErrKeyNameTaken},
+	}
+
+	for _, tt := range tests {
+		if _, _, err := service.CreateKey(tt.name, tt.role); !errors.Is(err, tt.want) {
+			t.Errorf("CreateKey(%q, %q) = %v, want %v", tt.name, tt.role, err, tt.want)
+		}
+	}
+}
+
+func TestEnsureKey(t *testing.T) {
+	service := NewService(memory.NewAPIKeyStore(memory.NewDB()))
+
+	const key = "fsk_configuredadminkey"
+	for i := 0; i < 2; i++ {
+		if err := service.EnsureKey("admin", key, types.RoleAdmin); err != nil {
+			t.Fatalf("EnsureKey call %d: %v", i+1, err)
+		}
+	}
+
+	keys, err := service.GetKeys()
+	if err != nil {
+		t.Fatalf("GetKeys: %v", err)
+	}
+	if len(keys) != 1 {
+		t.Fatalf("GetKeys = %+v, want the key stored once", keys)
+	}
+
+	apiKey, err := service.Authenticate(key)
+	if err != nil || apiKey.Name != "admin" || apiKey.Role != types.RoleAdmin {
+		t.Errorf("Authenticate of the ensured key = %+v, %v", apiKey, err)
+	}
+
+	// a changed key replaces the one stored under the name, even a revoked one
+	if err := service.RevokeKey(apiKey.ID); err != nil {
+		t.Fatalf("RevokeKey: %v", err)
+	}
+	const rotated = "fsk_rotatedadminkey"
+	if err := service.EnsureKey("admin", rotated, types.RoleAdmin); err != nil {
+		t.Fatalf("EnsureKey of a rotated key: %v", err)
+	}
+
+	if _, err := service.Authenticate(key); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Authenticate of the previous key = %v, want %v", err, ErrUnauthorized)
+	}
+	if got, err := service.Authenticate(rotated); err != nil || got.ID != apiKey.ID || got.Role != types.RoleAdmin {
+		t.Errorf("Authenticate of the rotated key = %+v, %v, want the admin entry", got, err)
+	}
+	if keys, err := service.GetKeys(); err != nil || len(keys) != 1 {
+		t.Errorf("GetKeys = %+v, %v, want the one admin entry", keys, err)
+	}
+
+	// a key already stored under another name is left alone
+	if _, _, err := service.CreateKey("scoreboard", types.RoleViewer); err != nil {
+		t.Fatalf("CreateKey: %v", err)
+	}
+	if err := service.EnsureKey("scoreboard", rotated, types.RoleAdmin); err != nil {
+		t.Fatalf("EnsureKey of a known key: %v", err)
+	}
+	if got, err := service.Authenticate(rotated); err != nil || got.ID != apiKey.ID {
+		t.Errorf("Authenticate of the rotated key = %+v, %v, want it unchanged", got, err)
+	}
+	if err := service.EnsureKey(" Admin ", "fsk_configuredadminkey", types.RoleAdmin); err != nil {
+		t.Fatalf("EnsureKey under a differently written name: %v", err)
+	}
+	if got, err := service.Authenticate(key); err != nil || got.ID != apiKey.ID {
+		t.Errorf("Authenticate = %+v, %v, want the admin entry, not a new one", got, err)
+	}
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"football-simulation/types"
+	"football-simulation/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// routeRoles lists the routes whose role differs from the default, by method
+// and path template relative to the API prefix. By default reads need
+// RoleViewer and every other change RoleAdmin, so a new route is locked down
+// until it is listed here. Webhooks are read by admins only, as their URLs
+// may carry credentials of the receiver.
+var routeRoles = map[string]string{
+	"POST /league/nextweek": types.RoleOperator,
+	"POST /league/playall":  types.RoleOperator,
+
+	"POST /league/playoffs/play": types.RoleOperator,
+
+	"POST /league/schedule/pause":  types.RoleOperator,
+	"POST /league/schedule/resume": types.RoleOperator,
+	"POST /jobs":                   types.RoleOperator,
+	"POST /jobs/{id}/cancel":       types.RoleOperator,
+
+	"GET /webhooks":                 types.RoleAdmin,
+	"GET /webhooks/{id}":            types.RoleAdmin,
+	"GET /webhooks/{id}/deliveries": types.RoleAdmin,
+}
+
+// publicRoutes can be used without a key.
+var publicRoutes = map[string]bool{
+	"GET /openapi.json": true,
+}
+
+type apiKeyContextKey struct{}
+
+// Middleware authenticates requests by API key and checks that the role of
+// the key allows the matched route.
+type Middleware struct {
+	service  types.AuthService
+	basePath string
+	// anonymousReads lets requests without a key use the viewer routes.
+	anonymousReads bool
+}
+
+func NewMiddleware(service types.AuthService, basePath string, anonymousReads bool) *Middleware {
+	return &Middleware{service: service, basePath: basePath, anonymousReads: anonymousReads}
+}
+
+func (m *Middleware) Authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, required := m.requiredRole(r)
+		if required == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := requestKey(r)
+		if key == "" && required == types.RoleViewer && m.anonymousReads {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		apiKey, err := m.service.Authenticate(key)
+		if err != nil {
+			if errors.Is(err, ErrUnauthorized) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="football-simulation"`)
+			}
+			utils.WriteServiceError(w, err)
+			return
+		}
+
+		if !Allows(apiKey.Role, required) {
+			utils.WriteServiceError(w, ErrForbidden.Errorf("%s requires the %s role, the key has the %s role", route, required, apiKey.Role))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), apiKeyContextKey{}, apiKey)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requiredRole returns the route of the request and the role it requires,
+// or an empty role for public routes.
+func (m *Middleware) requiredRole(r *http.Request) (string, string) {
+	template := r.URL.Path
+	if current := mux.CurrentRoute(r); current != nil {
+		if path, err := current.GetPathTemplate(); err == nil {
+			template = path
+		}
+	}
+
+	route := r.Method + " " + strings.TrimPrefix(template, m.basePath)
+	if publicRoutes[route] {
+		return route, ""
+	}
+
+	if role, ok := routeRoles[route]; ok {
+		return route, role
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return route, types.RoleViewer
+	}
+	return route, types.RoleAdmin
+}
+
+// KeyFromContext returns the API key that authenticated the request, or nil
+// for anonymous reads and public routes.
+func KeyFromContext(ctx context.Context) *types.APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*types.APIKey)
+	return key
+}
+
+// requestKey reads the key from the X-API-Key header or a bearer token.
+func requestKey(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get("X-API-Key")); key != "" {
+		return key
+	}
+
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if found && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"football-simulation/types"
+	"strings"
+)
+
+// keyPrefix marks the keys issued by this service, so they are easy to spot
+// in configuration files and logs.
+const keyPrefix = "fsk_"
+
+var (
+	ErrUnauthorized   = types.NewError(types.ErrorKindUnauthorized, "unauthorized", "a valid API key is required")
+	ErrForbidden      = types.NewError(types.ErrorKindForbidden, "forbidden", "the API key does not allow this request")
+	ErrInvalidRole    = types.NewError(types.ErrorKindValidation, "invalid_role", "the role must be viewer, operator or admin")
+	ErrKeyNameBlank   = types.NewError(types.ErrorKindValidation, "api_key_name_blank", "the API key name cannot be blank")
+	ErrKeyNameTaken   = types.NewError(types.ErrorKindConflict, "api_key_name_taken", "an API key with this name already exists")
+	ErrAPIKeyNotFound = types.NewError(types.ErrorKindNotFound, "api_key_not_found", "API key not found")
+)
+
+// roleRanks orders the roles; a role is allowed everything the roles below
+// it are allowed.
+var roleRanks = map[string]int{
+	types.RoleViewer:   1,
+	types.RoleOperator: 2,
+	types.RoleAdmin:    3,
+}
+
+type Service struct {
+	store types.APIKeyStore
+}
+
+func NewService(store types.APIKeyStore) *Service {
+	return &Service{store: store}
+}
+
+// CreateKey issues a new key for role. The returned key is not stored and
+// cannot be recovered later.
+func (s *Service) CreateKey(name, role string) (string, *types.APIKey, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+
+	key := keyPrefix + hex.EncodeToString(secret)
+	apiKey, err := s.createKey(name, key, role)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, apiKey, nil
+}
+
+// EnsureKey stores key under name unless it is already known, so a key from
+// the configuration can be used on a fresh database. When name holds another
+// key, that key is replaced: a changed configuration stops the previous key
+// from working.
+func (s *Service) EnsureKey(name, key, role string) error {
+	existing, err := s.store.GetAPIKeyByHash(hashKey(key))
+	if err != nil {
+		return err
+	}
+
+	if existing.ID != 0 {
+		return nil
+	}
+
+	keys, err := s.store.GetAPIKeys()
+	if err != nil {
+		return err
+	}
+
+	for _, stored := range keys {
+		if strings.EqualFold(stored.Name, strings.TrimSpace(name)) {
+			return s.store.ReplaceAPIKeyHash(stored.ID, hashKey(key))
+		}
+	}
+
+	_, err = s.createKey(name, key, role)
+	return err
+}
+
+func (s *Service) createKey(name, key, role string) (*types.APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrKeyNameBlank
+	}
+
+	if _, ok := roleRanks[role]; !ok {
+		return nil, ErrInvalidRole
+	}
+
+	keys, err := s.store.GetAPIKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, existing := range keys {
+		if strings.EqualFold(existing.Name, name) {
+			return nil, ErrKeyNameTaken
+		}
+	}
+
+	return s.store.CreateAPIKey(types.APIKey{Name: name, Role: role}, hashKey(key))
+}
+
+// Authenticate returns the API key matching key, or ErrUnauthorized when the
+// key is unknown or has been revoked.
+func (s *Service) Authenticate(key string) (*types.APIKey, error) {
+	if key == "" {
+		return nil, ErrUnauthorized
+	}
+
+	apiKey, err := s.store.GetAPIKeyByHash(hashKey(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if apiKey.ID == 0 || apiKey.RevokedAt != nil {
+		return nil, ErrUnauthorized
+	}
+
+	return apiKey, nil
+}
+
+func (s *Service) GetKeys() ([]types.APIKey, error) {
+	return s.store.GetAPIKeys()
+}
+
+func (s *Service) RevokeKey(id int) error {
+	keys, err := s.store.GetAPIKeys()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if key.ID == id {
+			return s.store.RevokeAPIKey(id)
+		}
+	}
+
+	return ErrAPIKeyNotFound.Errorf("API key %d not found", id)
+}
+
+// Allows reports whether role may use routes that require the required role.
+func Allows(role, required string) bool {
+	return roleRanks[role] >= roleRanks[required]
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"database/sql"
+	"football-simulation/types"
+)
+
+type Store struct {
+	db types.DBTX
+}
+
+func NewStore(db types.DBTX) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) WithTx(tx types.DBTX) types.APIKeyStore {
+	return &Store{db: tx}
+}
+
+func (s *Store) CreateAPIKey(key types.APIKey, hash string) (*types.APIKey, error) {
+	err := s.db.QueryRow("INSERT INTO api_keys (name, key_hash, role) VALUES ($1, $2, $3) RETURNING id, created_at",
+		key.Name, hash, key.Role).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (s *Store) GetAPIKeyByHash(hash string) (*types.APIKey, error) {
+	rows, err := s.db.Query("SELECT id, name, role, created_at, revoked_at FROM api_keys WHERE key_hash = $1", hash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys, err := scanRowsIntoAPIKeys(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return &types.APIKey{}, nil
+	}
+	return &keys[0], nil
+}
+
+func (s *Store) GetAPIKeys() ([]types.APIKey, error) {
+	rows, err := s.db.Query("SELECT id, name, role, created_at, revoked_at FROM api_keys ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRowsIntoAPIKeys(rows)
+}
+
+func (s *Store) RevokeAPIKey(id int) error {
+	_, err := s.db.Exec("UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL", id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReplaceAPIKeyHash makes hash the only key of the API key id, and makes the
+// key active again if it was revoked.
+func (s *Store) ReplaceAPIKeyHash(id int, hash string) error {
+	_, err := s.db.Exec("UPDATE api_keys SET key_hash = $1, revoked_at = NULL WHERE id = $2", hash, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func scanRowsIntoAPIKeys(rows *sql.Rows) ([]types.APIKey, error) {
+	keys := make([]types.APIKey, 0)
+
+	for rows.Next() {
+		var key types.APIKey
+		var revokedAt sql.NullTime
+		err := rows.Scan(
+			&key.ID,
+			&key.Name,
+			&key.Role,
+			&key.CreatedAt,
+			&revokedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if revokedAt.Valid {
+			key.RevokedAt = &revokedAt.Time
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
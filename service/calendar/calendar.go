@@ -0,0 +1,259 @@
+package calendar
+
+import (
+	"football-simulation/types"
+	"sort"
+	"strings"
+	"time"
+	// bundled so timezones resolve on hosts without a zoneinfo database
+	_ "time/tzdata"
+)
+
+const (
+	dateLayout  = "2006-01-02"
+	clockLayout = "15:04"
+	// searchDays bounds the search for a free matchday, so a calendar
+	// blacked out for good fails instead of looping.
+	searchDays = 366
+)
+
+var defaultMidweekMatchdays = []string{"wednesday"}
+var defaultMidweekKickoffTimes = []string{"19:45"}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// plan is a parsed calendar configuration.
+type plan struct {
+	loc           *time.Location
+	start         time.Time
+	matchdays     roundDays
+	midweekRounds map[int]bool
+	midweek       roundDays
+	blackouts     []dateRange
+}
+
+// roundDays are the weekdays a kind of round is played on and the kick-off
+// times of each of those days, in order.
+type roundDays struct {
+	weekdays [7]bool
+	kickoffs []time.Duration
+}
+
+type dateRange struct {
+	from, to time.Time
+}
+
+func newPlan(config types.CalendarConfig) (*plan, error) {
+	loc := time.UTC
+	if config.Timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(config.Timezone)
+		if err != nil {
+			return nil, ErrInvalidCalendar.Errorf("unknown timezone %q", config.Timezone)
+		}
+	}
+
+	start, err := time.ParseInLocation(dateLayout, config.StartDate, loc)
+	if err != nil {
+		return nil, ErrInvalidCalendar.Errorf("invalid start date %q, expected YYYY-MM-DD", config.StartDate)
+	}
+
+	p := &plan{loc: loc, start: start, midweekRounds: make(map[int]bool)}
+
+	p.matchdays, err = parseRoundDays(config.Matchdays, config.KickoffTimes)
+	if err != nil {
+		return nil, err
+	}
+
+	midweekMatchdays, midweekKickoffTimes := config.MidweekMatchdays, config.MidweekKickoffTimes
+	if len(midweekMatchdays) == 0 {
+		midweekMatchdays = defaultMidweekMatchdays
+	}
+	if len(midweekKickoffTimes) == 0 {
+		midweekKickoffTimes = defaultMidweekKickoffTimes
+	}
+	p.midweek, err = parseRoundDays(midweekMatchdays, midweekKickoffTimes)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, round := range config.MidweekRounds {
+		if round < 1 {
+			return nil, ErrInvalidCalendar.Errorf("invalid midweek round %d, rounds start at 1", round)
+		}
+		p.midweekRounds[round] = true
+	}
+
+	for _, blackout := range config.Blackouts {
+		from, err := time.ParseInLocation(dateLayout, blackout.From, loc)
+		if err != nil {
+			return nil, ErrInvalidCalendar.Errorf("invalid blackout date %q, expected YYYY-MM-DD", blackout.From)
+		}
+		to, err := time.ParseInLocation(dateLayout, blackout.To, loc)
+		if err != nil {
+			return nil, ErrInvalidCalendar.Errorf("invalid blackout date %q, expected YYYY-MM-DD", blackout.To)
+		}
+		if to.Before(from) {
+			return nil, ErrInvalidCalendar.Errorf("the blackout from %s to %s ends before it starts", blackout.From, blackout.To)
+		}
+		p.blackouts = append(p.blackouts, dateRange{from: from, to: to})
+	}
+
+	return p, nil
+}
+
+func parseRoundDays(days, kickoffTimes []string) (roundDays, error) {
+	var rd roundDays
+	if len(days) == 0 || len(kickoffTimes) == 0 {
+		return rd, ErrInvalidCalendar.Errorf("the calendar needs at least one matchday and kick-off time")
+	}
+
+	for _, day := range days {
+		weekday, ok := weekdays[strings.ToLower(day)]
+		if !ok {
+			return rd, ErrInvalidCalendar.Errorf("unknown matchday %q", day)
+		}
+		rd.weekdays[weekday] = true
+	}
+
+	for _, kickoffTime := range kickoffTimes {
+		clock, err := time.Parse(clockLayout, kickoffTime)
+		if err != nil {
+			return rd, ErrInvalidCalendar.Errorf("invalid kick-off time %q, expected HH:MM", kickoffTime)
+		}
+		rd.kickoffs = append(rd.kickoffs, time.Duration(clock.Hour())*time.Hour+time.Duration(clock.Minute())*time.Minute)
+	}
+	sort.Slice(rd.kickoffs, func(i, j int) bool { return rd.kickoffs[i] < rd.kickoffs[j] })
+
+	return rd, nil
+}
+
+// kickoffs returns the kick-off time of every match, by match id. The rounds
+// are the weeks of the matches and are played in order, each one after the
+// last day of the previous one. The matches of a round are spread evenly
+// over its kick-off slots, in match id order.
+func (p *plan) kickoffs(matches []types.Match) (map[int]time.Time, error) {
+	rounds := make(map[int][]types.Match)
+	var weeks []int
+	for _, match := range matches {
+		if _, ok := rounds[match.Week]; !ok {
+			weeks = append(weeks, match.Week)
+		}
+		rounds[match.Week] = append(rounds[match.Week], match)
+	}
+	sort.Ints(weeks)
+
+	kickoffs := make(map[int]time.Time, len(matches))
+	cursor := p.start
+
+	for _, week := range weeks {
+		round := rounds[week]
+		sort.Slice(round, func(i, j int) bool { return round[i].ID < round[j].ID })
+
+		rd := p.matchdays
+		if p.midweekRounds[week] {
+			rd = p.midweek
+		}
+
+		days, ok := p.roundDays(cursor, rd)
+		if !ok {
+			return nil, ErrInvalidCalendar.Errorf("week %d has no matchday within a year of %s that is not blacked out", week, cursor.Format(dateLayout))
+		}
+
+		var slots []time.Time
+		for _, day := range days {
+			for _, kickoff := range rd.kickoffs {
+				// the wall clock time of the day, which is not midnight plus
+				// the kick-off on the days the clocks change
+				hour, minute := int(kickoff/time.Hour), int(kickoff%time.Hour/time.Minute)
+				slots = append(slots, time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, p.loc))
+			}
+		}
+
+		for i, match := range round {
+			kickoffs[match.ID] = slots[i*len(slots)/len(round)].UTC()
+		}
+
+		cursor = days[len(days)-1].AddDate(0, 0, 1)
+	}
+
+	return kickoffs, nil
+}
+
+// roundDays returns the first matchday of rd on or after cursor that is not
+// blacked out, followed by the matchdays directly after it, so a Saturday and
+// Sunday round takes the whole weekend.
+func (p *plan) roundDays(cursor time.Time, rd roundDays) ([]time.Time, bool) {
+	day := cursor
+	for i := 0; !p.playable(day, rd); i++ {
+		if i == searchDays {
+			return nil, false
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	days := []time.Time{day}
+	for next := day.AddDate(0, 0, 1); len(days) < 7 && p.playable(next, rd); next = next.AddDate(0, 0, 1) {
+		days = append(days, next)
+	}
+	return days, true
+}
+
+func (p *plan) playable(day time.Time, rd roundDays) bool {
+	if !rd.weekdays[day.Weekday()] {
+		return false
+	}
+
+	for _, blackout := range p.blackouts {
+		if !day.Before(blackout.from) && !day.After(blackout.to) {
+			return false
+		}
+	}
+	return true
+}
+
+// fixtureRange returns the kick-off range [from, to) of filter in loc,
+// taking today from now.
+func fixtureRange(filter types.FixtureFilter, now time.Time, loc *time.Location) (time.Time, time.Time) {
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	switch filter.Period {
+	case types.PeriodToday:
+		return today, today.AddDate(0, 0, 1)
+	case types.PeriodWeekend:
+		// Friday to Sunday, the current weekend from Friday on
+		var friday time.Time
+		switch today.Weekday() {
+		case time.Saturday:
+			friday = today.AddDate(0, 0, -1)
+		case time.Sunday:
+			friday = today.AddDate(0, 0, -2)
+		default:
+			friday = today.AddDate(0, 0, (int(time.Friday)-int(today.Weekday())+7)%7)
+		}
+		return friday, friday.AddDate(0, 0, 3)
+	case types.PeriodWeek:
+		return today, today.AddDate(0, 0, 7)
+	}
+
+	from := today
+	if !filter.From.IsZero() {
+		from = time.Date(filter.From.Year(), filter.From.Month(), filter.From.Day(), 0, 0, 0, 0, loc)
+	}
+
+	to := from.AddDate(0, 0, 7)
+	if !filter.To.IsZero() {
+		to = time.Date(filter.To.Year(), filter.To.Month(), filter.To.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+	}
+
+	return from, to
+}
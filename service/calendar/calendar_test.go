@@ -0,0 +1,204 @@
+package calendar
+
+import (
+	"errors"
+	"football-simulation/types"
+	"testing"
+	"time"
+)
+
+func TestKickoffs(t *testing.T) {
+	p, err := newPlan(types.CalendarConfig{
+		// a Saturday
+		StartDate:     "2024-08-10",
+		Timezone:      "Europe/London",
+		Matchdays:     []string{"saturday", "sunday"},
+		KickoffTimes:  []string{"15:00", "12:30"},
+		MidweekRounds: []int{3},
+		Blackouts:     []types.Blackout{{From: "2024-08-31", To: "2024-09-08", Reason: "international break"}},
+	})
+	if err != nil {
+		t.Fatalf("newPlan: %v", err)
+	}
+
+	var matches []types.Match
+	for week := 1; week <= 6; week++ {
+		matches = append(matches,
+			types.Match{ID: 2*week - 1, Week: week},
+			types.Match{ID: 2 * week, Week: week},
+		)
+	}
+
+	kickoffs, err := p.kickoffs(matches)
+	if err != nil {
+		t.Fatalf("kickoffs: %v", err)
+	}
+
+	// London is on UTC+1 in summer; two matches take every other slot of
+	// the weekend
+	want := map[int]time.Time{
+		1:  time.Date(2024, time.August, 10, 11, 30, 0, 0, time.UTC),
+		2:  time.Date(2024, time.August, 11, 11, 30, 0, 0, time.UTC),
+		3:  time.Date(2024, time.August, 17, 11, 30, 0, 0, time.UTC),
+		4:  time.Date(2024, time.August, 18, 11, 30, 0, 0, time.UTC),
+		5:  time.Date(2024, time.August, 21, 18, 45, 0, 0, time.UTC),
+		6:  time.Date(2024, time.August, 21, 18, 45, 0, 0, time.UTC),
+		7:  time.Date(2024, time.August, 24, 11, 30, 0, 0, time.UTC),
+		8:  time.Date(2024, time.August, 25, 11, 30, 0, 0, time.UTC),
+		9:  time.Date(2024, time.September, 14, 11, 30, 0, 0, time.UTC),
+		10: time.Date(2024, time.September, 15, 11, 30, 0, 0, time.UTC),
+		11: time.Date(2024, time.September, 21, 11, 30, 0, 0, time.UTC),
+		12: time.Date(2024, time.September, 22, 11, 30, 0, 0, time.UTC),
+	}
+
+	for id, kickoff := range want {
+		if got := kickoffs[id]; !got.Equal(kickoff) {
+			t.Errorf("match %d kicks off at %v, want %v", id, got, kickoff)
+		}
+	}
+}
+
+func TestKickoffsOnClockChanges(t *testing.T) {
+	p, err := newPlan(types.CalendarConfig{
+		// the clocks go forward on Sunday 29 March 2026 and back on Sunday
+		// 25 October 2026
+		StartDate:    "2026-03-29",
+		Timezone:     "Europe/London",
+		Matchdays:    []string{"sunday"},
+		KickoffTimes: []string{"15:00"},
+	})
+	if err != nil {
+		t.Fatalf("newPlan: %v", err)
+	}
+
+	var matches []types.Match
+	for week := 1; week <= 31; week++ {
+		matches = append(matches, types.Match{ID: week, Week: week})
+	}
+
+	kickoffs, err := p.kickoffs(matches)
+	if err != nil {
+		t.Fatalf("kickoffs: %v", err)
+	}
+
+	want := map[int]time.Time{
+		// 15:00 BST on the day the clocks go forward
+		1: time.Date(2026, time.March, 29, 14, 0, 0, 0, time.UTC),
+		2: time.Date(2026, time.April, 5, 14, 0, 0, 0, time.UTC),
+		// 15:00 GMT on the day the clocks go back
+		31: time.Date(2026, time.October, 25, 15, 0, 0, 0, time.UTC),
+	}
+
+	for id, kickoff := range want {
+		if got := kickoffs[id]; !got.Equal(kickoff) {
+			t.Errorf("match %d kicks off at %v, want %v", id, got, kickoff)
+		}
+	}
+
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	for id, kickoff := range kickoffs {
+		if local := kickoff.In(london); local.Hour() != 15 || local.Minute() != 0 {
+			t.Errorf("match %d kicks off at %s London time, want 15:00", id, local.Format("2006-01-02 15:04"))
+		}
+	}
+}
+
+func TestKickoffsSpreadOverSlots(t *testing.T) {
+	p, err := newPlan(types.CalendarConfig{
+		StartDate:    "2024-08-10",
+		Matchdays:    []string{"saturday"},
+		KickoffTimes: []string{"12:30", "15:00", "17:30"},
+	})
+	if err != nil {
+		t.Fatalf("newPlan: %v", err)
+	}
+
+	var matches []types.Match
+	for id := 1; id <= 5; id++ {
+		matches = append(matches, types.Match{ID: id, Week: 1})
+	}
+
+	kickoffs, err := p.kickoffs(matches)
+	if err != nil {
+		t.Fatalf("kickoffs: %v", err)
+	}
+
+	hours := []int{12, 12, 15, 15, 17}
+	for i, hour := range hours {
+		if got := kickoffs[i+1].Hour(); got != hour {
+			t.Errorf("match %d kicks off at %d:xx, want %d:xx", i+1, got, hour)
+		}
+	}
+}
+
+func TestKickoffsBlackedOut(t *testing.T) {
+	p, err := newPlan(types.CalendarConfig{
+		StartDate:    "2024-08-10",
+		Matchdays:    []string{"saturday"},
+		KickoffTimes: []string{"15:00"},
+		Blackouts:    []types.Blackout{{From: "2024-08-01", To: "2026-01-01"}},
+	})
+	if err != nil {
+		t.Fatalf("newPlan: %v", err)
+	}
+
+	_, err = p.kickoffs([]types.Match{{ID: 1, Week: 1}})
+	if !errors.Is(err, ErrInvalidCalendar) {
+		t.Errorf("kickoffs = %v, want ErrInvalidCalendar", err)
+	}
+}
+
+func TestNewPlanRejectsInvalidCalendars(t *testing.T) {
+	valid := types.CalendarConfig{StartDate: "2024-08-10", Matchdays: []string{"saturday"}, KickoffTimes: []string{"15:00"}}
+
+	tests := map[string]func(config *types.CalendarConfig){
+		"timezone":      func(config *types.CalendarConfig) { config.Timezone = "Mars/Olympus" },
+		"start date":    func(config *types.CalendarConfig) { config.StartDate = "10/08/2024" },
+		"matchday":      func(config *types.CalendarConfig) { config.Matchdays = []string{"caturday"} },
+		"kick-off time": func(config *types.CalendarConfig) { config.KickoffTimes = []string{"25:00"} },
+		"blackout": func(config *types.CalendarConfig) {
+			config.Blackouts = []types.Blackout{{From: "2024-09-10", To: "2024-09-01"}}
+		},
+	}
+
+	for name, change := range tests {
+		config := valid
+		change(&config)
+		if _, err := newPlan(config); !errors.Is(err, ErrInvalidCalendar) {
+			t.Errorf("%s: newPlan = %v, want ErrInvalidCalendar", name, err)
+		}
+	}
+}
+
+func TestFixtureRange(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	// a Wednesday
+	wednesday := time.Date(2024, time.August, 21, 18, 0, 0, 0, time.UTC)
+	sunday := time.Date(2024, time.August, 25, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		filter   types.FixtureFilter
+		now      time.Time
+		from, to time.Time
+	}{
+		{"today", types.FixtureFilter{Period: types.PeriodToday}, wednesday, day(time.August, 21), day(time.August, 22)},
+		{"coming weekend", types.FixtureFilter{Period: types.PeriodWeekend}, wednesday, day(time.August, 23), day(time.August, 26)},
+		{"current weekend", types.FixtureFilter{Period: types.PeriodWeekend}, sunday, day(time.August, 23), day(time.August, 26)},
+		{"week", types.FixtureFilter{Period: types.PeriodWeek}, wednesday, day(time.August, 21), day(time.August, 28)},
+		{"no filter", types.FixtureFilter{}, wednesday, day(time.August, 21), day(time.August, 28)},
+		{"dates", types.FixtureFilter{From: day(time.September, 1), To: day(time.September, 3)}, wednesday, day(time.September, 1), day(time.September, 4)},
+	}
+
+	for _, test := range tests {
+		from, to := fixtureRange(test.filter, test.now, time.UTC)
+		if !from.Equal(test.from) || !to.Equal(test.to) {
+			t.Errorf("%s: range = %v to %v, want %v to %v", test.name, from, to, test.from, test.to)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package calendar
+
+import (
+	"fmt"
+	"football-simulation/types"
+	"football-simulation/utils"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	service types.CalendarService
+}
+
+func NewHandler(service types.CalendarService) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/league/calendar", h.handleGetCalendar).Methods("GET")
+	router.HandleFunc("/league/calendar", h.handleSetCalendar).Methods("PUT")
+	router.HandleFunc("/league/calendar", h.handleDeleteCalendar).Methods("DELETE")
+	router.HandleFunc("/league/fixtures", h.handleGetFixtures).Methods("GET")
+}
+
+func (h *Handler) handleGetCalendar(w http.ResponseWriter, r *http.Request) {
+	calendar, err := h.service.GetCalendar()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, calendar)
+}
+
+func (h *Handler) handleSetCalendar(w http.ResponseWriter, r *http.Request) {
+	var req types.CalendarConfig
+	if !utils.ParseAndValidate(w, r, &req) {
+		return
+	}
+
+	calendar, err := h.service.SetCalendar(req)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, calendar)
+}
+
+func (h *Handler) handleDeleteCalendar(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteCalendar(); err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, nil)
+}
+
+func (h *Handler) handleGetFixtures(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFixtureFilter(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	fixtures, err := h.service.GetFixtures(filter)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, fixtures)
+}
+
+// parseFixtureFilter reads either a period or a from and to date. Without
+// any of them the filter covers the seven days from today.
+func parseFixtureFilter(r *http.Request) (types.FixtureFilter, error) {
+	var filter types.FixtureFilter
+	query := r.URL.Query()
+
+	filter.Period = query.Get("period")
+	switch filter.Period {
+	case "", types.PeriodToday, types.PeriodWeekend, types.PeriodWeek:
+	default:
+		return filter, fmt.Errorf("invalid period %q, expected today, weekend or week", filter.Period)
+	}
+
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+
+		if filter.Period != "" {
+			return filter, fmt.Errorf("period cannot be combined with from and to")
+		}
+
+		parsed, err := time.Parse(dateLayout, value)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s %q, expected YYYY-MM-DD", name, value)
+		}
+		*target = parsed
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.From.After(filter.To) {
+		return filter, fmt.Errorf("from must not be after to")
+	}
+
+	return filter, nil
+}
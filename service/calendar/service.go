@@ -0,0 +1,227 @@
+package calendar
+
+import (
+	"football-simulation/types"
+	"time"
+)
+
+var (
+	ErrCalendarNotFound = types.NewError(types.ErrorKindNotFound, "calendar_not_found", "the league has no calendar")
+	ErrInvalidCalendar  = types.NewError(types.ErrorKindValidation, "invalid_calendar", "the calendar is invalid")
+)
+
+type Service struct {
+	store       types.CalendarStore
+	leagueStore types.LeagueStore
+	teamStore   types.Teamstore
+	transactor  types.Transactor
+}
+
+func NewService(store types.CalendarStore, leagueStore types.LeagueStore, teamStore types.Teamstore, transactor types.Transactor) *Service {
+	return &Service{
+		store:       store,
+		leagueStore: leagueStore,
+		teamStore:   teamStore,
+		transactor:  transactor,
+	}
+}
+
+func (s *Service) WithTx(tx types.DBTX) types.CalendarService {
+	return s.withTx(tx)
+}
+
+func (s *Service) withTx(tx types.DBTX) *Service {
+	return &Service{
+		store:       s.store.WithTx(tx),
+		leagueStore: s.leagueStore.WithTx(tx),
+		teamStore:   s.teamStore.WithTx(tx),
+		transactor:  s.transactor,
+	}
+}
+
+func (s *Service) GetCalendar() (*types.LeagueCalendar, error) {
+	league, err := s.leagueStore.GetLeagueInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	calendar, err := s.store.GetCalendar(league.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if calendar.LeagueID == 0 {
+		return nil, ErrCalendarNotFound
+	}
+	return calendar, nil
+}
+
+// SetCalendar replaces the calendar of the league and moves the matches that
+// are still to be played to their dates in it.
+func (s *Service) SetCalendar(config types.CalendarConfig) (*types.LeagueCalendar, error) {
+	p, err := newPlan(config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.transactor.WithinTransaction(func(tx types.DBTX) error {
+		txService := s.withTx(tx)
+
+		league, err := txService.leagueStore.GetLeagueInfo()
+		if err != nil {
+			return err
+		}
+
+		err = txService.store.SaveCalendar(types.LeagueCalendar{LeagueID: league.ID, CalendarConfig: config})
+		if err != nil {
+			return err
+		}
+
+		matches, err := txService.leagueStore.GetAllMatches()
+		if err != nil {
+			return err
+		}
+
+		return txService.schedule(p, matches)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCalendar()
+}
+
+// DeleteCalendar removes the calendar of the league and the kick-off times
+// of the matches that are still to be played.
+func (s *Service) DeleteCalendar() error {
+	calendar, err := s.GetCalendar()
+	if err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTransaction(func(tx types.DBTX) error {
+		txService := s.withTx(tx)
+
+		if err := txService.store.DeleteCalendar(calendar.LeagueID); err != nil {
+			return err
+		}
+
+		matches, err := txService.leagueStore.GetAllMatches()
+		if err != nil {
+			return err
+		}
+
+		for _, match := range matches {
+			if !match.Played && match.KickoffAt != nil {
+				if err := txService.store.SetKickoff(match.ID, nil); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// ScheduleFixture sets the kick-off times of a newly generated fixture. It
+// does nothing when the league has no calendar. matches must be the whole
+// fixture: the dates are handed out week by week from the start date, so a
+// partial fixture would start over at the first one.
+func (s *Service) ScheduleFixture(matches []types.Match) error {
+	league, err := s.leagueStore.GetLeagueInfo()
+	if err != nil {
+		return err
+	}
+
+	calendar, err := s.store.GetCalendar(league.ID)
+	if err != nil {
+		return err
+	}
+
+	if calendar.LeagueID == 0 {
+		return nil
+	}
+
+	p, err := newPlan(calendar.CalendarConfig)
+	if err != nil {
+		return err
+	}
+
+	return s.schedule(p, matches)
+}
+
+// schedule sets the kick-off time of every match of the fixture that is still
+// to be played. Played matches keep the time they were played at, unless
+// they have none.
+func (s *Service) schedule(p *plan, matches []types.Match) error {
+	kickoffs, err := p.kickoffs(matches)
+	if err != nil {
+		return err
+	}
+
+	for _, match := range matches {
+		if match.Played && match.KickoffAt != nil {
+			continue
+		}
+
+		kickoff := kickoffs[match.ID]
+		if err := s.store.SetKickoff(match.ID, &kickoff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetFixtures returns the matches kicking off in the range of filter, in
+// kick-off order. Matches without a kick-off time are never included.
+func (s *Service) GetFixtures(filter types.FixtureFilter) ([]types.MatchResult, error) {
+	loc := time.UTC
+
+	league, err := s.leagueStore.GetLeagueInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	calendar, err := s.store.GetCalendar(league.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if calendar.LeagueID != 0 && calendar.Timezone != "" {
+		if loc, err = time.LoadLocation(calendar.Timezone); err != nil {
+			return nil, err
+		}
+	}
+
+	from, to := fixtureRange(filter, time.Now(), loc)
+	matches, err := s.store.GetMatchesBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := s.teamStore.GetTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	teamNames := make(map[int]string, len(teams))
+	for _, team := range teams {
+		teamNames[team.ID] = team.Name
+	}
+
+	fixtures := make([]types.MatchResult, 0, len(matches))
+	for _, match := range matches {
+		fixtures = append(fixtures, types.MatchResult{
+			ID:         match.ID,
+			Week:       match.Week,
+			Team1Name:  teamNames[match.Team1ID],
+			Team2Name:  teamNames[match.Team2ID],
+			Team1Score: match.Team1Score,
+			Team2Score: match.Team2Score,
+			Played:     match.Played,
+			Version:    match.Version,
+			KickoffAt:  match.KickoffAt,
+		})
+	}
+
+	return fixtures, nil
+}
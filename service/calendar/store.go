@@ -0,0 +1,122 @@
+package calendar
+
+import (
+	"database/sql"
+	"encoding/json"
+	"football-simulation/types"
+	"time"
+)
+
+type Store struct {
+	db types.DBTX
+}
+
+func NewStore(db types.DBTX) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) WithTx(tx types.DBTX) types.CalendarStore {
+	return &Store{db: tx}
+}
+
+// GetCalendar returns the calendar of the league, or an empty calendar when
+// none has been set.
+func (s *Store) GetCalendar(leagueID int) (*types.LeagueCalendar, error) {
+	rows, err := s.db.Query("SELECT league_id, config, updated_at FROM league_calendar WHERE league_id = $1", leagueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	calendar := &types.LeagueCalendar{}
+	for rows.Next() {
+		var config []byte
+		if err := rows.Scan(&calendar.LeagueID, &config, &calendar.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(config, &calendar.CalendarConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return calendar, nil
+}
+
+// SaveCalendar creates or replaces the calendar of calendar.LeagueID.
+func (s *Store) SaveCalendar(calendar types.LeagueCalendar) error {
+	config, err := json.Marshal(calendar.CalendarConfig)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`INSERT INTO league_calendar (league_id, config, updated_at) VALUES ($1, $2, $3)
+		ON CONFLICT (league_id) DO UPDATE SET config = $2, updated_at = $3`,
+		calendar.LeagueID, config, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) DeleteCalendar(leagueID int) error {
+	_, err := s.db.Exec("DELETE FROM league_calendar WHERE league_id = $1", leagueID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetMatchesBetween returns the matches kicking off from from up to, but not
+// including, to, in kick-off order.
+func (s *Store) GetMatchesBetween(from, to time.Time) ([]types.Match, error) {
+	rows, err := s.db.Query(`SELECT id, week, team1_id, team2_id, team1_score, team2_score, played, version, kickoff_at
+		FROM matches WHERE kickoff_at >= $1 AND kickoff_at < $2 ORDER BY kickoff_at, id`, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]types.Match, 0)
+	for rows.Next() {
+		var match types.Match
+		var kickoffAt sql.NullTime
+		err := rows.Scan(
+			&match.ID,
+			&match.Week,
+			&match.Team1ID,
+			&match.Team2ID,
+			&match.Team1Score,
+			&match.Team2Score,
+			&match.Played,
+			&match.Version,
+			&kickoffAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if kickoffAt.Valid {
+			match.KickoffAt = &kickoffAt.Time
+		}
+		matches = append(matches, match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+func (s *Store) SetKickoff(matchID int, kickoffAt *time.Time) error {
+	_, err := s.db.Exec("UPDATE matches SET kickoff_at = $1 WHERE id = $2", kickoffAt, matchID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"football-simulation/types"
+	"sort"
+)
+
+// projection folds league events into the league row, the team statistics and
+// the fixture, mirroring what the league and team services write to the store.
+type projection struct {
+	league  types.League
+	teams   map[int]*types.Team
+	order   []int
+	matches []types.Match
+}
+
+func newProjection(league types.League, teams []types.Team) *projection {
+	p := &projection{
+		league: types.League{
+			ID:     league.ID,
+			Name:   league.Name,
+			Season: 1,
+		},
+		teams: make(map[int]*types.Team),
+	}
+
+	for _, team := range teams {
+		p.teams[team.ID] = &types.Team{ID: team.ID, Name: team.Name, Strength: team.Strength, Metadata: team.Metadata}
+		p.order = append(p.order, team.ID)
+	}
+
+	return p
+}
+
+func (p *projection) apply(event types.LeagueEvent) error {
+	switch event.Type {
+	case types.EventFixtureGenerated:
+		var payload types.FixtureGeneratedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		p.matches = append(p.matches, payload.Matches...)
+		p.league.CurrentWeek = payload.CurrentWeek
+		p.league.TotalWeeks = payload.TotalWeeks
+		p.league.Seed = payload.Seed
+
+	case types.EventMatchPlayed, types.EventResultCorrected:
+		var payload types.MatchEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		return p.applyResult(payload)
+
+	case types.EventWeekAdvanced:
+		var payload types.WeekAdvancedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		p.league.CurrentWeek = payload.Week
+
+	case types.EventLeagueRestarted:
+		var payload types.LeagueRestartedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		for _, team := range p.teams {
+			*team = types.Team{ID: team.ID, Name: team.Name, Strength: team.Strength, Metadata: team.Metadata}
+		}
+		p.matches = nil
+		p.league.CurrentWeek = 0
+		p.league.TotalWeeks = 0
+		p.league.ChampionTeamName = ""
+		p.league.PlayoffChampionTeamName = ""
+		p.league.Season = payload.Season
+		p.league.Seed = 0
+
+	case types.EventSnapshotLoaded:
+		var payload types.Snapshot
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		p.loadSnapshot(payload)
+
+	case types.EventPointsDeducted:
+		var payload types.PointsDeductedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		team := p.team(payload.TeamID)
+		team.Points -= payload.Points
+		team.PointsDeducted += payload.Points
+
+	case types.EventPlayoffChampionDecided:
+		var payload types.PlayoffChampionDecidedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		p.league.PlayoffChampionTeamName = payload.TeamName
+
+	case types.EventTeamCreated, types.EventTeamUpdated:
+		var payload types.TeamChangedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		team := p.team(payload.TeamID)
+		team.Name = payload.Name
+		team.Strength = payload.Strength
+
+	case types.EventTeamDeleted:
+		var payload types.TeamChangedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		p.removeTeam(payload.TeamID)
+
+	default:
+		return fmt.Errorf("event %d: unknown event type %q", event.ID, event.Type)
+	}
+
+	return nil
+}
+
+// loadSnapshot replaces the projected state with a loaded snapshot. The
+// snapshot is recorded with the ids assigned on load.
+func (p *projection) loadSnapshot(snapshot types.Snapshot) {
+	p.teams = make(map[int]*types.Team)
+	p.order = nil
+	for _, team := range snapshot.Teams {
+		team := team
+		p.teams[team.ID] = &team
+		p.order = append(p.order, team.ID)
+	}
+
+	p.matches = append([]types.Match(nil), snapshot.Matches...)
+	p.league = types.League{
+		ID:                      p.league.ID,
+		Name:                    snapshot.League.Name,
+		CurrentWeek:             snapshot.League.CurrentWeek,
+		TotalWeeks:              snapshot.League.TotalWeeks,
+		ChampionTeamName:        snapshot.League.ChampionTeamName,
+		PlayoffChampionTeamName: snapshot.League.PlayoffChampionTeamName,
+		Season:                  snapshot.League.Season,
+		Seed:                    snapshot.League.Seed,
+	}
+}
+
+func (p *projection) applyResult(change types.MatchEvent) error {
+	var match *types.Match
+	for i := range p.matches {
+		if p.matches[i].ID == change.MatchID {
+			match = &p.matches[i]
+			break
+		}
+	}
+
+	if match == nil {
+		return fmt.Errorf("unknown match %d", change.MatchID)
+	}
+
+	team1 := p.team(match.Team1ID)
+	team2 := p.team(match.Team2ID)
+
+	if change.BeforePlayed {
+		applyScore(team1, team2, change.BeforeTeam1Score, change.BeforeTeam2Score, -1, !change.AfterPlayed)
+	}
+
+	if change.AfterPlayed {
+		applyScore(team1, team2, change.AfterTeam1Score, change.AfterTeam2Score, 1, !change.BeforePlayed)
+	}
+
+	match.Team1Score = change.AfterTeam1Score
+	match.Team2Score = change.AfterTeam2Score
+	match.Played = change.AfterPlayed
+	match.Version++
+
+	return nil
+}
+
+// team returns the projected team with the given id. Teams deleted since the
+// event was recorded are no longer in the store, so they are recreated on
+// first use and dropped again by their team_deleted event.
+func (p *projection) team(id int) *types.Team {
+	team, ok := p.teams[id]
+	if !ok {
+		team = &types.Team{ID: id}
+		p.teams[id] = team
+		p.order = append(p.order, id)
+	}
+	return team
+}
+
+func (p *projection) removeTeam(id int) {
+	delete(p.teams, id)
+	for i, teamID := range p.order {
+		if teamID == id {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// applyScore adds (sign 1) or removes (sign -1) a result from both teams'
+// statistics, counting the match itself only when countMatch is set.
+func applyScore(team1, team2 *types.Team, team1Score, team2Score, sign int, countMatch bool) {
+	if team1Score > team2Score {
+		team1.Wins += sign
+		team1.Points += 3 * sign
+		team2.Losses += sign
+	} else if team1Score < team2Score {
+		team2.Wins += sign
+		team2.Points += 3 * sign
+		team1.Losses += sign
+	} else {
+		team1.Draws += sign
+		team2.Draws += sign
+		team1.Points += sign
+		team2.Points += sign
+	}
+
+	if countMatch {
+		team1.Matches += sign
+		team2.Matches += sign
+	}
+
+	team1.GoalsFor += team1Score * sign
+	team1.GoalsAgainst += team2Score * sign
+	team1.GoalsDifference = team1.GoalsFor - team1.GoalsAgainst
+
+	team2.GoalsFor += team2Score * sign
+	team2.GoalsAgainst += team1Score * sign
+	team2.GoalsDifference = team2.GoalsFor - team2.GoalsAgainst
+}
+
+func (p *projection) state(eventID int) *types.LeagueState {
+	standings := make([]types.Team, 0, len(p.order))
+	for _, id := range p.order {
+		standings = append(standings, *p.teams[id])
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Points != standings[j].Points {
+			return standings[i].Points > standings[j].Points
+		}
+		if standings[i].GoalsDifference != standings[j].GoalsDifference {
+			return standings[i].GoalsDifference > standings[j].GoalsDifference
+		}
+		return standings[i].GoalsFor > standings[j].GoalsFor
+	})
+
+	matches := make([]types.Match, len(p.matches))
+	copy(matches, p.matches)
+
+	return &types.LeagueState{
+		EventID:   eventID,
+		League:    p.league,
+		Standings: standings,
+		Matches:   matches,
+	}
+}
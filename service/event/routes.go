@@ -0,0 +1,64 @@
+package event
+
+import (
+	"football-simulation/types"
+	"football-simulation/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	service types.LeagueEventService
+}
+
+func NewHandler(service types.LeagueEventService) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/league/events", h.handleGetEvents).Methods("GET")
+	router.HandleFunc("/league/replay", h.handleReplay).Methods("GET")
+}
+
+func (h *Handler) handleGetEvents(w http.ResponseWriter, r *http.Request) {
+	toEventID, err := parseToEventID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	events, err := h.service.GetEvents(toEventID)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, events)
+}
+
+func (h *Handler) handleReplay(w http.ResponseWriter, r *http.Request) {
+	toEventID, err := parseToEventID(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	state, err := h.service.Replay(toEventID)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, state)
+}
+
+func parseToEventID(r *http.Request) (int, error) {
+	to := r.URL.Query().Get("to")
+	if to == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(to)
+}
@@ -0,0 +1,95 @@
+package event
+
+import (
+	"football-simulation/types"
+)
+
+type Service struct {
+	store       types.LeagueEventStore
+	leagueStore types.LeagueStore
+	teamStore   types.Teamstore
+	transactor  types.Transactor
+}
+
+func NewService(store types.LeagueEventStore, leagueStore types.LeagueStore, teamStore types.Teamstore, transactor types.Transactor) *Service {
+	return &Service{
+		store:       store,
+		leagueStore: leagueStore,
+		teamStore:   teamStore,
+		transactor:  transactor,
+	}
+}
+
+func (s *Service) GetEvents(toEventID int) ([]types.LeagueEvent, error) {
+	events, err := s.store.GetEvents(toEventID)
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// Replay rebuilds the league state as it was right after toEventID, or after
+// the latest event when toEventID is 0. Teams are taken from the store with
+// their statistics cleared, since team identity and strength are not events.
+func (s *Service) Replay(toEventID int) (*types.LeagueState, error) {
+	return s.replay(s.store, s.leagueStore, s.teamStore, toEventID)
+}
+
+// Rebuild replays the whole log and overwrites the team statistics and the
+// league row with the result, so the projections match the log again.
+func (s *Service) Rebuild() (*types.LeagueState, error) {
+	var state *types.LeagueState
+
+	err := s.transactor.WithinTransaction(func(tx types.DBTX) error {
+		leagueStore := s.leagueStore.WithTx(tx)
+		teamStore := s.teamStore.WithTx(tx)
+
+		var err error
+		state, err = s.replay(s.store.WithTx(tx), leagueStore, teamStore, 0)
+		if err != nil {
+			return err
+		}
+
+		for _, team := range state.Standings {
+			if err := teamStore.UpdateTeam(team); err != nil {
+				return err
+			}
+		}
+
+		return leagueStore.UpdateLeague(state.League)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func (s *Service) replay(store types.LeagueEventStore, leagueStore types.LeagueStore, teamStore types.Teamstore, toEventID int) (*types.LeagueState, error) {
+	league, err := leagueStore.GetLeagueInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := teamStore.GetTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := store.GetEvents(toEventID)
+	if err != nil {
+		return nil, err
+	}
+
+	projection := newProjection(league, teams)
+	eventID := 0
+	for _, event := range events {
+		if err := projection.apply(event); err != nil {
+			return nil, err
+		}
+		eventID = event.ID
+	}
+
+	return projection.state(eventID), nil
+}
@@ -0,0 +1,60 @@
+package event
+
+import (
+	"encoding/json"
+	"football-simulation/types"
+)
+
+type Store struct {
+	db types.DBTX
+}
+
+func NewStore(db types.DBTX) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) WithTx(tx types.DBTX) types.LeagueEventStore {
+	return &Store{db: tx}
+}
+
+func (s *Store) AppendEvent(eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec("INSERT INTO league_events (type, payload) VALUES ($1, $2)", eventType, data)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetEvents returns the log in order, up to and including toEventID. A
+// toEventID of 0 returns the whole log.
+func (s *Store) GetEvents(toEventID int) ([]types.LeagueEvent, error) {
+	rows, err := s.db.Query("SELECT id, type, payload, created_at FROM league_events WHERE $1 = 0 OR id <= $1 ORDER BY id", toEventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]types.LeagueEvent, 0)
+	for rows.Next() {
+		var event types.LeagueEvent
+		var payload []byte
+		err := rows.Scan(&event.ID, &event.Type, &payload, &event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Payload = payload
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
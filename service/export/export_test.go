@@ -0,0 +1,293 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"football-simulation/database/memory"
+	"football-simulation/service/team"
+	"football-simulation/types"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+)
+
+// newExport returns the export service and router of a league of three teams
+// playing one match a week, the first of which has been played.
+func newExport(t *testing.T) (*Service, *mux.Router, []types.Team) {
+	t.Helper()
+
+	db := memory.NewDB()
+	leagueStore, teamStore := memory.NewLeagueStore(db), memory.NewTeamStore(db)
+	teamService := team.NewService(teamStore, leagueStore, memory.NewEventStore(db), memory.NewTransactor(db))
+
+	var teams []types.Team
+	for _, name := range []string{"Chelsea", "Arsenal", "Brighton & Hove Albion"} {
+		created, err := teamStore.CreateTeam(types.Team{Name: name, Strength: 70})
+		if err != nil {
+			t.Fatalf("CreateTeam: %v", err)
+		}
+		teams = append(teams, *created)
+	}
+
+	matches, err := memory.NewSimulationStore(db).SaveFixture([]types.Match{
+		{Week: 1, Team1ID: teams[0].ID, Team2ID: teams[1].ID},
+		{Week: 2, Team1ID: teams[1].ID, Team2ID: teams[2].ID},
+		{Week: 3, Team1ID: teams[2].ID, Team2ID: teams[0].ID},
+	})
+	if err != nil {
+		t.Fatalf("SaveFixture: %v", err)
+	}
+	if err := leagueStore.SaveMatchResult(types.Match{ID: matches[0].ID, Team1Score: 2, Team2Score: 1}); err != nil {
+		t.Fatalf("SaveMatchResult: %v", err)
+	}
+
+	teams[0].Matches, teams[0].Wins, teams[0].GoalsFor, teams[0].GoalsAgainst, teams[0].Points = 1, 1, 2, 1, 3
+	teams[1].Matches, teams[1].Losses, teams[1].GoalsFor, teams[1].GoalsAgainst = 1, 1, 1, 2
+	for _, played := range teams[:2] {
+		if err := teamStore.UpdateTeam(played); err != nil {
+			t.Fatalf("UpdateTeam: %v", err)
+		}
+	}
+
+	service := NewService(leagueStore, teamService)
+	router := mux.NewRouter()
+	NewHandler(service).RegisterRoutes(router)
+
+	return service, router, teams
+}
+
+func get(router *mux.Router, url string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+	return recorder
+}
+
+func readCSV(t *testing.T, recorder *httptest.ResponseRecorder) [][]string {
+	t.Helper()
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", recorder.Code, recorder.Body)
+	}
+	records, err := csv.NewReader(recorder.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+	return records
+}
+
+func TestGetMatchesFilter(t *testing.T) {
+	service, _, teams := newExport(t)
+
+	tests := []struct {
+		name   string
+		filter types.ExportFilter
+		weeks  []int
+	}{
+		{"everything", types.ExportFilter{}, []int{1, 2, 3}},
+		{"team", types.ExportFilter{TeamID: teams[0].ID}, []int{1, 3}},
+		{"from", types.ExportFilter{FromWeek: 2}, []int{2, 3}},
+		{"to", types.ExportFilter{ToWeek: 2}, []int{1, 2}},
+		{"team and range", types.ExportFilter{TeamID: teams[2].ID, FromWeek: 3, ToWeek: 3}, []int{3}},
+		{"past the fixture", types.ExportFilter{FromWeek: 4}, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := service.GetMatches(tt.filter)
+			if err != nil {
+				t.Fatalf("GetMatches: %v", err)
+			}
+
+			weeks := make([]int, 0, len(matches))
+			for _, match := range matches {
+				weeks = append(weeks, match.Week)
+			}
+			if len(weeks) != len(tt.weeks) {
+				t.Fatalf("weeks = %v, want %v", weeks, tt.weeks)
+			}
+			for i := range weeks {
+				if weeks[i] != tt.weeks[i] {
+					t.Fatalf("weeks = %v, want %v", weeks, tt.weeks)
+				}
+			}
+		})
+	}
+}
+
+func TestUnknownTeam(t *testing.T) {
+	service, router, _ := newExport(t)
+	filter := types.ExportFilter{TeamID: 99}
+
+	if _, err := service.GetMatches(filter); !errors.Is(err, team.ErrTeamNotFound) {
+		t.Errorf("GetMatches = %v, want %v", err, team.ErrTeamNotFound)
+	}
+	if _, err := service.GetStandings(filter); !errors.Is(err, team.ErrTeamNotFound) {
+		t.Errorf("GetStandings = %v, want %v", err, team.ErrTeamNotFound)
+	}
+
+	for _, path := range []string{"matches.csv", "matches.json", "standings.csv", "fixtures.ics"} {
+		recorder := get(router, "/league/export/"+path+"?team=99")
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", path, recorder.Code)
+			continue
+		}
+
+		var response types.Response
+		if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil || response.Code != "team_not_found" {
+			t.Errorf("%s: response = %+v, %v, want code team_not_found", path, response, err)
+		}
+	}
+}
+
+func TestInvalidFilter(t *testing.T) {
+	_, router, _ := newExport(t)
+
+	for _, query := range []string{"team=0", "team=chelsea", "from=-1", "to=x", "from=3&to=2", "start=17/08/2024"} {
+		if recorder := get(router, "/league/export/fixtures.ics?"+query); recorder.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", query, recorder.Code)
+		}
+	}
+}
+
+func TestMatchesCSV(t *testing.T) {
+	_, router, _ := newExport(t)
+
+	records := readCSV(t, get(router, "/league/export/matches.csv"))
+	want := [][]string{
+		{"id", "week", "home_team", "away_team", "home_score", "away_score", "played"},
+		{"1", "1", "Chelsea", "Arsenal", "2", "1", "true"},
+		{"2", "2", "Arsenal", "Brighton & Hove Albion", "", "", "false"},
+		{"3", "3", "Brighton & Hove Albion", "Chelsea", "", "", "false"},
+	}
+	assertRecords(t, records, want)
+
+	records = readCSV(t, get(router, "/league/export/matches.csv?to=1"))
+	assertRecords(t, records, want[:2])
+}
+
+func TestStandingsCSV(t *testing.T) {
+	_, router, teams := newExport(t)
+
+	records := readCSV(t, get(router, "/league/export/standings.csv"))
+	if len(records) != 4 {
+		t.Fatalf("got %d rows, want a header and 3 teams", len(records))
+	}
+	header := []string{"team", "played", "wins", "draws", "losses", "goals_for", "goals_against", "goal_difference", "points", "points_deducted"}
+	assertRecords(t, records[:1], [][]string{header})
+
+	// the week range does not apply to standings
+	records = readCSV(t, get(router, "/league/export/standings.csv?team="+strconv.Itoa(teams[1].ID)+"&to=1"))
+	assertRecords(t, records, [][]string{header, {"Arsenal", "1", "0", "0", "1", "1", "2", "-1", "0", "0"}})
+}
+
+func TestWriteCalendar(t *testing.T) {
+	start := time.Date(2024, 8, 17, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 8, 1, 12, 0, 0, 0, time.UTC)
+	kickoff := time.Date(2024, 8, 27, 19, 45, 0, 0, time.FixedZone("BST", 3600))
+
+	var buf bytes.Buffer
+	writeCalendar(&buf, []types.MatchResult{
+		{ID: 1, Week: 1, Team1Name: "Chelsea", Team2Name: "Arsenal", Team1Score: 2, Team2Score: 1, Played: true, Version: 2},
+		{ID: 2, Week: 2, Team1Name: "Arsenal", Team2Name: "Brighton & Hove Albion, U23", Version: 1},
+		{ID: 3, Week: 2, Team1Name: "Chelsea", Team2Name: "Everton", Version: 1, KickoffAt: &kickoff},
+	}, start, now)
+	calendar := buf.String()
+
+	if !strings.HasSuffix(calendar, "END:VCALENDAR\r\n") || strings.Contains(strings.ReplaceAll(calendar, "\r\n", ""), "\n") {
+		t.Fatalf("calendar lines must end with CRLF:\n%q", calendar)
+	}
+
+	for _, line := range []string{
+		"UID:match-1@football-simulation",
+		"DTSTAMP:20240801T120000Z",
+		"DTSTART:20240817T150000Z",
+		"DTEND:20240817T170000Z",
+		"SUMMARY:Chelsea 2-1 Arsenal",
+		"SEQUENCE:2",
+		// week 2 is played a week after start
+		"DTSTART:20240824T150000Z",
+		`SUMMARY:Arsenal vs Brighton & Hove Albion\, U23`,
+		// a scheduled kick-off is kept and written in UTC
+		"DTSTART:20240827T184500Z",
+		"DESCRIPTION:Week 2",
+	} {
+		if !strings.Contains(calendar, "\r\n"+line+"\r\n") {
+			t.Errorf("calendar has no line %q:\n%s", line, calendar)
+		}
+	}
+}
+
+func TestFoldLine(t *testing.T) {
+	short := strings.Repeat("a", 75)
+	if got := foldLine(short); got != short {
+		t.Errorf("foldLine of 75 octets = %q, want it unchanged", got)
+	}
+
+	// "é" takes two octets, so some of them straddle the 75 octet boundary
+	for _, line := range []string{
+		"SUMMARY:" + strings.Repeat("x", 200),
+		"SUMMARY:" + strings.Repeat("é", 100),
+		"SUMMARY:a" + strings.Repeat("é", 100),
+	} {
+		folded := foldLine(line)
+		parts := strings.Split(folded, "\r\n")
+		if len(parts) < 2 {
+			t.Errorf("foldLine(%q) was not folded", line)
+			continue
+		}
+
+		for i, part := range parts {
+			if len(part) > 75 {
+				t.Errorf("part %d is %d octets long, want at most 75", i, len(part))
+			}
+			if i > 0 && !strings.HasPrefix(part, " ") {
+				t.Errorf("continuation %q does not start with a space", part)
+			}
+			if !utf8.ValidString(part) {
+				t.Errorf("part %d splits a UTF-8 sequence: %q", i, part)
+			}
+		}
+
+		if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != line {
+			t.Errorf("unfolded = %q, want %q", unfolded, line)
+		}
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := map[string]string{
+		"Chelsea vs Arsenal": "Chelsea vs Arsenal",
+		"Brighton, Hove":     `Brighton\, Hove`,
+		"a;b":                `a\;b`,
+		`C:\path`:            `C:\\path`,
+		"line\nbreak":        `line\nbreak`,
+		`\,;` + "\n":         `\\\,\;\n`,
+	}
+
+	for text, want := range tests {
+		if got := escapeText(text); got != want {
+			t.Errorf("escapeText(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func assertRecords(t *testing.T, got, want [][]string) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d:\n%v", len(got), len(want), got)
+	}
+	for i := range want {
+		if strings.Join(got[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("row %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
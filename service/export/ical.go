@@ -0,0 +1,89 @@
+package export
+
+import (
+	"fmt"
+	"football-simulation/types"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	icalTimeLayout = "20060102T150405Z"
+	kickoffHour    = 15
+	matchDuration  = 2 * time.Hour
+)
+
+// writeCalendar writes matches as an iCalendar document with one VEVENT per
+// match. Matches scheduled by the league calendar keep their kick-off time;
+// otherwise week n is played n-1 weeks after start, kicking off at 15:00 UTC.
+func writeCalendar(w io.Writer, matches []types.MatchResult, start time.Time, now time.Time) {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//football-simulation//fixtures//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Football Simulation Fixtures",
+	}
+	writeLines(w, lines)
+
+	for _, match := range matches {
+		kickoff := time.Date(start.Year(), start.Month(), start.Day(), kickoffHour, 0, 0, 0, time.UTC).
+			AddDate(0, 0, 7*(match.Week-1))
+		if match.KickoffAt != nil {
+			kickoff = match.KickoffAt.UTC()
+		}
+
+		summary := fmt.Sprintf("%s vs %s", match.Team1Name, match.Team2Name)
+		if match.Played {
+			summary = fmt.Sprintf("%s %d-%d %s", match.Team1Name, match.Team1Score, match.Team2Score, match.Team2Name)
+		}
+
+		writeLines(w, []string{
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:match-%d@football-simulation", match.ID),
+			"DTSTAMP:" + now.UTC().Format(icalTimeLayout),
+			"DTSTART:" + kickoff.Format(icalTimeLayout),
+			"DTEND:" + kickoff.Add(matchDuration).Format(icalTimeLayout),
+			"SUMMARY:" + escapeText(summary),
+			"DESCRIPTION:" + escapeText(fmt.Sprintf("Week %d", match.Week)),
+			fmt.Sprintf("SEQUENCE:%d", match.Version),
+			"END:VEVENT",
+		})
+	}
+
+	writeLines(w, []string{"END:VCALENDAR"})
+}
+
+func writeLines(w io.Writer, lines []string) {
+	for _, line := range lines {
+		io.WriteString(w, foldLine(line)+"\r\n")
+	}
+}
+
+// foldLine splits content lines longer than 75 octets as required by
+// RFC 5545, without breaking UTF-8 sequences.
+func foldLine(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+
+	var folded strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			folded.WriteString("\r\n ")
+			width = 1
+		}
+		folded.WriteRune(r)
+		width += size
+	}
+
+	return folded.String()
+}
+
+func escapeText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(text)
+}
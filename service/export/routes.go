@@ -0,0 +1,192 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"football-simulation/types"
+	"football-simulation/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	service types.ExportService
+}
+
+func NewHandler(service types.ExportService) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/league/export/matches.csv", h.handleMatchesCSV).Methods("GET")
+	router.HandleFunc("/league/export/matches.json", h.handleMatchesJSON).Methods("GET")
+	router.HandleFunc("/league/export/standings.csv", h.handleStandingsCSV).Methods("GET")
+	router.HandleFunc("/league/export/fixtures.ics", h.handleFixturesICS).Methods("GET")
+}
+
+func (h *Handler) handleMatchesCSV(w http.ResponseWriter, r *http.Request) {
+	matches, ok := h.getMatches(w, r)
+	if !ok {
+		return
+	}
+
+	setAttachment(w, "text/csv; charset=utf-8", "matches.csv")
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"id", "week", "home_team", "away_team", "home_score", "away_score", "played"})
+	for _, match := range matches {
+		homeScore, awayScore := "", ""
+		if match.Played {
+			homeScore = strconv.Itoa(match.Team1Score)
+			awayScore = strconv.Itoa(match.Team2Score)
+		}
+
+		writer.Write([]string{
+			strconv.Itoa(match.ID),
+			strconv.Itoa(match.Week),
+			match.Team1Name,
+			match.Team2Name,
+			homeScore,
+			awayScore,
+			strconv.FormatBool(match.Played),
+		})
+	}
+	writer.Flush()
+}
+
+func (h *Handler) handleMatchesJSON(w http.ResponseWriter, r *http.Request) {
+	matches, ok := h.getMatches(w, r)
+	if !ok {
+		return
+	}
+
+	setAttachment(w, "application/json", "matches.json")
+
+	// Encode match by match so large fixtures are streamed instead of
+	// buffered in a single slice encoding.
+	encoder := json.NewEncoder(w)
+	w.Write([]byte("["))
+	for i, match := range matches {
+		if i > 0 {
+			w.Write([]byte(","))
+		}
+		encoder.Encode(match)
+	}
+	w.Write([]byte("]\n"))
+}
+
+func (h *Handler) handleStandingsCSV(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	standings, err := h.service.GetStandings(filter)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	setAttachment(w, "text/csv; charset=utf-8", "standings.csv")
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"team", "played", "wins", "draws", "losses", "goals_for", "goals_against", "goal_difference", "points", "points_deducted"})
+	for _, team := range standings {
+		writer.Write([]string{
+			team.Name,
+			strconv.Itoa(team.Matches),
+			strconv.Itoa(team.Wins),
+			strconv.Itoa(team.Draws),
+			strconv.Itoa(team.Losses),
+			strconv.Itoa(team.GoalsFor),
+			strconv.Itoa(team.GoalsAgainst),
+			strconv.Itoa(team.GoalsFor - team.GoalsAgainst),
+			strconv.Itoa(team.Points),
+			strconv.Itoa(team.PointsDeducted),
+		})
+	}
+	writer.Flush()
+}
+
+func (h *Handler) handleFixturesICS(w http.ResponseWriter, r *http.Request) {
+	start, err := parseStart(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	matches, ok := h.getMatches(w, r)
+	if !ok {
+		return
+	}
+
+	setAttachment(w, "text/calendar; charset=utf-8", "fixtures.ics")
+	writeCalendar(w, matches, start, time.Now().UTC())
+}
+
+func (h *Handler) getMatches(w http.ResponseWriter, r *http.Request) ([]types.MatchResult, bool) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	matches, err := h.service.GetMatches(filter)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return nil, false
+	}
+
+	return matches, true
+}
+
+func setAttachment(w http.ResponseWriter, contentType string, filename string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+}
+
+func parseFilter(r *http.Request) (types.ExportFilter, error) {
+	var filter types.ExportFilter
+	query := r.URL.Query()
+
+	for name, target := range map[string]*int{"team": &filter.TeamID, "from": &filter.FromWeek, "to": &filter.ToWeek} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return filter, fmt.Errorf("invalid %s %q", name, value)
+		}
+		*target = parsed
+	}
+
+	if filter.ToWeek != 0 && filter.FromWeek > filter.ToWeek {
+		return filter, fmt.Errorf("from must not be after to")
+	}
+
+	return filter, nil
+}
+
+// parseStart returns the date of the first matchday. Without a start query
+// parameter the fixture begins on the next Saturday.
+func parseStart(r *http.Request) (time.Time, error) {
+	value := r.URL.Query().Get("start")
+	if value == "" {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		return today.AddDate(0, 0, (int(time.Saturday)-int(today.Weekday())+7)%7), nil
+	}
+
+	start, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid start %q, expected YYYY-MM-DD", value)
+	}
+
+	return start, nil
+}
@@ -0,0 +1,104 @@
+package export
+
+import (
+	"football-simulation/service/team"
+	"football-simulation/types"
+	"sort"
+)
+
+type Service struct {
+	leagueStore types.LeagueStore
+	teamService types.TeamService
+}
+
+func NewService(leagueStore types.LeagueStore, teamService types.TeamService) *Service {
+	return &Service{leagueStore: leagueStore, teamService: teamService}
+}
+
+// GetMatches returns the fixture and results matching filter, ordered by week.
+// A filter on a team that does not exist fails with team.ErrTeamNotFound.
+func (s *Service) GetMatches(filter types.ExportFilter) ([]types.MatchResult, error) {
+	matches, err := s.leagueStore.GetAllMatches()
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := s.teamService.GetTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	teamNames := make(map[int]string)
+	for _, team := range teams {
+		teamNames[team.ID] = team.Name
+	}
+
+	if _, ok := teamNames[filter.TeamID]; filter.TeamID != 0 && !ok {
+		return nil, team.ErrTeamNotFound.Errorf("team %d not found", filter.TeamID)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Week != matches[j].Week {
+			return matches[i].Week < matches[j].Week
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	results := make([]types.MatchResult, 0)
+	for _, match := range matches {
+		if !matchesFilter(match, filter) {
+			continue
+		}
+
+		results = append(results, types.MatchResult{
+			ID:         match.ID,
+			Week:       match.Week,
+			Team1Name:  teamNames[match.Team1ID],
+			Team2Name:  teamNames[match.Team2ID],
+			Team1Score: match.Team1Score,
+			Team2Score: match.Team2Score,
+			Played:     match.Played,
+			Version:    match.Version,
+			KickoffAt:  match.KickoffAt,
+		})
+	}
+
+	return results, nil
+}
+
+// GetStandings returns the standings, restricted to one team when the filter
+// names one. The week range does not apply to standings.
+func (s *Service) GetStandings(filter types.ExportFilter) ([]types.Team, error) {
+	standings, err := s.leagueStore.GetStandings()
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.TeamID == 0 {
+		return standings, nil
+	}
+
+	for _, standing := range standings {
+		if standing.ID == filter.TeamID {
+			return []types.Team{standing}, nil
+		}
+	}
+
+	return nil, team.ErrTeamNotFound.Errorf("team %d not found", filter.TeamID)
+}
+
+func matchesFilter(match types.Match, filter types.ExportFilter) bool {
+	if filter.TeamID != 0 && match.Team1ID != filter.TeamID && match.Team2ID != filter.TeamID {
+		return false
+	}
+
+	if filter.FromWeek != 0 && match.Week < filter.FromWeek {
+		return false
+	}
+
+	if filter.ToWeek != 0 && match.Week > filter.ToWeek {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,212 @@
+package fixture
+
+import (
+	"football-simulation/types"
+	"math"
+	"sort"
+	"strings"
+)
+
+// analyse measures the fixture of teams. The fixture is made of cycles in
+// which every team meets every other team once, up to splitAfter in a split
+// league, followed by the split rounds. The halves of the season are made of
+// whole cycles, the split rounds belonging to the second. Matches of teams no
+// longer in the league are left out.
+func analyse(matches []types.Match, teams []types.Team, splitAfter int) *types.FixtureAnalysis {
+	weeks := 0
+	for _, match := range matches {
+		weeks = max(weeks, match.Week)
+	}
+
+	regularWeeks := weeks
+	if splitAfter > 0 && splitAfter < weeks {
+		regularWeeks = splitAfter
+	}
+
+	// every team of the league plays in the cycles, only some of them meet
+	// in a split round
+	fixtureTeams := make(map[int]bool)
+	for _, match := range matches {
+		if match.Week <= regularWeeks {
+			fixtureTeams[match.Team1ID] = true
+			fixtureTeams[match.Team2ID] = true
+		}
+	}
+	cycle := max(cycleWeeks(len(fixtureTeams)), 1)
+	cycles := (regularWeeks + cycle - 1) / cycle
+	// the first half holds the extra cycle of an odd number of cycles
+	firstHalfCycles := (cycles + 1) / 2
+
+	// cycleOf returns the index of the cycle of week, cycles for the split
+	// rounds
+	cycleOf := func(week int) int {
+		if week > regularWeeks {
+			return cycles
+		}
+		return (week - 1) / cycle
+	}
+	periods := cycles
+	if regularWeeks < weeks {
+		periods++
+	}
+
+	sorted := append([]types.Match(nil), matches...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Week < sorted[j].Week })
+
+	strengths := make(map[int]int, len(teams))
+	for _, team := range teams {
+		strengths[team.ID] = team.Strength
+	}
+
+	// home games by week, to find the game of the cycle before a game mirrors
+	hosts := make(map[[3]int]bool)
+	for _, match := range sorted {
+		hosts[[3]int{match.Week, match.Team1ID, match.Team2ID}] = true
+	}
+
+	analysis := &types.FixtureAnalysis{Weeks: weeks, CycleWeeks: cycle, Teams: make([]types.TeamFixtureAnalysis, 0, len(teams))}
+	spread := newRange()
+	rest := newRange()
+
+	for _, team := range teams {
+		t := types.TeamFixtureAnalysis{TeamID: team.ID, TeamName: team.Name, BreakWeeks: []int{}}
+		venues := []byte(strings.Repeat("-", weeks))
+		firstHalf, secondHalf, season := newRange(), newRange(), newRange()
+		teamRest := newRange()
+
+		t.Cycles = make([]types.CycleBalance, periods)
+		cycleStrengths := make([]*valueRange, periods)
+		for i := range t.Cycles {
+			t.Cycles[i] = types.CycleBalance{Cycle: i + 1, Split: i == cycles}
+			cycleStrengths[i] = newRange()
+		}
+
+		var previous *types.Match
+		for i := range sorted {
+			match := &sorted[i]
+			home := match.Team1ID == team.ID
+			if !home && match.Team2ID != team.ID {
+				continue
+			}
+
+			opponent, venue := match.Team1ID, byte('A')
+			if home {
+				opponent, venue = match.Team2ID, 'H'
+				t.HomeGames++
+			} else {
+				t.AwayGames++
+			}
+			venues[match.Week-1] = venue
+
+			index := cycleOf(match.Week)
+			firstHalfGame := index < firstHalfCycles
+			// a game of a later cycle mirrors the same round of the cycle before
+			mirrored := index > 0 && index < cycles && hosts[[3]int{match.Week - cycle, match.Team2ID, match.Team1ID}]
+
+			c := &t.Cycles[index]
+			c.Games++
+			if home {
+				c.HomeGames++
+			}
+			if mirrored {
+				c.Mirrored++
+			}
+
+			if strength, ok := strengths[opponent]; ok {
+				season.add(float64(strength))
+				cycleStrengths[index].add(float64(strength))
+				if firstHalfGame {
+					firstHalf.add(float64(strength))
+				} else {
+					secondHalf.add(float64(strength))
+				}
+			}
+
+			if firstHalfGame {
+				if home {
+					t.SecondHalf.FirstHalfHome++
+				}
+			} else {
+				t.SecondHalf.Games++
+				if home {
+					t.SecondHalf.SecondHalfHome++
+				}
+				if mirrored {
+					t.SecondHalf.Mirrored++
+				}
+			}
+
+			if previous != nil {
+				if (previous.Team1ID == team.ID) == home {
+					t.Breaks++
+					t.BreakWeeks = append(t.BreakWeeks, match.Week)
+				}
+				if previous.KickoffAt != nil && match.KickoffAt != nil {
+					days := match.KickoffAt.Sub(*previous.KickoffAt).Hours() / 24
+					teamRest.add(days)
+					rest.add(days)
+				}
+			}
+			previous = match
+		}
+
+		t.Venues = string(venues)
+		for i := range t.Cycles {
+			t.Cycles[i].StrengthOfSchedule = round(cycleStrengths[i].average())
+		}
+		t.StrengthOfSchedule = types.StrengthOfSchedule{
+			FirstHalf:  round(firstHalf.average()),
+			SecondHalf: round(secondHalf.average()),
+			Season:     round(season.average()),
+		}
+		if teamRest.count > 0 {
+			t.RestDays = &types.RestDays{Min: round(teamRest.min), Max: round(teamRest.max), Average: round(teamRest.average())}
+		}
+		if season.count > 0 {
+			spread.add(season.average())
+		}
+
+		analysis.TotalBreaks += t.Breaks
+		analysis.MaxBreaks = max(analysis.MaxBreaks, t.Breaks)
+		analysis.Teams = append(analysis.Teams, t)
+	}
+
+	if spread.count > 0 {
+		analysis.StrengthSpread = round(spread.max - spread.min)
+	}
+	if rest.count > 0 {
+		minRest := round(rest.min)
+		analysis.MinRestDays = &minRest
+	}
+
+	return analysis
+}
+
+// valueRange accumulates the minimum, maximum and sum of values.
+type valueRange struct {
+	min, max, sum float64
+	count         int
+}
+
+func newRange() *valueRange {
+	return &valueRange{min: math.Inf(1), max: math.Inf(-1)}
+}
+
+func (r *valueRange) add(value float64) {
+	r.min = math.Min(r.min, value)
+	r.max = math.Max(r.max, value)
+	r.sum += value
+	r.count++
+}
+
+func (r *valueRange) average() float64 {
+	if r.count == 0 {
+		return 0
+	}
+	return r.sum / float64(r.count)
+}
+
+// round rounds to two decimals.
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
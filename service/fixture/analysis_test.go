@@ -0,0 +1,192 @@
+package fixture
+
+import (
+	"football-simulation/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAnalyse(t *testing.T) {
+	teams := []types.Team{
+		{ID: 1, Name: "Chelsea", Strength: 80},
+		{ID: 2, Name: "Arsenal", Strength: 90},
+		{ID: 3, Name: "Liverpool", Strength: 70},
+		{ID: 4, Name: "Everton", Strength: 60},
+	}
+
+	saturday := time.Date(2024, time.August, 10, 15, 0, 0, 0, time.UTC)
+	kickoff := func(days float64) *time.Time {
+		at := saturday.Add(time.Duration(days * 24 * float64(time.Hour)))
+		return &at
+	}
+
+	matches := []types.Match{
+		{Week: 1, Team1ID: 1, Team2ID: 2, KickoffAt: kickoff(0)},
+		{Week: 1, Team1ID: 3, Team2ID: 4, KickoffAt: kickoff(0)},
+		{Week: 2, Team1ID: 1, Team2ID: 3, KickoffAt: kickoff(3.5)},
+		{Week: 2, Team1ID: 4, Team2ID: 2, KickoffAt: kickoff(4)},
+		{Week: 3, Team1ID: 4, Team2ID: 1, KickoffAt: kickoff(7)},
+		{Week: 3, Team1ID: 2, Team2ID: 3, KickoffAt: kickoff(7)},
+		{Week: 4, Team1ID: 2, Team2ID: 1},
+		{Week: 4, Team1ID: 4, Team2ID: 3},
+		{Week: 5, Team1ID: 3, Team2ID: 1},
+		{Week: 5, Team1ID: 2, Team2ID: 4},
+		// not a mirror of week 3, Everton hosting again
+		{Week: 6, Team1ID: 4, Team2ID: 1},
+		{Week: 6, Team1ID: 3, Team2ID: 2},
+	}
+
+	analysis := analyse(matches, teams, 0)
+
+	if analysis.Weeks != 6 || len(analysis.Teams) != 4 {
+		t.Fatalf("analysis = %+v, want 6 weeks of 4 teams", analysis)
+	}
+
+	chelsea := analysis.Teams[0]
+	if chelsea.Venues != "HHAAAA" || chelsea.HomeGames != 2 || chelsea.AwayGames != 4 {
+		t.Errorf("Chelsea venues = %s, %d home, %d away, want HHAAAA, 2 home, 4 away", chelsea.Venues, chelsea.HomeGames, chelsea.AwayGames)
+	}
+	if chelsea.Breaks != 4 || !reflect.DeepEqual(chelsea.BreakWeeks, []int{2, 4, 5, 6}) {
+		t.Errorf("Chelsea breaks = %d in weeks %v, want 4 in weeks 2, 4, 5 and 6", chelsea.Breaks, chelsea.BreakWeeks)
+	}
+	if want := (types.StrengthOfSchedule{FirstHalf: 73.33, SecondHalf: 73.33, Season: 73.33}); chelsea.StrengthOfSchedule != want {
+		t.Errorf("Chelsea strength of schedule = %+v, want %+v", chelsea.StrengthOfSchedule, want)
+	}
+	if want := (types.HalfBalance{FirstHalfHome: 2, SecondHalfHome: 0, Mirrored: 2, Games: 3}); chelsea.SecondHalf != want {
+		t.Errorf("Chelsea second half = %+v, want %+v", chelsea.SecondHalf, want)
+	}
+	if want := (types.RestDays{Min: 3.5, Max: 3.5, Average: 3.5}); chelsea.RestDays == nil || *chelsea.RestDays != want {
+		t.Errorf("Chelsea rest days = %+v, want %+v", chelsea.RestDays, want)
+	}
+
+	arsenal := analysis.Teams[1]
+	if want := (types.RestDays{Min: 3, Max: 4, Average: 3.5}); arsenal.RestDays == nil || *arsenal.RestDays != want {
+		t.Errorf("Arsenal rest days = %+v, want %+v", arsenal.RestDays, want)
+	}
+	if arsenal.StrengthOfSchedule.Season != 70 {
+		t.Errorf("Arsenal season strength of schedule = %v, want 70", arsenal.StrengthOfSchedule.Season)
+	}
+
+	if analysis.TotalBreaks != 4+3+3+2 || analysis.MaxBreaks != 4 {
+		t.Errorf("breaks = %d, at most %d, want 12, at most 4", analysis.TotalBreaks, analysis.MaxBreaks)
+	}
+	// Everton face the strongest opponents, Arsenal the weakest
+	if analysis.StrengthSpread != 10 {
+		t.Errorf("strength spread = %v, want 10", analysis.StrengthSpread)
+	}
+	if analysis.MinRestDays == nil || *analysis.MinRestDays != 3 {
+		t.Errorf("min rest days = %v, want 3", analysis.MinRestDays)
+	}
+}
+
+// cycleOf returns the matches of one cycle of four teams; mirrored swaps
+// home and away.
+func cycleOf(first int, mirrored bool) []types.Match {
+	games := [][3]int{{0, 1, 2}, {0, 3, 4}, {1, 1, 3}, {1, 4, 2}, {2, 4, 1}, {2, 2, 3}}
+
+	matches := make([]types.Match, 0, len(games))
+	for _, game := range games {
+		home, away := game[1], game[2]
+		if mirrored {
+			home, away = away, home
+		}
+		matches = append(matches, types.Match{Week: first + game[0], Team1ID: home, Team2ID: away})
+	}
+	return matches
+}
+
+func TestAnalyseCycles(t *testing.T) {
+	teams := []types.Team{
+		{ID: 1, Name: "Chelsea", Strength: 80},
+		{ID: 2, Name: "Arsenal", Strength: 90},
+		{ID: 3, Name: "Liverpool", Strength: 70},
+		{ID: 4, Name: "Everton", Strength: 60},
+	}
+
+	// three cycles: the second mirrors the first, the third repeats it
+	var matches []types.Match
+	matches = append(matches, cycleOf(1, false)...)
+	matches = append(matches, cycleOf(4, true)...)
+	matches = append(matches, cycleOf(7, false)...)
+
+	analysis := analyse(matches, teams, 0)
+	if analysis.Weeks != 9 || analysis.CycleWeeks != 3 {
+		t.Fatalf("analysis = %d weeks in cycles of %d, want 9 in cycles of 3", analysis.Weeks, analysis.CycleWeeks)
+	}
+
+	chelsea := analysis.Teams[0]
+	if chelsea.Venues != "HHAAAHHHA" {
+		t.Errorf("Chelsea venues = %s, want HHAAAHHHA", chelsea.Venues)
+	}
+
+	// every cycle replays the one before with home and away swapped
+	want := []types.CycleBalance{
+		{Cycle: 1, Games: 3, HomeGames: 2, Mirrored: 0, StrengthOfSchedule: 73.33},
+		{Cycle: 2, Games: 3, HomeGames: 1, Mirrored: 3, StrengthOfSchedule: 73.33},
+		{Cycle: 3, Games: 3, HomeGames: 2, Mirrored: 3, StrengthOfSchedule: 73.33},
+	}
+	if !reflect.DeepEqual(chelsea.Cycles, want) {
+		t.Errorf("Chelsea cycles = %+v, want %+v", chelsea.Cycles, want)
+	}
+
+	// the first half takes the first two cycles
+	if want := (types.HalfBalance{FirstHalfHome: 3, SecondHalfHome: 2, Mirrored: 3, Games: 3}); chelsea.SecondHalf != want {
+		t.Errorf("Chelsea second half = %+v, want %+v", chelsea.SecondHalf, want)
+	}
+}
+
+func TestAnalyseSplit(t *testing.T) {
+	teams := []types.Team{
+		{ID: 1, Name: "Chelsea", Strength: 80},
+		{ID: 2, Name: "Arsenal", Strength: 90},
+		{ID: 3, Name: "Liverpool", Strength: 70},
+		{ID: 4, Name: "Everton", Strength: 60},
+	}
+
+	// one cycle, then the top two and the bottom two meet once more
+	matches := append(cycleOf(1, false),
+		types.Match{Week: 4, Team1ID: 2, Team2ID: 1},
+		types.Match{Week: 4, Team1ID: 4, Team2ID: 3},
+	)
+
+	analysis := analyse(matches, teams, 3)
+	if analysis.CycleWeeks != 3 {
+		t.Fatalf("cycle weeks = %d, want 3", analysis.CycleWeeks)
+	}
+
+	chelsea := analysis.Teams[0]
+	want := []types.CycleBalance{
+		{Cycle: 1, Games: 3, HomeGames: 2, Mirrored: 0, StrengthOfSchedule: 73.33},
+		{Cycle: 2, Split: true, Games: 1, HomeGames: 0, Mirrored: 0, StrengthOfSchedule: 90},
+	}
+	if !reflect.DeepEqual(chelsea.Cycles, want) {
+		t.Errorf("Chelsea cycles = %+v, want %+v", chelsea.Cycles, want)
+	}
+
+	// the split round is the second half
+	if want := (types.StrengthOfSchedule{FirstHalf: 73.33, SecondHalf: 90, Season: 77.5}); chelsea.StrengthOfSchedule != want {
+		t.Errorf("Chelsea strength of schedule = %+v, want %+v", chelsea.StrengthOfSchedule, want)
+	}
+	if want := (types.HalfBalance{FirstHalfHome: 2, SecondHalfHome: 0, Mirrored: 0, Games: 1}); chelsea.SecondHalf != want {
+		t.Errorf("Chelsea second half = %+v, want %+v", chelsea.SecondHalf, want)
+	}
+}
+
+func TestAnalyseLeavesWeeksOff(t *testing.T) {
+	teams := []types.Team{{ID: 1, Name: "Chelsea"}, {ID: 2, Name: "Arsenal"}, {ID: 3, Name: "Liverpool"}}
+	matches := []types.Match{
+		{Week: 1, Team1ID: 1, Team2ID: 2},
+		{Week: 2, Team1ID: 2, Team2ID: 3},
+		{Week: 3, Team1ID: 3, Team2ID: 1},
+	}
+
+	analysis := analyse(matches, teams, 0)
+
+	if got := analysis.Teams[0].Venues; got != "H-A" {
+		t.Errorf("Chelsea venues = %s, want H-A", got)
+	}
+	if analysis.MinRestDays != nil || analysis.Teams[0].RestDays != nil {
+		t.Errorf("rest days = %v, want none without kick-off times", analysis.MinRestDays)
+	}
+}
@@ -0,0 +1,69 @@
+package fixture
+
+import (
+	"football-simulation/types"
+	"football-simulation/utils"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	service types.FixtureService
+}
+
+func NewHandler(service types.FixtureService) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/league/fixture/config", h.handleGetConfig).Methods("GET")
+	router.HandleFunc("/league/fixture/config", h.handleSetConfig).Methods("PUT")
+	router.HandleFunc("/league/fixture/preview", h.handlePreview).Methods("GET")
+	router.HandleFunc("/league/fixture/analysis", h.handleAnalyse).Methods("GET")
+}
+
+func (h *Handler) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	config, err := h.service.GetConfig()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, config)
+}
+
+func (h *Handler) handleSetConfig(w http.ResponseWriter, r *http.Request) {
+	var req types.FixtureConfig
+	if !utils.ParseAndValidate(w, r, &req) {
+		return
+	}
+
+	config, err := h.service.SetConfig(req)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, config)
+}
+
+func (h *Handler) handlePreview(w http.ResponseWriter, r *http.Request) {
+	preview, err := h.service.Preview()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, preview)
+}
+
+func (h *Handler) handleAnalyse(w http.ResponseWriter, r *http.Request) {
+	analysis, err := h.service.Analyse()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, analysis)
+}
@@ -0,0 +1,215 @@
+package fixture
+
+import (
+	"football-simulation/types"
+)
+
+var (
+	ErrFixtureNotFound      = types.NewError(types.ErrorKindNotFound, "fixture_not_found", "the league has no fixture yet, play a week to generate it")
+	ErrInvalidFixtureConfig = types.NewError(types.ErrorKindValidation, "invalid_fixture_config", "the fixture configuration is invalid")
+)
+
+const defaultCycles = 2
+
+type Service struct {
+	store             types.FixtureStore
+	leagueStore       types.LeagueStore
+	teamStore         types.Teamstore
+	simulationService types.SimulationService
+}
+
+func NewService(store types.FixtureStore, leagueStore types.LeagueStore, teamStore types.Teamstore, simulationService types.SimulationService) *Service {
+	return &Service{
+		store:             store,
+		leagueStore:       leagueStore,
+		teamStore:         teamStore,
+		simulationService: simulationService,
+	}
+}
+
+func (s *Service) WithTx(tx types.DBTX) types.FixtureService {
+	return &Service{
+		store:             s.store.WithTx(tx),
+		leagueStore:       s.leagueStore.WithTx(tx),
+		teamStore:         s.teamStore.WithTx(tx),
+		simulationService: s.simulationService.WithTx(tx),
+	}
+}
+
+// GetConfig returns the fixture configuration of the league, the defaults
+// when none has been set.
+func (s *Service) GetConfig() (*types.LeagueFixtureConfig, error) {
+	league, err := s.leagueStore.GetLeagueInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := s.store.GetFixtureConfig(league.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	config.LeagueID = league.ID
+	return config, nil
+}
+
+// SetConfig replaces the fixture configuration. It applies from the next
+// fixture generated; the current one is kept.
+func (s *Service) SetConfig(request types.FixtureConfig) (*types.LeagueFixtureConfig, error) {
+	config, err := s.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	config.FixtureConfig = request
+	if err := s.store.SaveFixtureConfig(*config); err != nil {
+		return nil, err
+	}
+
+	return s.GetConfig()
+}
+
+// Preview plans a fixture of the current teams with the configuration,
+// without saving it.
+func (s *Service) Preview() (*types.FixturePreview, error) {
+	config, err := s.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := s.teamStore.GetTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := seasonWeeks(len(teams), config.FixtureConfig); err != nil {
+		return nil, err
+	}
+
+	matches, report := s.simulationService.PlanFixture(teams, config.FixtureConfig)
+
+	teamNames := make(map[int]string, len(teams))
+	for _, team := range teams {
+		teamNames[team.ID] = team.Name
+	}
+
+	preview := &types.FixturePreview{Matches: make([]types.MatchResult, 0, len(matches)), Report: *report}
+	for _, match := range matches {
+		preview.Matches = append(preview.Matches, types.MatchResult{
+			Week:      match.Week,
+			Team1Name: teamNames[match.Team1ID],
+			Team2Name: teamNames[match.Team2ID],
+		})
+	}
+
+	return preview, nil
+}
+
+// Generate generates and saves the fixture of teams with the configuration
+// and keeps its report as the last report. It returns the fixture and the
+// weeks of the season, the rounds after a split included.
+func (s *Service) Generate(teams []types.Team) ([]types.Match, int, error) {
+	config, err := s.GetConfig()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	weeks, err := seasonWeeks(len(teams), config.FixtureConfig)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	matches, report, err := s.simulationService.GenerateFixture(teams, config.FixtureConfig)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	config.LastReport = report
+	if err := s.store.SaveFixtureConfig(*config); err != nil {
+		return nil, 0, err
+	}
+
+	return matches, weeks, nil
+}
+
+// GenerateSplit generates and saves the rounds after the split of a split
+// league from week on: the top half of standings and the bottom half each
+// play a single round robin. The top half gets the extra team of an odd
+// number of teams.
+func (s *Service) GenerateSplit(standings []types.Team, week int) ([]types.Match, error) {
+	config, err := s.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	top := (len(standings) + 1) / 2
+	groupConfig := types.FixtureConfig{Cycles: 1, MaxConsecutive: config.MaxConsecutive, Shuffle: config.Shuffle}
+
+	var matches []types.Match
+	for _, group := range [][]types.Team{standings[:top], standings[top:]} {
+		planned, _ := s.simulationService.PlanFixture(group, groupConfig)
+		for _, match := range planned {
+			match.Week += week - 1
+			matches = append(matches, match)
+		}
+	}
+
+	return s.simulationService.SaveFixture(matches)
+}
+
+// Analyse measures how fair the current fixture is to every team.
+func (s *Service) Analyse() (*types.FixtureAnalysis, error) {
+	config, err := s.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := s.leagueStore.GetAllMatches()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(matches) == 0 {
+		return nil, ErrFixtureNotFound
+	}
+
+	teams, err := s.teamStore.GetTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	return analyse(matches, teams, config.SplitAfter), nil
+}
+
+// seasonWeeks returns the weeks of the season of teams under config, the
+// rounds after a split included.
+func seasonWeeks(teams int, config types.FixtureConfig) (int, error) {
+	cycles := config.Cycles
+	if cycles == 0 {
+		cycles = defaultCycles
+	}
+
+	weeks := cycles * cycleWeeks(teams)
+	if config.SplitAfter == 0 {
+		return weeks, nil
+	}
+
+	if config.SplitAfter > weeks {
+		return 0, ErrInvalidFixtureConfig.Errorf("the league splits after week %d but the fixture of %d teams has %d weeks", config.SplitAfter, teams, weeks)
+	}
+
+	top := (teams + 1) / 2
+	return config.SplitAfter + max(cycleWeeks(top), cycleWeeks(teams-top)), nil
+}
+
+// cycleWeeks returns the weeks teams need to meet each other once; an odd
+// number of teams needs a week more, every team having a week off.
+func cycleWeeks(teams int) int {
+	if teams < 2 {
+		return 0
+	}
+	if teams%2 != 0 {
+		return teams
+	}
+	return teams - 1
+}
@@ -0,0 +1,38 @@
+package fixture
+
+import (
+	"errors"
+	"football-simulation/types"
+	"testing"
+)
+
+func TestSeasonWeeks(t *testing.T) {
+	tests := []struct {
+		name   string
+		teams  int
+		config types.FixtureConfig
+		weeks  int
+	}{
+		{"double round robin", 20, types.FixtureConfig{}, 38},
+		{"odd number of teams", 5, types.FixtureConfig{}, 10},
+		{"single round robin", 18, types.FixtureConfig{Cycles: 1}, 17},
+		{"quadruple round robin", 10, types.FixtureConfig{Cycles: 4}, 36},
+		// the Scottish Premiership: three cycles, then five rounds among six teams
+		{"split", 12, types.FixtureConfig{Cycles: 3, SplitAfter: 33}, 38},
+		{"split of an odd number of teams", 7, types.FixtureConfig{SplitAfter: 7}, 10},
+		{"no teams", 0, types.FixtureConfig{}, 0},
+	}
+
+	for _, test := range tests {
+		weeks, err := seasonWeeks(test.teams, test.config)
+		if err != nil || weeks != test.weeks {
+			t.Errorf("%s: seasonWeeks = %d, %v, want %d", test.name, weeks, err, test.weeks)
+		}
+	}
+}
+
+func TestSeasonWeeksRejectsASplitAfterTheFixture(t *testing.T) {
+	if _, err := seasonWeeks(12, types.FixtureConfig{Cycles: 3, SplitAfter: 34}); !errors.Is(err, ErrInvalidFixtureConfig) {
+		t.Errorf("seasonWeeks = %v, want ErrInvalidFixtureConfig", err)
+	}
+}
@@ -0,0 +1,78 @@
+package fixture
+
+import (
+	"encoding/json"
+	"football-simulation/types"
+	"time"
+)
+
+type Store struct {
+	db types.DBTX
+}
+
+func NewStore(db types.DBTX) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) WithTx(tx types.DBTX) types.FixtureStore {
+	return &Store{db: tx}
+}
+
+// GetFixtureConfig returns the fixture configuration of the league, or an
+// empty one when none has been saved.
+func (s *Store) GetFixtureConfig(leagueID int) (*types.LeagueFixtureConfig, error) {
+	rows, err := s.db.Query("SELECT league_id, config, last_report, updated_at FROM league_fixture_config WHERE league_id = $1", leagueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	config := &types.LeagueFixtureConfig{}
+	for rows.Next() {
+		var data, report []byte
+		if err := rows.Scan(&config.LeagueID, &data, &report, &config.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(data, &config.FixtureConfig); err != nil {
+			return nil, err
+		}
+
+		if report != nil {
+			config.LastReport = new(types.FixtureReport)
+			if err := json.Unmarshal(report, config.LastReport); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// SaveFixtureConfig creates or replaces the configuration and last report of
+// config.LeagueID.
+func (s *Store) SaveFixtureConfig(config types.LeagueFixtureConfig) error {
+	data, err := json.Marshal(config.FixtureConfig)
+	if err != nil {
+		return err
+	}
+
+	var report []byte
+	if config.LastReport != nil {
+		if report, err = json.Marshal(config.LastReport); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.db.Exec(`INSERT INTO league_fixture_config (league_id, config, last_report, updated_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (league_id) DO UPDATE SET config = $2, last_report = $3, updated_at = $4`,
+		config.LeagueID, data, report, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	return nil
+}
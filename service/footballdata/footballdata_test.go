@@ -0,0 +1,159 @@
+package footballdata
+
+import (
+	"errors"
+	"football-simulation/types"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseFile(t *testing.T) []result {
+	t.Helper()
+
+	f, err := os.Open("testdata/season.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	report := &types.SeasonImportReport{}
+	results, err := parseResults(f, report)
+	if err != nil {
+		t.Fatalf("parseResults: %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("parseResults reported %+v, want no errors", report.Errors)
+	}
+	return results
+}
+
+func TestParseResults(t *testing.T) {
+	results := parseFile(t)
+
+	// the blank row is skipped, the betting columns are ignored
+	if len(results) != 6 {
+		t.Fatalf("parseResults returned %d results, want 6", len(results))
+	}
+
+	want := result{row: 4, date: time.Date(2024, time.August, 17, 0, 0, 0, 0, time.UTC), homeTeam: "Chelsea", awayTeam: "Liverpool", homeGoals: 1, awayGoals: 3}
+	if results[2] != want {
+		t.Errorf("third result = %+v, want %+v", results[2], want)
+	}
+	if results[5].row != 8 {
+		t.Errorf("last result on row %d, want 8 after the blank row", results[5].row)
+	}
+}
+
+func TestParseResultsRejectsRows(t *testing.T) {
+	const header = "Date,HomeTeam,AwayTeam,FTHG,FTAG\n"
+
+	tests := []struct {
+		name  string
+		row   string
+		field string
+	}{
+		{"missing team", "10/08/2024,Arsenal,,1,0", "HomeTeam"},
+		{"team playing itself", "10/08/2024,Arsenal,Arsenal,1,0", "AwayTeam"},
+		{"invalid date", "2024-08-10,Arsenal,Chelsea,1,0", "Date"},
+		{"home score not a number", "10/08/2024,Arsenal,Chelsea,x,0", "FTHG"},
+		{"negative away score", "10/08/2024,Arsenal,Chelsea,1,-1", "FTAG"},
+		{"short row", "10/08/2024,Arsenal,Chelsea,1", "FTAG"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &types.SeasonImportReport{}
+			results, err := parseResults(strings.NewReader(header+"10/08/2024,Everton,Fulham,0,0\n"+tt.row+"\n"), report)
+			if err != nil {
+				t.Fatalf("parseResults: %v", err)
+			}
+
+			if len(results) != 1 {
+				t.Errorf("parseResults returned %d results, want the valid row only", len(results))
+			}
+			if len(report.Errors) != 1 || report.Errors[0].Row != 3 || report.Errors[0].Field != tt.field {
+				t.Errorf("errors = %+v, want one on row 3 for %s", report.Errors, tt.field)
+			}
+		})
+	}
+}
+
+func TestParseResultsRejectsFile(t *testing.T) {
+	for _, input := range []string{"", "Date,HomeTeam,AwayTeam,FTHG\n10/08/2024,Arsenal,Chelsea,1\n"} {
+		if _, err := parseResults(strings.NewReader(input), &types.SeasonImportReport{}); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("parseResults(%q) = %v, want %v", input, err, ErrInvalidFile)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{"10/08/2024", time.Date(2024, time.August, 10, 0, 0, 0, 0, time.UTC), true},
+		{"10/08/24", time.Date(2024, time.August, 10, 0, 0, 0, 0, time.UTC), true},
+		{"31/12/99", time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC), true},
+		{"08/31/2024", time.Time{}, false},
+		{"", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDate(tt.value)
+		if (err == nil) != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("parseDate(%q) = %v, %v, want %v, ok %v", tt.value, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDerivedStrengths(t *testing.T) {
+	strengths, names := derivedStrengths(parseFile(t))
+
+	if want := []string{"Arsenal", "Chelsea", "Liverpool", "Everton"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v in order of first appearance", names, want)
+	}
+
+	// 30 plus 70 times the share of the 3 points per game taken
+	want := map[string]int{
+		"Arsenal":   84, // 7 points in 3 games
+		"Chelsea":   53, // 3 points in 3 games
+		"Liverpool": 61, // 4 points in 3 games
+		"Everton":   46, // 2 points in 3 games
+	}
+	if !reflect.DeepEqual(strengths, want) {
+		t.Errorf("strengths = %v, want %v", strengths, want)
+	}
+}
+
+func TestBuildFixture(t *testing.T) {
+	results := []result{
+		{homeTeam: "Arsenal", awayTeam: "Chelsea"},
+		{homeTeam: "Liverpool", awayTeam: "Everton"},
+		// Chelsea's game of the second weekend was postponed
+		{homeTeam: "Everton", awayTeam: "Arsenal"},
+		{homeTeam: "Arsenal", awayTeam: "Liverpool"},
+		{homeTeam: "Chelsea", awayTeam: "Liverpool"},
+		{homeTeam: "Chelsea", awayTeam: "Everton"},
+	}
+	teamIDs := map[string]int{"Arsenal": 1, "Chelsea": 2, "Liverpool": 3, "Everton": 4}
+
+	matches, weeks := buildFixture(results, teamIDs)
+
+	// a match goes in the week after the latest either team has played
+	wantWeeks := []int{1, 1, 2, 3, 4, 5}
+	for i, match := range matches {
+		if match.Week != wantWeeks[i] {
+			t.Errorf("match %d (%s v %s) in week %d, want %d", i, results[i].homeTeam, results[i].awayTeam, match.Week, wantWeeks[i])
+		}
+	}
+	if weeks != 5 {
+		t.Errorf("weeks = %d, want 5", weeks)
+	}
+	if matches[4].Team1ID != 2 || matches[4].Team2ID != 3 || matches[4].Played {
+		t.Errorf("fifth match = %+v, want Chelsea hosting Liverpool, unplayed", matches[4])
+	}
+}
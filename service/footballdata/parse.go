@@ -0,0 +1,137 @@
+package footballdata
+
+import (
+	"encoding/csv"
+	"fmt"
+	"football-simulation/types"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidFile is returned when the season file cannot be read at all.
+var ErrInvalidFile = types.NewError(types.ErrorKindValidation, "invalid_file", "invalid football-data.co.uk file")
+
+// dateLayouts are the date formats used by football-data.co.uk over the
+// years; older seasons use two-digit years.
+var dateLayouts = []string{"02/01/2006", "02/01/06"}
+
+// requiredColumns are the football-data.co.uk columns the importer reads; all
+// the betting and match statistics columns are ignored.
+var requiredColumns = []string{"Date", "HomeTeam", "AwayTeam", "FTHG", "FTAG"}
+
+type result struct {
+	row       int
+	date      time.Time
+	homeTeam  string
+	awayTeam  string
+	homeGoals int
+	awayGoals int
+}
+
+// parseResults reads a football-data.co.uk season CSV. Rows that cannot be
+// parsed are reported in report.Errors and left out of the returned results.
+func parseResults(r io.Reader, report *types.SeasonImportReport) ([]result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not read the header: %v", ErrInvalidFile, err)
+	}
+
+	columns := make(map[string]int)
+	for i, column := range header {
+		// the first header cell of some files starts with a byte order mark
+		columns[strings.TrimPrefix(strings.TrimSpace(column), "\ufeff")] = i
+	}
+
+	for _, required := range requiredColumns {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: the header has no %q column", ErrInvalidFile, required)
+		}
+	}
+
+	results := make([]result, 0)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, types.ImportError{Row: line, Message: err.Error()})
+			continue
+		}
+
+		if isBlank(record) {
+			continue
+		}
+
+		result, importErr := parseRecord(line, record, columns)
+		if importErr != nil {
+			report.Errors = append(report.Errors, *importErr)
+			continue
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func parseRecord(line int, record []string, columns map[string]int) (result, *types.ImportError) {
+	field := func(column string) string {
+		i := columns[column]
+		if i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	parsed := result{row: line, homeTeam: field("HomeTeam"), awayTeam: field("AwayTeam")}
+
+	if parsed.homeTeam == "" || parsed.awayTeam == "" {
+		return result{}, &types.ImportError{Row: line, Field: "HomeTeam", Message: "both team names are required"}
+	}
+
+	if parsed.homeTeam == parsed.awayTeam {
+		return result{}, &types.ImportError{Row: line, Field: "AwayTeam", Message: "a team cannot play itself"}
+	}
+
+	date, err := parseDate(field("Date"))
+	if err != nil {
+		return result{}, &types.ImportError{Row: line, Field: "Date", Message: err.Error()}
+	}
+	parsed.date = date
+
+	parsed.homeGoals, err = strconv.Atoi(field("FTHG"))
+	if err != nil || parsed.homeGoals < 0 {
+		return result{}, &types.ImportError{Row: line, Field: "FTHG", Message: fmt.Sprintf("%q is not a score", field("FTHG"))}
+	}
+
+	parsed.awayGoals, err = strconv.Atoi(field("FTAG"))
+	if err != nil || parsed.awayGoals < 0 {
+		return result{}, &types.ImportError{Row: line, Field: "FTAG", Message: fmt.Sprintf("%q is not a score", field("FTAG"))}
+	}
+
+	return parsed, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a dd/mm/yy or dd/mm/yyyy date", value)
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
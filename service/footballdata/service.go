@@ -0,0 +1,324 @@
+package footballdata
+
+import (
+	"errors"
+	"fmt"
+	"football-simulation/service/league"
+	"football-simulation/types"
+	"io"
+	"math"
+	"sort"
+)
+
+// ErrSeasonInProgress is returned when a fixture already exists, since the
+// imported season replaces the whole fixture.
+var ErrSeasonInProgress = types.NewError(types.ErrorKindConflict, "season_in_progress", "a fixture already exists, restart the league before importing a season")
+
+// errRollbackImport aborts the import transaction for dry runs; it never
+// reaches the caller.
+var errRollbackImport = errors.New("rollback import")
+
+var importChange = types.MatchChange{Actor: types.ImportActor, Reason: "imported from football-data.co.uk"}
+
+type Service struct {
+	leagueStore     types.LeagueStore
+	simulationStore types.SimulationStore
+	teamService     types.TeamService
+	eventStore      types.LeagueEventStore
+	transactor      types.Transactor
+}
+
+func NewService(leagueStore types.LeagueStore, simulationStore types.SimulationStore, teamService types.TeamService, eventStore types.LeagueEventStore, transactor types.Transactor) *Service {
+	return &Service{
+		leagueStore:     leagueStore,
+		simulationStore: simulationStore,
+		teamService:     teamService,
+		eventStore:      eventStore,
+		transactor:      transactor,
+	}
+}
+
+// ImportSeason loads a completed season from a football-data.co.uk CSV into
+// the league: missing teams are created, every result is stored as a played
+// match and the standings are built from them. Matchdays are not part of the
+// format, so matches are ordered by date and each one is placed in the week
+// after the latest one either team has already played in.
+func (s *Service) ImportSeason(r io.Reader, options types.SeasonImportOptions) (*types.SeasonImportReport, error) {
+	report := &types.SeasonImportReport{
+		DryRun:       options.DryRun,
+		TeamsCreated: []string{},
+		TeamsUpdated: []string{},
+	}
+
+	results, err := parseResults(r, report)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("%w: the file has no results", ErrInvalidFile)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].date.Before(results[j].date)
+	})
+
+	err = s.transactor.WithinTransaction(func(tx types.DBTX) error {
+		txService := &Service{
+			leagueStore:     s.leagueStore.WithTx(tx),
+			simulationStore: s.simulationStore.WithTx(tx),
+			teamService:     s.teamService.WithTx(tx),
+			eventStore:      s.eventStore.WithTx(tx),
+		}
+
+		if err := txService.importSeason(results, options, report); err != nil {
+			return err
+		}
+
+		if options.DryRun {
+			return errRollbackImport
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollbackImport) {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (s *Service) importSeason(results []result, options types.SeasonImportOptions, report *types.SeasonImportReport) error {
+	leagueInfo, err := s.leagueStore.GetLeagueInfo()
+	if err != nil {
+		return err
+	}
+
+	locked, err := s.leagueStore.TryLockLeague(leagueInfo.ID)
+	if err != nil {
+		return err
+	}
+
+	if !locked {
+		return league.ErrLeagueBusy
+	}
+
+	existingMatches, err := s.leagueStore.GetAllMatches()
+	if err != nil {
+		return err
+	}
+
+	if len(existingMatches) > 0 {
+		return ErrSeasonInProgress
+	}
+
+	teamIDs, err := s.importTeams(results, options, report)
+	if err != nil {
+		return err
+	}
+
+	if err := s.teamService.ResetTeams(); err != nil {
+		return err
+	}
+
+	fixture, weeks := buildFixture(results, teamIDs)
+
+	savedFixture, err := s.simulationStore.SaveFixture(fixture)
+	if err != nil {
+		return err
+	}
+
+	err = s.eventStore.AppendEvent(types.EventFixtureGenerated, types.FixtureGeneratedEvent{
+		CurrentWeek: 1,
+		TotalWeeks:  weeks,
+		Matches:     savedFixture,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, match := range savedFixture {
+		if err := s.playMatch(match, results[i]); err != nil {
+			return err
+		}
+	}
+
+	standings, err := s.leagueStore.GetStandings()
+	if err != nil {
+		return err
+	}
+
+	name := leagueInfo.Name
+	if options.LeagueName != "" {
+		name = options.LeagueName
+	}
+
+	err = s.leagueStore.UpdateLeague(types.League{
+		ID:               leagueInfo.ID,
+		Name:             name,
+		CurrentWeek:      weeks + 1,
+		TotalWeeks:       weeks,
+		ChampionTeamName: standings[0].Name,
+		Season:           leagueInfo.Season,
+	})
+	if err != nil {
+		return err
+	}
+
+	report.Matches = len(savedFixture)
+	report.Weeks = weeks
+	report.Champion = standings[0].Name
+
+	return s.eventStore.AppendEvent(types.EventWeekAdvanced, types.WeekAdvancedEvent{Week: weeks + 1})
+}
+
+// importTeams creates the teams of the season that do not exist yet, using a
+// strength derived from their points per game, and returns the id of every
+// team by name.
+func (s *Service) importTeams(results []result, options types.SeasonImportOptions, report *types.SeasonImportReport) (map[string]int, error) {
+	strengths, names := derivedStrengths(results)
+	teamIDs := make(map[string]int)
+
+	for _, name := range names {
+		team, err := s.teamService.GetTeamByName(name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case team.ID == 0:
+			team, err = s.teamService.CreateTeam(types.CreateTeamRequest{Name: name, Strength: strengths[name]})
+			if err != nil {
+				return nil, err
+			}
+			report.TeamsCreated = append(report.TeamsCreated, name)
+
+		case options.UpdateStrength && team.Strength != strengths[name]:
+			strength := strengths[name]
+			team, err = s.teamService.PatchTeam(team.ID, types.PatchTeamRequest{Strength: &strength})
+			if err != nil {
+				return nil, err
+			}
+			report.TeamsUpdated = append(report.TeamsUpdated, name)
+		}
+
+		teamIDs[name] = team.ID
+	}
+
+	teams, err := s.teamService.GetTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, team := range teams {
+		if _, ok := teamIDs[team.Name]; !ok {
+			report.OtherTeams = append(report.OtherTeams, team.Name)
+		}
+	}
+
+	return teamIDs, nil
+}
+
+func (s *Service) playMatch(match types.Match, result result) error {
+	team1, err := s.teamService.GetTeamByID(match.Team1ID)
+	if err != nil {
+		return err
+	}
+
+	team2, err := s.teamService.GetTeamByID(match.Team2ID)
+	if err != nil {
+		return err
+	}
+
+	before := match
+	match.Team1Score = result.homeGoals
+	match.Team2Score = result.awayGoals
+	match.Played = true
+
+	if err := s.leagueStore.SaveMatchResult(match); err != nil {
+		return err
+	}
+
+	if err := s.teamService.UpdateTeamStats(*team1, *team2, match.Team1Score, match.Team2Score, false); err != nil {
+		return err
+	}
+
+	event := types.MatchEvent{
+		MatchID:          match.ID,
+		BeforeTeam1Score: before.Team1Score,
+		BeforeTeam2Score: before.Team2Score,
+		BeforePlayed:     before.Played,
+		AfterTeam1Score:  match.Team1Score,
+		AfterTeam2Score:  match.Team2Score,
+		AfterPlayed:      match.Played,
+		Actor:            importChange.Actor,
+		Reason:           importChange.Reason,
+	}
+
+	if err := s.leagueStore.SaveMatchEvent(event); err != nil {
+		return err
+	}
+
+	return s.eventStore.AppendEvent(types.EventMatchPlayed, event)
+}
+
+// buildFixture turns the results, ordered by date, into unplayed matches and
+// returns them with the number of weeks they span.
+func buildFixture(results []result, teamIDs map[string]int) ([]types.Match, int) {
+	played := make(map[string]int)
+	matches := make([]types.Match, 0, len(results))
+	weeks := 0
+
+	for _, result := range results {
+		week := max(played[result.homeTeam], played[result.awayTeam]) + 1
+		played[result.homeTeam] = week
+		played[result.awayTeam] = week
+		weeks = max(weeks, week)
+
+		matches = append(matches, types.Match{
+			Week:    week,
+			Team1ID: teamIDs[result.homeTeam],
+			Team2ID: teamIDs[result.awayTeam],
+		})
+	}
+
+	return matches, weeks
+}
+
+// derivedStrengths maps each team's points per game onto the 30-100 strength
+// range and returns the team names in order of first appearance.
+func derivedStrengths(results []result) (map[string]int, []string) {
+	points := make(map[string]int)
+	games := make(map[string]int)
+	names := make([]string, 0)
+
+	for _, result := range results {
+		for _, name := range []string{result.homeTeam, result.awayTeam} {
+			if _, ok := games[name]; !ok {
+				names = append(names, name)
+			}
+			games[name]++
+		}
+
+		switch {
+		case result.homeGoals > result.awayGoals:
+			points[result.homeTeam] += 3
+		case result.homeGoals < result.awayGoals:
+			points[result.awayTeam] += 3
+		default:
+			points[result.homeTeam]++
+			points[result.awayTeam]++
+		}
+	}
+
+	strengths := make(map[string]int)
+	for _, name := range names {
+		pointsPerGame := float64(points[name]) / float64(games[name])
+		strengths[name] = int(math.Round(30 + 70*pointsPerGame/3))
+	}
+
+	return strengths, names
+}
@@ -0,0 +1,187 @@
+package job_test
+
+import (
+	"context"
+	"encoding/json"
+	"football-simulation/database/memory"
+	"football-simulation/service/job"
+	"football-simulation/service/league"
+	"football-simulation/types"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLeague plays a league of totalWeeks weeks and runs predictions that
+// take a millisecond per simulation.
+type fakeLeague struct {
+	types.LeagueService
+
+	mu          sync.Mutex
+	week        int
+	totalWeeks  int
+	startPlayed chan struct{}
+}
+
+func (f *fakeLeague) NextWeek(leagueVersion int) ([]types.Match, *types.Team, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.startPlayed != nil {
+		<-f.startPlayed
+	}
+
+	if f.week == f.totalWeeks {
+		return nil, nil, league.ErrLeagueFinished
+	}
+
+	f.week++
+	if f.week == f.totalWeeks {
+		return nil, &types.Team{ID: 1, Name: "Arsenal"}, nil
+	}
+	return nil, nil, nil
+}
+
+func (f *fakeLeague) GetLeague() (types.League, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return types.League{ID: 1, CurrentWeek: f.week + 1, TotalWeeks: f.totalWeeks}, nil
+}
+
+func (f *fakeLeague) GetAllMatches() ([]types.MatchResult, error) {
+	return []types.MatchResult{{ID: 1, Played: true}}, nil
+}
+
+func (f *fakeLeague) RunPredictions(ctx context.Context, simulations int, progress func(done int)) ([]types.Prediction, error) {
+	for i := 0; i < simulations; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		time.Sleep(time.Millisecond)
+		progress(i + 1)
+	}
+	return []types.Prediction{{TeamID: 1, TeamName: "Arsenal", ChampionshipOdds: 100}}, nil
+}
+
+func waitForJob(t *testing.T, service *job.Service, id int, done func(job *types.Job) bool) *types.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		current, err := service.GetJob(id)
+		if err != nil {
+			t.Fatalf("GetJob: %v", err)
+		}
+		if done(current) {
+			return current
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("job %d did not reach the expected state", id)
+	return nil
+}
+
+func finished(job *types.Job) bool {
+	return job.FinishedAt != nil
+}
+
+func TestPlayAllJob(t *testing.T) {
+	service := job.NewService(memory.NewJobStore(memory.NewDB()), &fakeLeague{totalWeeks: 6})
+
+	submitted, err := service.Submit(types.CreateJobRequest{Type: types.JobPlayAll})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if submitted.Status != types.JobQueued {
+		t.Errorf("submitted job status = %s, want queued", submitted.Status)
+	}
+
+	done := waitForJob(t, service, submitted.ID, finished)
+	if done.Status != types.JobSucceeded {
+		t.Fatalf("job = %+v, want it succeeded", done)
+	}
+	if done.Progress != (types.JobProgress{Done: 6, Total: 6, Unit: "weeks"}) {
+		t.Errorf("progress = %+v, want 6 of 6 weeks", done.Progress)
+	}
+
+	var result types.PlayAllResult
+	if err := json.Unmarshal(done.Result, &result); err != nil {
+		t.Fatalf("result %s: %v", done.Result, err)
+	}
+	if result.Champion == nil || result.Champion.Name != "Arsenal" || len(result.PlayedMatches) != 1 {
+		t.Errorf("result = %s, want the matches and the champion", done.Result)
+	}
+
+	// the league is finished now, so another run fails with its error code
+	again, err := service.Submit(types.CreateJobRequest{Type: types.JobPlayAll})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	failed := waitForJob(t, service, again.ID, finished)
+	if failed.Status != types.JobFailed || failed.ErrorCode != "league_finished" {
+		t.Errorf("job = %+v, want it failed with league_finished", failed)
+	}
+}
+
+func TestPredictionsJobProgressAndCancel(t *testing.T) {
+	service := job.NewService(memory.NewJobStore(memory.NewDB()), &fakeLeague{totalWeeks: 6})
+
+	submitted, err := service.Submit(types.CreateJobRequest{Type: types.JobPredictions, Simulations: 100000})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	running := waitForJob(t, service, submitted.ID, func(job *types.Job) bool {
+		return job.Progress.Done > 0
+	})
+	if running.Status != types.JobRunning || running.Progress.Total != 100000 || running.Progress.Unit != "simulations" {
+		t.Errorf("running job = %+v, want progress out of 100000 simulations", running)
+	}
+
+	if _, err := service.Cancel(submitted.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	cancelled := waitForJob(t, service, submitted.ID, finished)
+	if cancelled.Status != types.JobCancelled || !cancelled.CancelRequested || cancelled.Result != nil {
+		t.Errorf("job = %+v, want it cancelled without a result", cancelled)
+	}
+
+	if _, err := service.Cancel(submitted.ID); err == nil {
+		t.Error("cancelling a finished job succeeded")
+	}
+}
+
+func TestQueuedJobCancel(t *testing.T) {
+	fake := &fakeLeague{totalWeeks: 6, startPlayed: make(chan struct{})}
+	service := job.NewService(memory.NewJobStore(memory.NewDB()), fake)
+
+	first, err := service.Submit(types.CreateJobRequest{Type: types.JobPlayAll})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	waitForJob(t, service, first.ID, func(job *types.Job) bool { return job.Status == types.JobRunning })
+
+	queued, err := service.Submit(types.CreateJobRequest{Type: types.JobPredictions, Simulations: 10})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	if _, err := service.Cancel(queued.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	cancelled := waitForJob(t, service, queued.ID, finished)
+	if cancelled.Status != types.JobCancelled || cancelled.StartedAt != nil {
+		t.Errorf("queued job = %+v, want it cancelled before it started", cancelled)
+	}
+
+	close(fake.startPlayed)
+	if done := waitForJob(t, service, first.ID, finished); done.Status != types.JobSucceeded {
+		t.Errorf("running job = %+v, want it to finish", done)
+	}
+}
@@ -0,0 +1,84 @@
+package job
+
+import (
+	"fmt"
+	"football-simulation/types"
+	"football-simulation/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	service types.JobService
+}
+
+func NewHandler(service types.JobService) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/jobs", h.handleSubmitJob).Methods("POST")
+	router.HandleFunc("/jobs", h.handleGetJobs).Methods("GET")
+	router.HandleFunc("/jobs/{id}", h.handleGetJob).Methods("GET")
+	router.HandleFunc("/jobs/{id}/cancel", h.handleCancelJob).Methods("POST")
+}
+
+func (h *Handler) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateJobRequest
+	if !utils.ParseAndValidate(w, r, &req) {
+		return
+	}
+
+	job, err := h.service.Submit(req)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("%s/%d", r.URL.Path, job.ID))
+	utils.WriteSuccess(w, http.StatusAccepted, job)
+}
+
+func (h *Handler) handleGetJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.service.GetJobs()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, jobs)
+}
+
+func (h *Handler) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	job, err := h.service.GetJob(id)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, job)
+}
+
+func (h *Handler) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	job, err := h.service.Cancel(id)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, job)
+}
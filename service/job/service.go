@@ -0,0 +1,312 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"football-simulation/service/league"
+	"football-simulation/types"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// listLimit is how many of the newest jobs GetJobs returns.
+	listLimit = 50
+	// staleAfter is how long a job may go without a heartbeat before it is
+	// considered interrupted. Run refreshes heartbeats every second.
+	staleAfter = 30 * time.Second
+	// progressInterval throttles how often the progress of a prediction run
+	// is stored.
+	progressInterval = 250 * time.Millisecond
+)
+
+var (
+	ErrJobNotFound = types.NewError(types.ErrorKindNotFound, "job_not_found", "job not found")
+	ErrJobFinished = types.NewError(types.ErrorKindConflict, "job_finished", "the job has already finished")
+)
+
+// Service runs jobs in the background of the server that accepted them. Jobs
+// are stored, so every server can report on them and cancel them.
+type Service struct {
+	store         types.JobStore
+	leagueService types.LeagueService
+
+	// run makes the jobs of this server run one at a time, as they all use
+	// the league. A job holds it by sending to it.
+	run chan struct{}
+
+	mu sync.Mutex
+	// active holds the cancel functions of the unfinished jobs of this server.
+	active map[int]context.CancelFunc
+}
+
+func NewService(store types.JobStore, leagueService types.LeagueService) *Service {
+	return &Service{
+		store:         store,
+		leagueService: leagueService,
+		run:           make(chan struct{}, 1),
+		active:        make(map[int]context.CancelFunc),
+	}
+}
+
+// Submit stores a job and starts it in the background. It is queued until
+// the jobs submitted before it have finished.
+func (s *Service) Submit(request types.CreateJobRequest) (*types.Job, error) {
+	job := types.Job{Type: request.Type, Status: types.JobQueued, HeartbeatAt: now()}
+
+	switch request.Type {
+	case types.JobPlayAll:
+		job.Progress.Unit = "weeks"
+	case types.JobPredictions:
+		job.Simulations = request.Simulations
+		if job.Simulations == 0 {
+			job.Simulations = league.DefaultSimulations
+		}
+		job.Progress = types.JobProgress{Total: job.Simulations, Unit: "simulations"}
+	}
+
+	created, err := s.store.CreateJob(job)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.active[created.ID] = cancel
+	s.mu.Unlock()
+
+	go s.execute(ctx, *created)
+
+	return created, nil
+}
+
+func (s *Service) GetJob(id int) (*types.Job, error) {
+	job, err := s.store.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.ID == 0 {
+		return nil, ErrJobNotFound.Errorf("job %d not found", id)
+	}
+	return job, nil
+}
+
+// GetJobs returns the newest jobs without their results, which are only
+// returned by GetJob.
+func (s *Service) GetJobs() ([]types.Job, error) {
+	jobs, err := s.store.GetJobs(listLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range jobs {
+		jobs[i].Result = nil
+	}
+	return jobs, nil
+}
+
+// Cancel stops a queued or running job. A job of another server stops when
+// that server next checks for cancellations. Weeks a play_all job has
+// already played stay played.
+func (s *Service) Cancel(id int) (*types.Job, error) {
+	job, err := s.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status != types.JobQueued && job.Status != types.JobRunning {
+		return nil, ErrJobFinished.Errorf("job %d has already %s", id, job.Status)
+	}
+
+	if err := s.store.RequestCancel(id); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if cancel, ok := s.active[id]; ok {
+		cancel()
+	}
+	s.mu.Unlock()
+
+	return s.GetJob(id)
+}
+
+// Run keeps the jobs of this server alive until ctx is done: every second it
+// refreshes their heartbeats, stops the ones cancelled through another
+// server and fails the jobs of servers that have stopped.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := s.tick(); err != nil {
+			log.Printf("jobs: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) tick() error {
+	s.mu.Lock()
+	ids := make([]int, 0, len(s.active))
+	for id := range s.active {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+
+	current := now()
+	if err := s.store.Heartbeat(ids, current); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		job, err := s.store.GetJob(id)
+		if err != nil {
+			return err
+		}
+
+		if job.CancelRequested {
+			s.mu.Lock()
+			if cancel, ok := s.active[id]; ok {
+				cancel()
+			}
+			s.mu.Unlock()
+		}
+	}
+
+	return s.store.FailStaleJobs(current.Add(-staleAfter), "the server running the job stopped before it finished")
+}
+
+func (s *Service) execute(ctx context.Context, job types.Job) {
+	defer func() {
+		s.mu.Lock()
+		if cancel, ok := s.active[job.ID]; ok {
+			cancel()
+			delete(s.active, job.ID)
+		}
+		s.mu.Unlock()
+	}()
+
+	var result any
+	var err error
+
+	select {
+	case s.run <- struct{}{}:
+		defer func() { <-s.run }()
+		err = ctx.Err()
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err == nil {
+		started := now()
+		job.Status = types.JobRunning
+		job.StartedAt = &started
+		if err := s.store.UpdateJob(job); err != nil {
+			log.Printf("job %d: %v", job.ID, err)
+		}
+
+		switch job.Type {
+		case types.JobPlayAll:
+			result, err = s.playAll(ctx, &job)
+		case types.JobPredictions:
+			result, err = s.predict(ctx, &job)
+		}
+	}
+
+	finished := now()
+	job.FinishedAt = &finished
+
+	switch {
+	case err == nil:
+		job.Status = types.JobSucceeded
+		job.Result, err = json.Marshal(result)
+		if err != nil {
+			job.Status = types.JobFailed
+			job.Error = err.Error()
+			job.ErrorCode = "internal_error"
+		}
+	case errors.Is(err, context.Canceled):
+		job.Status = types.JobCancelled
+	default:
+		job.Status = types.JobFailed
+		job.Error = err.Error()
+		job.ErrorCode = "internal_error"
+
+		var domainErr *types.Error
+		if errors.As(err, &domainErr) {
+			job.ErrorCode = domainErr.Code
+		}
+	}
+
+	if err := s.store.UpdateJob(job); err != nil {
+		log.Printf("job %d: %v", job.ID, err)
+	}
+}
+
+// playAll plays the remaining weeks one at a time, so progress is visible
+// and the job can be cancelled between weeks. Unlike PlayAll, every week is
+// its own transaction.
+func (s *Service) playAll(ctx context.Context, job *types.Job) (*types.PlayAllResult, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		_, champion, err := s.leagueService.NextWeek(0)
+		if err != nil {
+			return nil, err
+		}
+
+		leagueInfo, err := s.leagueService.GetLeague()
+		if err != nil {
+			return nil, err
+		}
+
+		job.Progress.Done++
+		job.Progress.Total = job.Progress.Done
+		if champion == nil {
+			job.Progress.Total += leagueInfo.TotalWeeks - leagueInfo.CurrentWeek + 1
+		}
+		if err := s.store.UpdateJob(*job); err != nil {
+			return nil, err
+		}
+
+		if champion != nil {
+			matches, err := s.leagueService.GetAllMatches()
+			if err != nil {
+				return nil, err
+			}
+			return &types.PlayAllResult{PlayedMatches: matches, Champion: champion}, nil
+		}
+	}
+}
+
+func (s *Service) predict(ctx context.Context, job *types.Job) ([]types.Prediction, error) {
+	stored := time.Now()
+	progress := func(done int) {
+		job.Progress.Done = done
+		if time.Since(stored) < progressInterval {
+			return
+		}
+
+		stored = time.Now()
+		if err := s.store.UpdateJob(*job); err != nil {
+			log.Printf("job %d: %v", job.ID, err)
+		}
+	}
+
+	return s.leagueService.RunPredictions(ctx, job.Simulations, progress)
+}
+
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
@@ -0,0 +1,156 @@
+package job
+
+import (
+	"database/sql"
+	"football-simulation/types"
+	"time"
+)
+
+type Store struct {
+	db types.DBTX
+}
+
+func NewStore(db types.DBTX) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) WithTx(tx types.DBTX) types.JobStore {
+	return &Store{db: tx}
+}
+
+const jobColumns = `id, type, status, simulations, progress_done, progress_total, progress_unit, result, error, error_code,
+	cancel_requested, created_at, started_at, finished_at, heartbeat_at`
+
+func (s *Store) CreateJob(job types.Job) (*types.Job, error) {
+	err := s.db.QueryRow(`INSERT INTO jobs (type, status, simulations, progress_total, progress_unit, heartbeat_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`,
+		job.Type, job.Status, job.Simulations, job.Progress.Total, job.Progress.Unit, job.HeartbeatAt).Scan(&job.ID, &job.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (s *Store) GetJob(id int) (*types.Job, error) {
+	rows, err := s.db.Query("SELECT "+jobColumns+" FROM jobs WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs, err := scanRowsIntoJobs(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(jobs) == 0 {
+		return &types.Job{}, nil
+	}
+	return &jobs[0], nil
+}
+
+// GetJobs returns the newest jobs first.
+func (s *Store) GetJobs(limit int) ([]types.Job, error) {
+	rows, err := s.db.Query("SELECT "+jobColumns+" FROM jobs ORDER BY id DESC LIMIT $1", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRowsIntoJobs(rows)
+}
+
+// UpdateJob stores the state of a job. It leaves cancel_requested alone, so
+// a cancellation requested meanwhile is not lost.
+func (s *Store) UpdateJob(job types.Job) error {
+	var result any
+	if job.Result != nil {
+		result = []byte(job.Result)
+	}
+
+	_, err := s.db.Exec(`UPDATE jobs SET status = $1, progress_done = $2, progress_total = $3, result = $4, error = $5,
+		error_code = $6, started_at = $7, finished_at = $8 WHERE id = $9`,
+		job.Status, job.Progress.Done, job.Progress.Total, result, job.Error,
+		job.ErrorCode, job.StartedAt, job.FinishedAt, job.ID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) RequestCancel(id int) error {
+	_, err := s.db.Exec("UPDATE jobs SET cancel_requested = $1 WHERE id = $2 AND status IN ($3, $4)",
+		true, id, types.JobQueued, types.JobRunning)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) Heartbeat(ids []int, now time.Time) error {
+	for _, id := range ids {
+		if _, err := s.db.Exec("UPDATE jobs SET heartbeat_at = $1 WHERE id = $2", now, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FailStaleJobs fails the unfinished jobs whose last heartbeat is older than
+// before: the server running them has stopped.
+func (s *Store) FailStaleJobs(before time.Time, message string) error {
+	_, err := s.db.Exec(`UPDATE jobs SET status = $1, error = $2, error_code = $3, finished_at = $4
+		WHERE status IN ($5, $6) AND heartbeat_at < $7`,
+		types.JobFailed, message, "job_interrupted", time.Now().UTC(), types.JobQueued, types.JobRunning, before)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func scanRowsIntoJobs(rows *sql.Rows) ([]types.Job, error) {
+	jobs := make([]types.Job, 0)
+
+	for rows.Next() {
+		var job types.Job
+		var result []byte
+		var startedAt, finishedAt sql.NullTime
+		err := rows.Scan(
+			&job.ID,
+			&job.Type,
+			&job.Status,
+			&job.Simulations,
+			&job.Progress.Done,
+			&job.Progress.Total,
+			&job.Progress.Unit,
+			&result,
+			&job.Error,
+			&job.ErrorCode,
+			&job.CancelRequested,
+			&job.CreatedAt,
+			&startedAt,
+			&finishedAt,
+			&job.HeartbeatAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if result != nil {
+			job.Result = result
+		}
+		if startedAt.Valid {
+			job.StartedAt = &startedAt.Time
+		}
+		if finishedAt.Valid {
+			job.FinishedAt = &finishedAt.Time
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
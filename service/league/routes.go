@@ -1,9 +1,12 @@
 package league
 
 import (
+	"errors"
 	"fmt"
+	"football-simulation/service/auth"
 	"football-simulation/types"
 	"football-simulation/utils"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -19,6 +22,7 @@ func NewHandler(service types.LeagueService) *Handler {
 }
 
 func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/league", h.handleGetLeague).Methods("GET")
 	router.HandleFunc("/league/nextweek", h.handleNextWeek).Methods("POST")
 	router.HandleFunc("/league/playall", h.handlePlayAll).Methods("POST")
 	router.HandleFunc("/league/restart", h.handleRestartLeague).Methods("POST")
@@ -26,46 +30,68 @@ func (h *Handler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/league/weekresults", h.handleGetWeekResults).Methods("GET")
 	router.HandleFunc("/league/matches", h.handleGetAllMatches).Methods("GET")
 	router.HandleFunc("/league/matches/{week}", h.handleGetMatchesByWeek).Methods("GET")
+	router.HandleFunc("/league/match/{id}", h.handleGetMatch).Methods("GET")
 	router.HandleFunc("/league/match/{id}", h.handleUpdateMatch).Methods("PUT")
+	router.HandleFunc("/league/match/{id}/history", h.handleGetMatchHistory).Methods("GET")
+	router.HandleFunc("/league/match/{id}/undo", h.handleUndoMatchChange).Methods("POST")
 	router.HandleFunc("/league/predictions", h.handleGetPredictions).Methods("GET")
 }
 
+func (h *Handler) handleGetLeague(w http.ResponseWriter, r *http.Request) {
+	league, err := h.service.GetLeague()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.SetETag(w, league.Version)
+	utils.WriteSuccess(w, http.StatusOK, league)
+}
+
 func (h *Handler) handleNextWeek(w http.ResponseWriter, r *http.Request) {
-	playedMatches, champion, err := h.service.NextWeek()
+	leagueVersion, err := utils.ParseIfMatch(r)
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
+		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"status":        "success",
-		"message":       "Next week played successfully",
-		"playedMatches": playedMatches,
-		"champion":      nil,
+	playedMatches, champion, err := h.service.NextWeek(leagueVersion)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
 	}
 
-	if champion != nil {
-		response["champion"] = champion
+	if !h.setLeagueETag(w) {
+		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK, response)
+	utils.WriteSuccess(w, http.StatusOK, types.WeekPlayResult{
+		PlayedMatches: playedMatches,
+		Champion:      champion,
+	})
 }
 
 func (h *Handler) handlePlayAll(w http.ResponseWriter, r *http.Request) {
-	playedMatches, champion, err := h.service.PlayAll()
+	leagueVersion, err := utils.ParseIfMatch(r)
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
+		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"status":        "success",
-		"message":       "Next week played successfully",
-		"playedMatches": playedMatches,
-		"champion":      champion,
+	playedMatches, champion, err := h.service.PlayAll(leagueVersion)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	if !h.setLeagueETag(w) {
+		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK, response)
+	utils.WriteSuccess(w, http.StatusOK, types.PlayAllResult{
+		PlayedMatches: playedMatches,
+		Champion:      champion,
+	})
 }
 
 func (h *Handler) handleGetMatchesByWeek(w http.ResponseWriter, r *http.Request) {
@@ -78,7 +104,7 @@ func (h *Handler) handleGetMatchesByWeek(w http.ResponseWriter, r *http.Request)
 
 	matches, err := h.service.GetMatchesByWeek(week)
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
+		utils.WriteServiceError(w, err)
 		return
 	}
 
@@ -87,7 +113,7 @@ func (h *Handler) handleGetMatchesByWeek(w http.ResponseWriter, r *http.Request)
 func (h *Handler) handleGetWeekResults(w http.ResponseWriter, r *http.Request) {
 	weekResults, err := h.service.GetWeekResults()
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
+		utils.WriteServiceError(w, err)
 		return
 	}
 
@@ -97,7 +123,7 @@ func (h *Handler) handleGetWeekResults(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) handleGetAllMatches(w http.ResponseWriter, r *http.Request) {
 	matches, err := h.service.GetAllMatches()
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
+		utils.WriteServiceError(w, err)
 		return
 	}
 
@@ -106,44 +132,133 @@ func (h *Handler) handleGetAllMatches(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) handleUpdateMatch(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	idStr, ok := vars["id"]
-	if !ok {
-		utils.WriteError(w, http.StatusBadRequest, nil)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, nil)
+	var req types.UpdateMatchRequest
+	if !utils.ParseAndValidate(w, r, &req) {
 		return
 	}
 
-	var req types.UpdateMatchRequest
-	if err := utils.ParseJSON(r, &req); err != nil {
+	matchVersion, err := utils.ParseIfMatch(r)
+	if err != nil {
 		utils.WriteError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	fmt.Println(req)
 	match := types.Match{
 		ID:         id,
 		Team1Score: req.Team1Score,
 		Team2Score: req.Team2Score,
+		Version:    matchVersion,
+	}
+
+	change := recordedChange(r, types.MatchChange{Actor: req.Actor, Reason: req.Reason})
+	if err := h.service.UpdateMatch(match, change); err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	updatedMatch, err := h.service.GetMatch(id)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.SetETag(w, updatedMatch.Version)
+	utils.WriteSuccess(w, http.StatusOK, updatedMatch)
+}
+
+func (h *Handler) handleGetMatchHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	history, err := h.service.GetMatchHistory(id)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, history)
+}
+
+func (h *Handler) handleUndoMatchChange(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// the actor and reason are optional, so an empty body is accepted
+	var change types.MatchChange
+	if err := utils.ParseJSON(r, &change); err != nil && !errors.Is(err, io.EOF) {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	change = recordedChange(r, change)
+
+	matchVersion, err := utils.ParseIfMatch(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.UndoMatchChange(types.Match{ID: id, Version: matchVersion}, change); err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	match, err := h.service.GetMatch(id)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.SetETag(w, match.Version)
+	utils.WriteSuccess(w, http.StatusOK, match)
+}
+
+func (h *Handler) handleGetMatch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
 	}
 
-	if err := h.service.UpdateMatch(match); err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
+	match, err := h.service.GetMatch(id)
+	if err != nil {
+		utils.WriteServiceError(w, err)
 		return
 	}
 
-	utils.WriteSuccess(w, http.StatusOK, nil)
+	utils.SetETag(w, match.Version)
+	utils.WriteSuccess(w, http.StatusOK, match)
 }
 
 func (h *Handler) handleRestartLeague(w http.ResponseWriter, r *http.Request) {
-	err := h.service.RestartLeague()
+	leagueVersion, err := utils.ParseIfMatch(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.service.RestartLeague(leagueVersion)
 
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	if !h.setLeagueETag(w) {
 		return
 	}
 
@@ -155,7 +270,7 @@ func (h *Handler) handleGetStandings(w http.ResponseWriter, r *http.Request) {
 	teams, err := h.service.GetStandings()
 
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
+		utils.WriteServiceError(w, err)
 		return
 	}
 
@@ -166,9 +281,42 @@ func (h *Handler) handleGetStandings(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) handleGetPredictions(w http.ResponseWriter, r *http.Request) {
 	predictions, err := h.service.GetPredictions()
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
+		utils.WriteServiceError(w, err)
 		return
 	}
 
 	utils.WriteSuccess(w, http.StatusOK, predictions)
 }
+
+// recordedChange attributes change to the API key of the request. The actor
+// sent by the client is only a display name, so it is kept in the reason
+// instead of being trusted as the author.
+func recordedChange(r *http.Request, change types.MatchChange) types.MatchChange {
+	name := change.Actor
+	change.Actor = ""
+	if key := auth.KeyFromContext(r.Context()); key != nil {
+		change.Actor = key.Name
+	}
+
+	if name != "" && name != change.Actor {
+		if change.Reason == "" {
+			change.Reason = fmt.Sprintf("on behalf of %s", name)
+		} else {
+			change.Reason = fmt.Sprintf("%s, on behalf of %s", change.Reason, name)
+		}
+	}
+	return change
+}
+
+// setLeagueETag sets the ETag of the current league version, writing an
+// error response and returning false when the league cannot be read.
+func (h *Handler) setLeagueETag(w http.ResponseWriter) bool {
+	league, err := h.service.GetLeague()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return false
+	}
+
+	utils.SetETag(w, league.Version)
+	return true
+}
@@ -0,0 +1,41 @@
+package league_test
+
+import (
+	"encoding/json"
+	"football-simulation/service/league"
+	"football-simulation/types"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestUnknownMatchIsNotFound(t *testing.T) {
+	l := newLeague(t)
+	if err := l.league.StartLeague(); err != nil {
+		t.Fatalf("StartLeague: %v", err)
+	}
+
+	router := mux.NewRouter()
+	league.NewHandler(l.league).RegisterRoutes(router)
+
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/league/match/999", nil),
+		httptest.NewRequest(http.MethodPut, "/league/match/999", strings.NewReader(`{"team1_score": 1, "team2_score": 0}`)),
+		httptest.NewRequest(http.MethodGet, "/league/match/999/history", nil),
+		httptest.NewRequest(http.MethodPost, "/league/match/999/undo", nil),
+	} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		var response types.Response
+		if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+			t.Fatalf("%s %s: decoding the response: %v", request.Method, request.URL.Path, err)
+		}
+		if recorder.Code != http.StatusNotFound || response.Code != "match_not_found" || response.Message != "match 999 not found" {
+			t.Errorf("%s %s = %d %+v, want 404 match_not_found", request.Method, request.URL.Path, recorder.Code, response)
+		}
+	}
+}
@@ -1,38 +1,134 @@
 package league
 
 import (
-	"errors"
+	"context"
+	"fmt"
 	"football-simulation/types"
+	"math/rand"
+	"sync"
 )
 
+var (
+	// ErrLeagueBusy is returned when another request is already changing the league.
+	ErrLeagueBusy = types.NewError(types.ErrorKindConflict, "league_busy", "the league is being updated by another request, try again")
+	// ErrPreconditionFailed is returned when the caller's version of a
+	// resource no longer matches the stored one.
+	ErrPreconditionFailed = types.NewError(types.ErrorKindPreconditionFailed, "precondition_failed", "the resource has been modified since it was last read")
+	ErrMatchNotFound      = types.NewError(types.ErrorKindNotFound, "match_not_found", "match not found")
+	// ErrLeagueFinished is returned when every match of the fixture has been played.
+	ErrLeagueFinished         = types.NewError(types.ErrorKindConflict, "league_finished", "every match has been played, restart the league to play a new season")
+	ErrPredictionsUnavailable = types.NewError(types.ErrorKindConflict, "predictions_unavailable", "championship predictions can only be made after week 4")
+	ErrNoChangesToUndo        = types.NewError(types.ErrorKindConflict, "no_changes_to_undo", "the match has no changes to undo")
+	// ErrWeekAlreadyPlayed is returned when an undo would leave a match of a
+	// week already played without a result, as it would never be played again.
+	ErrWeekAlreadyPlayed = types.NewError(types.ErrorKindConflict, "week_already_played", "the week of the match has been played, correct the result instead of undoing it")
+)
+
+// DefaultSimulations is how many seasons GetPredictions simulates.
+const DefaultSimulations = 1000
+
+// simulatedChange attributes results produced by the match engine.
+var simulatedChange = types.MatchChange{Actor: types.SimulationActor, Reason: "simulated"}
+
 type Service struct {
 	store             types.LeagueStore
 	teamService       types.TeamService
 	simulationService types.SimulationService
+	eventStore        types.LeagueEventStore
+	webhookService    types.WebhookService
+	calendarService   types.CalendarService
+	fixtureService    types.FixtureService
+	transactor        types.Transactor
+
+	// mu serializes league changes within this process; the advisory lock
+	// taken in withLeagueLock covers other server instances.
+	mu sync.Mutex
 }
 
-func NewService(store types.LeagueStore, simulationService types.SimulationService, teamService types.TeamService) *Service {
+func NewService(store types.LeagueStore, simulationService types.SimulationService, teamService types.TeamService, eventStore types.LeagueEventStore, webhookService types.WebhookService, calendarService types.CalendarService, fixtureService types.FixtureService, transactor types.Transactor) *Service {
 	return &Service{
 		store:             store,
 		teamService:       teamService,
 		simulationService: simulationService,
+		eventStore:        eventStore,
+		webhookService:    webhookService,
+		calendarService:   calendarService,
+		fixtureService:    fixtureService,
+		transactor:        transactor,
 	}
 }
 
+// withTx runs fn against a copy of the service whose stores all share one
+// transaction, so a round either fully commits or leaves no trace.
+func (s *Service) withTx(fn func(txService *Service) error) error {
+	return s.transactor.WithinTransaction(func(tx types.DBTX) error {
+		return fn(&Service{
+			store:             s.store.WithTx(tx),
+			teamService:       s.teamService.WithTx(tx),
+			simulationService: s.simulationService.WithTx(tx),
+			eventStore:        s.eventStore.WithTx(tx),
+			webhookService:    s.webhookService.WithTx(tx),
+			calendarService:   s.calendarService.WithTx(tx),
+			fixtureService:    s.fixtureService.WithTx(tx),
+			transactor:        s.transactor,
+		})
+	})
+}
+
+// withLeagueLock runs fn in a transaction holding the league lock, failing
+// fast with ErrLeagueBusy instead of waiting for a concurrent change. A
+// non-zero leagueVersion must match the stored league version.
+func (s *Service) withLeagueLock(leagueVersion int, fn func(txService *Service) error) error {
+	if !s.mu.TryLock() {
+		return ErrLeagueBusy
+	}
+	defer s.mu.Unlock()
+
+	return s.withTx(func(txService *Service) error {
+		league, err := txService.store.GetLeagueInfo()
+		if err != nil {
+			return err
+		}
+
+		locked, err := txService.store.TryLockLeague(league.ID)
+		if err != nil {
+			return err
+		}
+
+		if !locked {
+			return ErrLeagueBusy
+		}
+
+		if leagueVersion != 0 && leagueVersion != league.Version {
+			return ErrPreconditionFailed
+		}
+
+		return fn(txService)
+	})
+}
+
 func (s *Service) StartLeague() error {
+	return s.withLeagueLock(0, func(txService *Service) error {
+		return txService.startLeague()
+	})
+}
+
+func (s *Service) startLeague() error {
 
 	teams, err := s.teamService.GetTeams()
 
 	if err != nil {
 		return err
 	}
-	err = s.simulationService.GenerateFixture(teams)
+	fixture, totalWeeks, err := s.fixtureService.Generate(teams)
 
 	if err != nil {
 		return err
 	}
 
-	totalWeeks := calculateTotalWeeks(teams)
+	if err := s.calendarService.ScheduleFixture(fixture); err != nil {
+		return err
+	}
 
 	league, err := s.store.GetLeagueInfo()
 
@@ -40,22 +136,49 @@ func (s *Service) StartLeague() error {
 		return err
 	}
 
+	//every fixture gets its own seed so its results can be reproduced from a snapshot
+	seed := rand.Int63()
+
 	err = s.store.UpdateLeague(types.League{
-		ID:               league.ID,
-		Name:             league.Name,
-		CurrentWeek:      league.CurrentWeek + 1,
-		TotalWeeks:       totalWeeks,
-		ChampionTeamName: league.ChampionTeamName,
+		ID:                      league.ID,
+		Name:                    league.Name,
+		CurrentWeek:             league.CurrentWeek + 1,
+		TotalWeeks:              totalWeeks,
+		ChampionTeamName:        league.ChampionTeamName,
+		PlayoffChampionTeamName: league.PlayoffChampionTeamName,
+		Season:                  league.Season,
+		Seed:                    seed,
 	})
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return s.eventStore.AppendEvent(types.EventFixtureGenerated, types.FixtureGeneratedEvent{
+		CurrentWeek: league.CurrentWeek + 1,
+		TotalWeeks:  totalWeeks,
+		Seed:        seed,
+		Matches:     fixture,
+	})
 }
 
-func (s *Service) NextWeek() ([]types.Match, *types.Team, error) {
+func (s *Service) NextWeek(leagueVersion int) ([]types.Match, *types.Team, error) {
+	var playedMatches []types.Match
+	var champion *types.Team
+
+	err := s.withLeagueLock(leagueVersion, func(txService *Service) error {
+		var err error
+		playedMatches, champion, err = txService.nextWeek()
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return playedMatches, champion, nil
+}
+
+func (s *Service) nextWeek() ([]types.Match, *types.Team, error) {
 	matches, err := s.store.GetAllMatches()
 	if err != nil {
 		return nil, nil, err
@@ -63,11 +186,13 @@ func (s *Service) NextWeek() ([]types.Match, *types.Team, error) {
 
 	if len(matches) == 0 {
 		//iff there is no match, start the league
-		err = s.StartLeague()
+		err = s.startLeague()
 		if err != nil {
 			return nil, nil, err
 		}
 
+	} else if allPlayed(matches) {
+		return nil, nil, ErrLeagueFinished
 	}
 
 	matches, err = s.store.GetMatchesForNextWeek()
@@ -75,6 +200,13 @@ func (s *Service) NextWeek() ([]types.Match, *types.Team, error) {
 		return nil, nil, err
 	}
 
+	if len(matches) > 0 {
+		err = s.reseedForWeek(matches[0].Week)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	var playedMatches []types.Match
 
 	for _, match := range matches {
@@ -88,6 +220,7 @@ func (s *Service) NextWeek() ([]types.Match, *types.Team, error) {
 			return nil, nil, err
 		}
 
+		before := match
 		team1Score, team2Score := s.simulationService.PlayMatch(*team1, *team2)
 		match.Team1Score = team1Score
 		match.Team2Score = team2Score
@@ -97,6 +230,12 @@ func (s *Service) NextWeek() ([]types.Match, *types.Team, error) {
 		if err != nil {
 			return nil, nil, err
 		}
+		match.Version++
+
+		err = s.recordMatchEvent(types.EventMatchPlayed, newMatchEvent(before, match, simulatedChange, 0))
+		if err != nil {
+			return nil, nil, err
+		}
 
 		err = s.teamService.UpdateTeamStats(*team1, *team2, team1Score, team2Score, false)
 		if err != nil {
@@ -110,18 +249,44 @@ func (s *Service) NextWeek() ([]types.Match, *types.Team, error) {
 		return nil, nil, err
 	}
 
+	currentWeek, err := s.store.GetCurrentWeek()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = s.eventStore.AppendEvent(types.EventWeekAdvanced, types.WeekAdvancedEvent{Week: currentWeek})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = s.notifyWeekPlayed(currentWeek - 1)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	remainingMatches, err := s.store.GetMatchesForNextWeek()
 	if err != nil {
 		return nil, nil, err
 	}
 
 	if len(remainingMatches) == 0 {
+		split, err := s.splitLeague()
+		if err != nil {
+			return nil, nil, err
+		}
+		if split {
+			return playedMatches, nil, nil
+		}
+
 		standings, err := s.store.GetStandings()
 		if err != nil {
 			return nil, nil, err
 		}
 
 		champion := &standings[0]
+		if err := s.notifyChampionDecided(*champion); err != nil {
+			return nil, nil, err
+		}
 		return playedMatches, champion, nil
 	}
 
@@ -129,14 +294,30 @@ func (s *Service) NextWeek() ([]types.Match, *types.Team, error) {
 
 }
 
-func (s *Service) PlayAll() ([]types.MatchResult, *types.Team, error) {
+func (s *Service) PlayAll(leagueVersion int) ([]types.MatchResult, *types.Team, error) {
+	var playedMatches []types.MatchResult
+	var champion *types.Team
+
+	err := s.withLeagueLock(leagueVersion, func(txService *Service) error {
+		var err error
+		playedMatches, champion, err = txService.playAll()
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return playedMatches, champion, nil
+}
+
+func (s *Service) playAll() ([]types.MatchResult, *types.Team, error) {
 	matches, err := s.GetAllMatches()
 	if err != nil {
 		return nil, nil, err
 	}
 
 	if len(matches) == 0 {
-		err = s.StartLeague()
+		err = s.startLeague()
 		if err != nil {
 			return nil, nil, err
 		}
@@ -146,10 +327,35 @@ func (s *Service) PlayAll() ([]types.MatchResult, *types.Team, error) {
 		}
 	}
 
-	var playedMatches []types.MatchResult
-
+	finished := true
 	for _, match := range matches {
 		if !match.Played {
+			finished = false
+			break
+		}
+	}
+	if finished {
+		return nil, nil, ErrLeagueFinished
+	}
+
+	var playedWeeks []int
+	seededWeek := 0
+
+	for {
+		for i, match := range matches {
+			if match.Played {
+				continue
+			}
+
+			if match.Week != seededWeek {
+				err = s.reseedForWeek(match.Week)
+				if err != nil {
+					return nil, nil, err
+				}
+				seededWeek = match.Week
+				playedWeeks = append(playedWeeks, match.Week)
+			}
+
 			team1, err := s.teamService.GetTeamByName(match.Team1Name)
 			if err != nil {
 				return nil, nil, err
@@ -179,13 +385,38 @@ func (s *Service) PlayAll() ([]types.MatchResult, *types.Team, error) {
 			if err != nil {
 				return nil, nil, err
 			}
+			match.Version++
+
+			err = s.recordMatchEvent(types.EventMatchPlayed, newMatchEvent(types.Match{ID: match.ID}, matchToSave, simulatedChange, 0))
+			if err != nil {
+				return nil, nil, err
+			}
 
 			err = s.teamService.UpdateTeamStats(*team1, *team2, team1Score, team2Score, false)
 			if err != nil {
 				return nil, nil, err
 			}
+
+			matches[i] = match
+		}
+
+		// a split league plays on once the table has been split
+		split, err := s.splitLeague()
+		if err != nil {
+			return nil, nil, err
+		}
+		if !split {
+			break
 		}
 
+		matches, err = s.GetAllMatches()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	playedMatches := make([]types.MatchResult, 0, len(matches))
+	for _, match := range matches {
 		playedMatches = append(playedMatches, types.MatchResult{
 			ID:         match.ID,
 			Week:       match.Week,
@@ -194,9 +425,17 @@ func (s *Service) PlayAll() ([]types.MatchResult, *types.Team, error) {
 			Team1Score: match.Team1Score,
 			Team2Score: match.Team2Score,
 			Played:     match.Played,
+			Version:    match.Version,
+			KickoffAt:  match.KickoffAt,
 		})
 	}
 
+	for _, week := range playedWeeks {
+		if err := s.notifyWeekPlayed(week); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	//champion
 	standings, err := s.store.GetStandings()
 	if err != nil {
@@ -204,8 +443,165 @@ func (s *Service) PlayAll() ([]types.MatchResult, *types.Team, error) {
 	}
 
 	champion := &standings[0]
+	if err := s.notifyChampionDecided(*champion); err != nil {
+		return nil, nil, err
+	}
 	return playedMatches, champion, nil
 }
+
+// notifyWeekPlayed queues the week_played webhooks with every result of week.
+func (s *Service) notifyWeekPlayed(week int) error {
+	league, err := s.store.GetLeagueInfo()
+	if err != nil {
+		return err
+	}
+
+	matches, err := s.GetMatchesByWeek(week)
+	if err != nil {
+		return err
+	}
+
+	return s.webhookService.Enqueue(types.WebhookWeekPlayed, types.WeekPlayedPayload{
+		Season:  league.Season,
+		Week:    week,
+		Matches: matches,
+	})
+}
+
+func (s *Service) notifyChampionDecided(champion types.Team) error {
+	league, err := s.store.GetLeagueInfo()
+	if err != nil {
+		return err
+	}
+
+	return s.webhookService.Enqueue(types.WebhookChampionDecided, types.ChampionDecidedPayload{
+		Season:   league.Season,
+		Champion: champion,
+	})
+}
+
+// splitLeague generates the rounds after the split of a split league once
+// every match before it has been played, and reports whether it did. The
+// league knows the rounds are due when it has more weeks than its fixture.
+func (s *Service) splitLeague() (bool, error) {
+	league, err := s.store.GetLeagueInfo()
+	if err != nil {
+		return false, err
+	}
+
+	matches, err := s.store.GetAllMatches()
+	if err != nil {
+		return false, err
+	}
+
+	lastWeek := 0
+	for _, match := range matches {
+		if !match.Played {
+			return false, nil
+		}
+		lastWeek = max(lastWeek, match.Week)
+	}
+
+	if len(matches) == 0 || lastWeek >= league.TotalWeeks {
+		return false, nil
+	}
+
+	standings, err := s.store.GetStandings()
+	if err != nil {
+		return false, err
+	}
+
+	fixture, err := s.fixtureService.GenerateSplit(standings, lastWeek+1)
+	if err != nil {
+		return false, err
+	}
+
+	// the calendar dates the whole fixture, so the split rounds follow the
+	// weeks already played instead of starting over at its first date
+	scheduled, err := s.store.GetAllMatches()
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.calendarService.ScheduleFixture(scheduled); err != nil {
+		return false, err
+	}
+
+	return true, s.eventStore.AppendEvent(types.EventFixtureGenerated, types.FixtureGeneratedEvent{
+		CurrentWeek: league.CurrentWeek,
+		TotalWeeks:  league.TotalWeeks,
+		Seed:        league.Seed,
+		Matches:     fixture,
+	})
+}
+
+func allPlayed(matches []types.Match) bool {
+	for _, match := range matches {
+		if !match.Played {
+			return false
+		}
+	}
+	return true
+}
+
+// reseedForWeek restarts the match engine from the league seed and the week,
+// so a week's results depend only on the state it is played from. Leagues
+// created before seeds were stored keep the engine's current source.
+func (s *Service) reseedForWeek(week int) error {
+	league, err := s.store.GetLeagueInfo()
+	if err != nil {
+		return err
+	}
+
+	if league.Seed != 0 {
+		s.simulationService.Reseed(league.Seed + int64(week))
+	}
+
+	return nil
+}
+
+func (s *Service) GetLeague() (types.League, error) {
+	league, err := s.store.GetLeagueInfo()
+	if err != nil {
+		return types.League{}, err
+	}
+
+	return league, nil
+}
+
+func (s *Service) GetMatch(id int) (*types.MatchResult, error) {
+	match, err := s.store.GetMatchByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if match.ID == 0 {
+		return nil, ErrMatchNotFound.Errorf("match %d not found", id)
+	}
+
+	team1, err := s.teamService.GetTeamByID(match.Team1ID)
+	if err != nil {
+		return nil, err
+	}
+
+	team2, err := s.teamService.GetTeamByID(match.Team2ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.MatchResult{
+		ID:         match.ID,
+		Week:       match.Week,
+		Team1Name:  team1.Name,
+		Team2Name:  team2.Name,
+		Team1Score: match.Team1Score,
+		Team2Score: match.Team2Score,
+		Played:     match.Played,
+		Version:    match.Version,
+		KickoffAt:  match.KickoffAt,
+	}, nil
+}
+
 func (s *Service) GetWeekResults() ([]types.MatchResult, error) {
 	currentWeek, err := s.store.GetCurrentWeek()
 	if err != nil {
@@ -238,6 +634,8 @@ func (s *Service) GetWeekResults() ([]types.MatchResult, error) {
 			Team1Score: match.Team1Score,
 			Team2Score: match.Team2Score,
 			Played:     match.Played,
+			Version:    match.Version,
+			KickoffAt:  match.KickoffAt,
 		})
 	}
 
@@ -272,6 +670,8 @@ func (s *Service) GetMatchesByWeek(id int) ([]types.MatchResult, error) {
 			Team1Score: match.Team1Score,
 			Team2Score: match.Team2Score,
 			Played:     match.Played,
+			Version:    match.Version,
+			KickoffAt:  match.KickoffAt,
 		})
 	}
 
@@ -305,60 +705,237 @@ func (s *Service) GetAllMatches() ([]types.MatchResult, error) {
 			Team1Score: match.Team1Score,
 			Team2Score: match.Team2Score,
 			Played:     match.Played,
+			Version:    match.Version,
+			KickoffAt:  match.KickoffAt,
 		})
 	}
 
 	return matchResults, nil
 }
 
-func (s *Service) UpdateMatch(match types.Match) error {
-	existingMatch, err := s.store.GetMatchByID(match.ID)
+// UpdateMatch replaces the score of a match. A non-zero match.Version must
+// match the stored version of the match.
+func (s *Service) UpdateMatch(match types.Match, change types.MatchChange) error {
+	return s.withLeagueLock(0, func(txService *Service) error {
+		return txService.updateMatch(match, change)
+	})
+}
+
+func (s *Service) updateMatch(match types.Match, change types.MatchChange) error {
+	existingMatch, err := s.getMatchForChange(match)
 	if err != nil {
 		return err
 	}
 
-	if err := s.teamService.UpdateTeamStatsReverse(*existingMatch); err != nil {
-		return err
+	return s.setMatchResult(*existingMatch, match.Team1Score, match.Team2Score, true, change, 0)
+}
+
+func (s *Service) GetMatchHistory(matchID int) ([]types.MatchEvent, error) {
+	match, err := s.store.GetMatchByID(matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if match.ID == 0 {
+		return nil, ErrMatchNotFound.Errorf("match %d not found", matchID)
 	}
 
-	existingMatch.Team1Score = match.Team1Score
-	existingMatch.Team2Score = match.Team2Score
-	if err := s.store.UpdateMatch(*existingMatch); err != nil {
+	return s.store.GetMatchEvents(matchID)
+}
+
+// UndoMatchChange restores the result a match had before its latest change
+// that has not been undone yet. The undo itself is recorded as a new event.
+func (s *Service) UndoMatchChange(match types.Match, change types.MatchChange) error {
+	return s.withLeagueLock(0, func(txService *Service) error {
+		return txService.undoMatchChange(match, change)
+	})
+}
+
+func (s *Service) undoMatchChange(match types.Match, change types.MatchChange) error {
+	existingMatch, err := s.getMatchForChange(match)
+	if err != nil {
 		return err
 	}
 
-	team1, err := s.teamService.GetTeamByID(existingMatch.Team1ID)
+	events, err := s.store.GetMatchEvents(existingMatch.ID)
 	if err != nil {
 		return err
 	}
 
-	team2, err := s.teamService.GetTeamByID(existingMatch.Team2ID)
+	reverted := make(map[int]bool)
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		if event.RevertsEventID != 0 {
+			reverted[event.RevertsEventID] = true
+			continue
+		}
+
+		if reverted[event.ID] {
+			continue
+		}
+
+		if !event.BeforePlayed {
+			if err := s.checkWeekNotPlayed(*existingMatch); err != nil {
+				return err
+			}
+		}
+
+		if change.Reason == "" {
+			change.Reason = fmt.Sprintf("undo of change %d", event.ID)
+		}
+
+		return s.setMatchResult(*existingMatch, event.BeforeTeam1Score, event.BeforeTeam2Score, event.BeforePlayed, change, event.ID)
+	}
+
+	return ErrNoChangesToUndo.Errorf("match %d has no changes to undo", existingMatch.ID)
+}
+
+// checkWeekNotPlayed rejects removing the result of match once its week is
+// behind the league, or a later week has been played, since Next Week only
+// plays the matches of the current week.
+func (s *Service) checkWeekNotPlayed(match types.Match) error {
+	league, err := s.store.GetLeagueInfo()
 	if err != nil {
 		return err
 	}
 
-	if err := s.teamService.UpdateTeamStats(*team1, *team2, existingMatch.Team1Score, existingMatch.Team2Score, true); err != nil {
+	if match.Week < league.CurrentWeek {
+		return ErrWeekAlreadyPlayed.Errorf("week %d of match %d has been played, correct the result instead of undoing it", match.Week, match.ID)
+	}
+
+	matches, err := s.store.GetAllMatches()
+	if err != nil {
 		return err
 	}
 
+	for _, other := range matches {
+		if other.Played && other.Week > match.Week {
+			return ErrWeekAlreadyPlayed.Errorf("week %d has been played after match %d, correct the result instead of undoing it", other.Week, match.ID)
+		}
+	}
 	return nil
 }
 
-func (s *Service) RestartLeague() error {
+func (s *Service) getMatchForChange(match types.Match) (*types.Match, error) {
+	existingMatch, err := s.store.GetMatchByID(match.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existingMatch.ID == 0 {
+		return nil, ErrMatchNotFound.Errorf("match %d not found", match.ID)
+	}
+
+	if match.Version != 0 && match.Version != existingMatch.Version {
+		return nil, ErrPreconditionFailed
+	}
+
+	return existingMatch, nil
+}
+
+// setMatchResult moves match from its stored result to the given one, keeping
+// the team statistics in step and appending the change to the match history.
+func (s *Service) setMatchResult(match types.Match, team1Score, team2Score int, played bool, change types.MatchChange, revertsEventID int) error {
+	before := match
+
+	if before.Played {
+		if err := s.teamService.UpdateTeamStatsReverse(before, played); err != nil {
+			return err
+		}
+	}
+
+	match.Team1Score = team1Score
+	match.Team2Score = team2Score
+	match.Played = played
+	if err := s.store.UpdateMatch(match); err != nil {
+		return err
+	}
+
+	if played {
+		team1, err := s.teamService.GetTeamByID(match.Team1ID)
+		if err != nil {
+			return err
+		}
+
+		team2, err := s.teamService.GetTeamByID(match.Team2ID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.teamService.UpdateTeamStats(*team1, *team2, match.Team1Score, match.Team2Score, before.Played); err != nil {
+			return err
+		}
+	}
+
+	event := newMatchEvent(before, match, change, revertsEventID)
+	if err := s.recordMatchEvent(types.EventResultCorrected, event); err != nil {
+		return err
+	}
+
+	return s.webhookService.Enqueue(types.WebhookResultEdited, event)
+}
+
+// recordMatchEvent appends a result change to both the match history and the
+// league event log.
+func (s *Service) recordMatchEvent(eventType string, event types.MatchEvent) error {
+	if err := s.store.SaveMatchEvent(event); err != nil {
+		return err
+	}
+
+	return s.eventStore.AppendEvent(eventType, event)
+}
+
+func newMatchEvent(before, after types.Match, change types.MatchChange, revertsEventID int) types.MatchEvent {
+	actor := change.Actor
+	if actor == "" {
+		actor = types.AnonymousActor
+	}
+
+	return types.MatchEvent{
+		MatchID:          after.ID,
+		BeforeTeam1Score: before.Team1Score,
+		BeforeTeam2Score: before.Team2Score,
+		BeforePlayed:     before.Played,
+		AfterTeam1Score:  after.Team1Score,
+		AfterTeam2Score:  after.Team2Score,
+		AfterPlayed:      after.Played,
+		Actor:            actor,
+		Reason:           change.Reason,
+		RevertsEventID:   revertsEventID,
+	}
+}
+
+func (s *Service) RestartLeague(leagueVersion int) error {
+	return s.withLeagueLock(leagueVersion, func(txService *Service) error {
+		return txService.restartLeague()
+	})
+}
 
-	err := s.store.ClearFixtures()
+func (s *Service) restartLeague() error {
+
+	league, err := s.store.GetLeagueInfo()
 
 	if err != nil {
 		return err
 	}
 
-	err = s.teamService.ResetTeams()
+	//keep the played matches for head-to-head records before the fixture is cleared
+	season := league.Season
+	if league.CurrentWeek > 0 {
+		err = s.store.ArchiveFixtures()
+		if err != nil {
+			return err
+		}
+		season++
+	}
+
+	err = s.store.ClearFixtures()
 
 	if err != nil {
 		return err
 	}
 
-	league, err := s.store.GetLeagueInfo()
+	err = s.teamService.ResetTeams()
 
 	if err != nil {
 		return err
@@ -370,13 +947,19 @@ func (s *Service) RestartLeague() error {
 		CurrentWeek:      0,
 		TotalWeeks:       0,
 		ChampionTeamName: "",
+		Season:           season,
 	})
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	err = s.eventStore.AppendEvent(types.EventLeagueRestarted, types.LeagueRestartedEvent{Season: season})
+	if err != nil {
+		return err
+	}
+
+	return s.webhookService.Enqueue(types.WebhookLeagueRestarted, types.LeagueRestartedEvent{Season: season})
 }
 
 func (s *Service) GetStandings() ([]types.Team, error) {
@@ -389,13 +972,19 @@ func (s *Service) GetStandings() ([]types.Team, error) {
 }
 
 func (s *Service) GetPredictions() ([]types.Prediction, error) {
+	return s.RunPredictions(context.Background(), DefaultSimulations, nil)
+}
+
+// RunPredictions estimates the championship odds from the given number of
+// simulated seasons; see SimulationService.CalculateChampionshipOdds.
+func (s *Service) RunPredictions(ctx context.Context, simulations int, progress func(done int)) ([]types.Prediction, error) {
 	league, err := s.store.GetLeagueInfo()
 	if err != nil {
 		return nil, err
 	}
 
 	if league.CurrentWeek < 4 {
-		return nil, errors.New("championship predictions can only be made after week 4")
+		return nil, ErrPredictionsUnavailable
 	}
 
 	teams, err := s.store.GetStandings()
@@ -427,11 +1016,9 @@ func (s *Service) GetPredictions() ([]types.Prediction, error) {
 			Team1Score: matchResult.Team1Score,
 			Team2Score: matchResult.Team2Score,
 			Played:     matchResult.Played,
+			Version:    matchResult.Version,
 		}
 	}
 
-	return s.simulationService.CalculateChampionshipOdds(teams, matches)
-}
-func calculateTotalWeeks(teams []types.Team) int {
-	return 2 * (len(teams) - 1)
+	return s.simulationService.CalculateChampionshipOdds(ctx, teams, matches, simulations, progress)
 }
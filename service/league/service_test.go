@@ -0,0 +1,149 @@
+package league_test
+
+import (
+	"errors"
+	"football-simulation/database/memory"
+	"football-simulation/service/calendar"
+	"football-simulation/service/fixture"
+	"football-simulation/service/league"
+	"football-simulation/service/simulation"
+	"football-simulation/service/team"
+	"football-simulation/service/webhook"
+	"football-simulation/types"
+	"testing"
+	"time"
+)
+
+// leagueServices is a league of four teams on the memory backend.
+type leagueServices struct {
+	db       *memory.DB
+	league   *league.Service
+	fixture  *fixture.Service
+	calendar *calendar.Service
+}
+
+func newLeague(t *testing.T) leagueServices {
+	t.Helper()
+
+	db := memory.NewDB()
+	leagueStore, teamStore, eventStore := memory.NewLeagueStore(db), memory.NewTeamStore(db), memory.NewEventStore(db)
+	transactor := memory.NewTransactor(db)
+
+	simulationService := simulation.NewService(memory.NewSimulationStore(db))
+	teamService := team.NewService(teamStore, leagueStore, eventStore, transactor)
+	calendarService := calendar.NewService(memory.NewCalendarStore(db), leagueStore, teamStore, transactor)
+	fixtureService := fixture.NewService(memory.NewFixtureStore(db), leagueStore, teamStore, simulationService)
+	service := league.NewService(leagueStore, simulationService, teamService, eventStore, webhook.NewService(memory.NewWebhookStore(db)), calendarService, fixtureService, transactor)
+
+	for i, name := range []string{"Chelsea", "Arsenal", "Manchester City", "Liverpool"} {
+		if _, err := teamStore.CreateTeam(types.Team{Name: name, Strength: 70 + 5*i}); err != nil {
+			t.Fatalf("CreateTeam: %v", err)
+		}
+	}
+
+	return leagueServices{db: db, league: service, fixture: fixtureService, calendar: calendarService}
+}
+
+func TestUndoSimulatedResultOfPlayedWeek(t *testing.T) {
+	l := newLeague(t)
+
+	for week := 1; week <= 2; week++ {
+		if _, _, err := l.league.NextWeek(0); err != nil {
+			t.Fatalf("NextWeek %d: %v", week, err)
+		}
+	}
+
+	matches, err := l.league.GetMatchesByWeek(1)
+	if err != nil || len(matches) == 0 {
+		t.Fatalf("GetMatchesByWeek = %v, %v", matches, err)
+	}
+	err = l.league.UndoMatchChange(types.Match{ID: matches[0].ID}, types.MatchChange{})
+	if !errors.Is(err, league.ErrWeekAlreadyPlayed) {
+		t.Fatalf("UndoMatchChange = %v, want %v", err, league.ErrWeekAlreadyPlayed)
+	}
+
+	// an edit of the simulated result can still be undone
+	err = l.league.UpdateMatch(types.Match{ID: matches[0].ID, Team1Score: 4, Team2Score: 4}, types.MatchChange{})
+	if err != nil {
+		t.Fatalf("UpdateMatch: %v", err)
+	}
+	if err := l.league.UndoMatchChange(types.Match{ID: matches[0].ID}, types.MatchChange{}); err != nil {
+		t.Fatalf("UndoMatchChange of the edit: %v", err)
+	}
+
+	// the season finishes with every match played, and then stops
+	var champion *types.Team
+	for week := 3; champion == nil; week++ {
+		if week > 6 {
+			t.Fatal("no champion after the last week")
+		}
+		if _, champion, err = l.league.NextWeek(0); err != nil {
+			t.Fatalf("NextWeek %d: %v", week, err)
+		}
+	}
+
+	all, err := l.league.GetAllMatches()
+	if err != nil {
+		t.Fatalf("GetAllMatches: %v", err)
+	}
+	for _, match := range all {
+		if !match.Played {
+			t.Errorf("match %d of week %d left unplayed after the champion was decided", match.ID, match.Week)
+		}
+	}
+	if _, _, err := l.league.NextWeek(0); !errors.Is(err, league.ErrLeagueFinished) {
+		t.Errorf("NextWeek after the last week = %v, want %v", err, league.ErrLeagueFinished)
+	}
+}
+
+func TestSplitRoundsFollowTheCalendar(t *testing.T) {
+	l := newLeague(t)
+	service, fixtureService, calendarService := l.league, l.fixture, l.calendar
+	leagueStore := memory.NewLeagueStore(l.db)
+
+	if _, err := fixtureService.SetConfig(types.FixtureConfig{SplitAfter: 2}); err != nil {
+		t.Fatalf("SetConfig: %v", err)
+	}
+	if err := service.StartLeague(); err != nil {
+		t.Fatalf("StartLeague: %v", err)
+	}
+	// Saturdays from the start of August
+	_, err := calendarService.SetCalendar(types.CalendarConfig{StartDate: "2026-08-01", Matchdays: []string{"saturday"}, KickoffTimes: []string{"15:00"}})
+	if err != nil {
+		t.Fatalf("SetCalendar: %v", err)
+	}
+
+	// the third week plays the split round generated after the second
+	for week := 1; week <= 3; week++ {
+		if _, _, err := service.NextWeek(0); err != nil {
+			t.Fatalf("NextWeek %d: %v", week, err)
+		}
+	}
+
+	matches, err := leagueStore.GetAllMatches()
+	if err != nil {
+		t.Fatalf("GetAllMatches: %v", err)
+	}
+
+	var lastBeforeSplit time.Time
+	split := 0
+	for _, match := range matches {
+		if match.KickoffAt == nil {
+			t.Fatalf("match %d of week %d has no kick-off time", match.ID, match.Week)
+		}
+		if match.Week <= 2 && match.KickoffAt.After(lastBeforeSplit) {
+			lastBeforeSplit = *match.KickoffAt
+		}
+	}
+	for _, match := range matches {
+		if match.Week > 2 {
+			split++
+			if !match.KickoffAt.After(lastBeforeSplit) {
+				t.Errorf("split match %d of week %d kicks off at %s, not after the last matchday before the split on %s", match.ID, match.Week, match.KickoffAt, lastBeforeSplit)
+			}
+		}
+	}
+	if split == 0 {
+		t.Fatal("no split rounds were generated")
+	}
+}
@@ -2,25 +2,31 @@ package league
 
 import (
 	"database/sql"
+	"football-simulation/database"
 	"football-simulation/types"
 )
 
 type Store struct {
-	db *sql.DB
+	db types.DBTX
 }
 
-func NewStore(db *sql.DB) *Store {
+func NewStore(db types.DBTX) *Store {
 	return &Store{db: db}
 }
 
+func (s *Store) WithTx(tx types.DBTX) types.LeagueStore {
+	return &Store{db: tx}
+}
+
 func (s *Store) GetLeagueInfo() (types.League, error) {
 	league := new(types.League)
 
-	rows, err := s.db.Query("SELECT * FROM league")
+	rows, err := s.db.Query("SELECT id, name, current_week, total_weeks, champion_team_name, playoff_champion_team_name, season, seed, version FROM league")
 
 	if err != nil {
 		return types.League{}, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		league, err = scanRowsIntoLeague(rows)
@@ -40,9 +46,40 @@ func (s *Store) ClearFixtures() error {
 	return nil
 }
 
+func (s *Store) ArchiveFixtures() error {
+	_, err := s.db.Exec(`
+		INSERT INTO archived_matches (season, week, team1_id, team1_name, team2_id, team2_name, team1_score, team2_score)
+		SELECT l.season, m.week, m.team1_id, t1.name, m.team2_id, t2.name, m.team1_score, m.team2_score
+		FROM matches m
+		JOIN teams t1 ON t1.id = m.team1_id
+		JOIN teams t2 ON t2.id = m.team2_id
+		CROSS JOIN league l
+		WHERE m.played = TRUE`)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// TryLockLeague takes a transaction-scoped advisory lock on the league. It
+// returns false without waiting when another transaction already holds it.
+// SQLite runs one write transaction at a time, so there is no lock to take.
+func (s *Store) TryLockLeague(leagueID int) (bool, error) {
+	if database.IsSQLite(s.db) {
+		return true, nil
+	}
+
+	var locked bool
+	err := s.db.QueryRow("SELECT pg_try_advisory_xact_lock(hashtext('league'), $1)", leagueID).Scan(&locked)
+	if err != nil {
+		return false, err
+	}
+	return locked, nil
+}
+
 func (s *Store) UpdateLeague(league types.League) error {
-	_, err := s.db.Exec(`UPDATE league SET name = $1, current_week = $2, total_weeks = $3, champion_team_name = $4 WHERE id = $5`,
-		league.Name, league.CurrentWeek, league.TotalWeeks, league.ChampionTeamName, league.ID)
+	_, err := s.db.Exec(`UPDATE league SET name = $1, current_week = $2, total_weeks = $3, champion_team_name = $4, playoff_champion_team_name = $5, season = $6, seed = $7, version = version + 1 WHERE id = $8`,
+		league.Name, league.CurrentWeek, league.TotalWeeks, league.ChampionTeamName, league.PlayoffChampionTeamName, league.Season, league.Seed, league.ID)
 	if err != nil {
 		return err
 	}
@@ -52,12 +89,13 @@ func (s *Store) UpdateLeague(league types.League) error {
 func (s *Store) GetStandings() ([]types.Team, error) {
 
 	rows, err := s.db.Query(`
-		SELECT id, name, points, matches, wins, draws, losses, goals_for, goals_against, goals_difference, temporary_drop
+		SELECT id, name, points, matches, wins, draws, losses, goals_for, goals_against, goals_difference, temporary_drop, points_deducted
 		FROM teams
 		ORDER BY points DESC, goals_difference DESC, goals_for DESC`)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	teams := make([]types.Team, 0)
 	for rows.Next() {
@@ -87,10 +125,11 @@ func (s *Store) GetMatchesForNextWeek() ([]types.Match, error) {
 	}
 
 	var matches []types.Match
-	rows, err := s.db.Query("SELECT id, week, team1_id, team2_id, team1_score, team2_score, played FROM matches WHERE played = FALSE AND week = $1", currentWeek)
+	rows, err := s.db.Query("SELECT id, week, team1_id, team2_id, team1_score, team2_score, played, version, kickoff_at FROM matches WHERE played = FALSE AND week = $1 ORDER BY id", currentWeek)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		match, err := scanRowsIntoMatch(rows)
@@ -105,7 +144,7 @@ func (s *Store) GetMatchesForNextWeek() ([]types.Match, error) {
 
 func (s *Store) GetMatchesByWeek(week int) ([]types.Match, error) {
 	var matches []types.Match
-	rows, err := s.db.Query("SELECT id, week, team1_id, team2_id, team1_score, team2_score, played FROM matches WHERE week = $1 AND played = TRUE", week)
+	rows, err := s.db.Query("SELECT id, week, team1_id, team2_id, team1_score, team2_score, played, version, kickoff_at FROM matches WHERE week = $1 AND played = TRUE ORDER BY id", week)
 	if err != nil {
 		return nil, err
 	}
@@ -124,11 +163,12 @@ func (s *Store) GetMatchesByWeek(week int) ([]types.Match, error) {
 
 func (s *Store) GetMatchByID(id int) (*types.Match, error) {
 
-	rows, err := s.db.Query("SELECT * FROM matches WHERE id = $1", id)
+	rows, err := s.db.Query("SELECT id, week, team1_id, team2_id, team1_score, team2_score, played, version, kickoff_at FROM matches WHERE id = $1", id)
 
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	match := new(types.Match)
 
@@ -145,8 +185,8 @@ func (s *Store) GetMatchByID(id int) (*types.Match, error) {
 }
 
 func (s *Store) UpdateMatch(match types.Match) error {
-	_, err := s.db.Exec("UPDATE matches SET team1_score = $1, team2_score = $2, played = TRUE WHERE id = $3",
-		match.Team1Score, match.Team2Score, match.ID)
+	_, err := s.db.Exec("UPDATE matches SET team1_score = $1, team2_score = $2, played = $3, version = version + 1 WHERE id = $4",
+		match.Team1Score, match.Team2Score, match.Played, match.ID)
 	if err != nil {
 		return err
 	}
@@ -154,7 +194,7 @@ func (s *Store) UpdateMatch(match types.Match) error {
 }
 
 func (s *Store) SaveMatchResult(match types.Match) error {
-	_, err := s.db.Exec("UPDATE matches SET team1_score = $1, team2_score = $2, played = TRUE WHERE id = $3",
+	_, err := s.db.Exec("UPDATE matches SET team1_score = $1, team2_score = $2, played = TRUE, version = version + 1 WHERE id = $3",
 		match.Team1Score, match.Team2Score, match.ID)
 	if err != nil {
 		return err
@@ -162,8 +202,64 @@ func (s *Store) SaveMatchResult(match types.Match) error {
 	return nil
 }
 
+func (s *Store) SaveMatchEvent(event types.MatchEvent) error {
+	_, err := s.db.Exec(`INSERT INTO match_events
+	(match_id, before_team1_score, before_team2_score, before_played, after_team1_score, after_team2_score, after_played, actor, reason, reverts_event_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, 0))`,
+		event.MatchID, event.BeforeTeam1Score, event.BeforeTeam2Score, event.BeforePlayed,
+		event.AfterTeam1Score, event.AfterTeam2Score, event.AfterPlayed, event.Actor, event.Reason, event.RevertsEventID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) GetMatchEvents(matchID int) ([]types.MatchEvent, error) {
+	rows, err := s.db.Query(`SELECT id, match_id, before_team1_score, before_team2_score, before_played,
+	after_team1_score, after_team2_score, after_played, actor, reason, reverts_event_id, created_at
+	FROM match_events WHERE match_id = $1 ORDER BY id`, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]types.MatchEvent, 0)
+	for rows.Next() {
+		var event types.MatchEvent
+		var reason sql.NullString
+		var revertsEventID sql.NullInt64
+		err := rows.Scan(
+			&event.ID,
+			&event.MatchID,
+			&event.BeforeTeam1Score,
+			&event.BeforeTeam2Score,
+			&event.BeforePlayed,
+			&event.AfterTeam1Score,
+			&event.AfterTeam2Score,
+			&event.AfterPlayed,
+			&event.Actor,
+			&reason,
+			&revertsEventID,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Reason = reason.String
+		event.RevertsEventID = int(revertsEventID.Int64)
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
 func (s *Store) IncrementWeek() error {
-	_, err := s.db.Exec("UPDATE league SET current_week = current_week + 1")
+	_, err := s.db.Exec("UPDATE league SET current_week = current_week + 1, version = version + 1")
 	if err != nil {
 		return err
 	}
@@ -172,7 +268,7 @@ func (s *Store) IncrementWeek() error {
 
 func (s *Store) GetAllMatches() ([]types.Match, error) {
 	var matches []types.Match
-	rows, err := s.db.Query("SELECT id, week, team1_id, team2_id, team1_score, team2_score, played FROM matches")
+	rows, err := s.db.Query("SELECT id, week, team1_id, team2_id, team1_score, team2_score, played, version, kickoff_at FROM matches ORDER BY week, id")
 	if err != nil {
 		return nil, err
 	}
@@ -193,19 +289,69 @@ func (s *Store) GetAllMatches() ([]types.Match, error) {
 	return matches, nil
 }
 
+func (s *Store) GetHeadToHeadMatches(teamID, opponentID int) ([]types.HeadToHeadMatch, error) {
+	rows, err := s.db.Query(`
+		SELECT l.season, FALSE, m.week, m.team1_id, t1.name, m.team2_id, t2.name, m.team1_score, m.team2_score, m.played
+		FROM matches m
+		JOIN teams t1 ON t1.id = m.team1_id
+		JOIN teams t2 ON t2.id = m.team2_id
+		CROSS JOIN league l
+		WHERE (m.team1_id = $1 AND m.team2_id = $2) OR (m.team1_id = $2 AND m.team2_id = $1)
+		UNION ALL
+		SELECT a.season, TRUE, a.week, a.team1_id, a.team1_name, a.team2_id, a.team2_name, a.team1_score, a.team2_score, TRUE
+		FROM archived_matches a
+		WHERE (a.team1_id = $1 AND a.team2_id = $2) OR (a.team1_id = $2 AND a.team2_id = $1)
+		ORDER BY 1, 3`, teamID, opponentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]types.HeadToHeadMatch, 0)
+	for rows.Next() {
+		var match types.HeadToHeadMatch
+		err := rows.Scan(
+			&match.Season,
+			&match.Archived,
+			&match.Week,
+			&match.Team1ID,
+			&match.Team1Name,
+			&match.Team2ID,
+			&match.Team2Name,
+			&match.Team1Score,
+			&match.Team2Score,
+			&match.Played,
+		)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
 func (s *Store) GetPredictions() ([]types.Prediction, error) {
 	return nil, nil
 }
 
 func scanRowsIntoLeague(rows *sql.Rows) (*types.League, error) {
 	league := new(types.League)
-	var championTeamName sql.NullString
+	var championTeamName, playoffChampionTeamName sql.NullString
 	err := rows.Scan(
 		&league.ID,
 		&league.Name,
 		&league.CurrentWeek,
 		&league.TotalWeeks,
 		&championTeamName,
+		&playoffChampionTeamName,
+		&league.Season,
+		&league.Seed,
+		&league.Version,
 	)
 
 	if err != nil {
@@ -217,6 +363,7 @@ func scanRowsIntoLeague(rows *sql.Rows) (*types.League, error) {
 	} else {
 		league.ChampionTeamName = ""
 	}
+	league.PlayoffChampionTeamName = playoffChampionTeamName.String
 
 	return league, nil
 }
@@ -236,6 +383,7 @@ func scanRowsIntoTeam(rows *sql.Rows) (*types.Team, error) {
 		&team.GoalsAgainst,
 		&team.GoalsDifference,
 		&team.TemporaryDrop,
+		&team.PointsDeducted,
 	)
 
 	if err != nil {
@@ -247,6 +395,7 @@ func scanRowsIntoTeam(rows *sql.Rows) (*types.Team, error) {
 
 func scanRowsIntoMatch(rows *sql.Rows) (*types.Match, error) {
 	match := new(types.Match)
+	var kickoffAt sql.NullTime
 	err := rows.Scan(
 		&match.ID,
 		&match.Week,
@@ -255,9 +404,15 @@ func scanRowsIntoMatch(rows *sql.Rows) (*types.Match, error) {
 		&match.Team1Score,
 		&match.Team2Score,
 		&match.Played,
+		&match.Version,
+		&kickoffAt,
 	)
 	if err != nil {
 		return nil, err
 	}
+
+	if kickoffAt.Valid {
+		match.KickoffAt = &kickoffAt.Time
+	}
 	return match, nil
 }
@@ -0,0 +1,146 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"football-simulation/service/calendar"
+	"football-simulation/service/event"
+	"football-simulation/service/export"
+	"football-simulation/service/fixture"
+	"football-simulation/service/job"
+	"football-simulation/service/league"
+	"football-simulation/service/openapi"
+	"football-simulation/service/playoff"
+	"football-simulation/service/sanction"
+	"football-simulation/service/schedule"
+	"football-simulation/service/snapshot"
+	"football-simulation/service/team"
+	"football-simulation/service/webhook"
+	"football-simulation/types"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+)
+
+type routeRegistrar interface {
+	RegisterRoutes(router *mux.Router)
+}
+
+// newRouter registers the routes of every handler cmd/api mounts; a new
+// handler must be added here too. The handlers are never called, so they get
+// no services.
+func newRouter() *mux.Router {
+	router := mux.NewRouter()
+	for _, handler := range []routeRegistrar{
+		league.NewHandler(nil),
+		team.NewHandler(nil),
+		sanction.NewHandler(nil),
+		event.NewHandler(nil),
+		export.NewHandler(nil),
+		snapshot.NewHandler(nil),
+		webhook.NewHandler(nil),
+		schedule.NewHandler(nil),
+		job.NewHandler(nil),
+		calendar.NewHandler(nil),
+		fixture.NewHandler(nil),
+		playoff.NewHandler(nil),
+		openapi.NewHandler(),
+	} {
+		handler.RegisterRoutes(router)
+	}
+	return router
+}
+
+func loadSpec(t *testing.T) *openapi3.T {
+	t.Helper()
+
+	doc, err := openapi3.NewLoader().LoadFromData(openapi.Spec)
+	if err != nil {
+		t.Fatalf("loading the spec: %v", err)
+	}
+	return doc
+}
+
+func TestSpecCoversRoutes(t *testing.T) {
+	doc := loadSpec(t)
+	served := make(map[string]bool)
+
+	err := newRouter().Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+
+		for _, method := range methods {
+			served[method+" "+path] = true
+
+			item := doc.Paths.Find(path)
+			if item == nil || item.GetOperation(method) == nil {
+				t.Errorf("%s %s is served but has no operation in the spec", method, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walking the routes: %v", err)
+	}
+
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			if !served[method+" "+path] {
+				t.Errorf("%s %s is in the spec but not served", method, path)
+			}
+		}
+	}
+}
+
+func TestValidatorRejectsNegativeScore(t *testing.T) {
+	validator, err := openapi.NewValidator()
+	if err != nil {
+		t.Fatalf("NewValidator: %v", err)
+	}
+
+	reached := false
+	handler := validator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+
+	body := `{"team1_score": -1, "team2_score": 2}`
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/api/v1/league/match/7", strings.NewReader(body)))
+
+	if reached {
+		t.Fatal("the request reached the handler")
+	}
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", recorder.Code)
+	}
+
+	var response types.Response
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("decoding the response: %v", err)
+	}
+	if response.Code != "validation_failed" || len(response.Errors) != 1 || response.Errors[0].Field != "team1_score" || response.Errors[0].Message == "" {
+		t.Fatalf("response = %+v, want one error on team1_score", response)
+	}
+
+	// a valid request and a route missing from the spec pass through
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodPut, "/api/v1/league/match/7", strings.NewReader(`{"team1_score": 1, "team2_score": 2}`)),
+		httptest.NewRequest(http.MethodGet, "/api/v1/unknown", nil),
+	} {
+		reached = false
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if !reached {
+			t.Errorf("%s %s did not reach the handler: %d %s", request.Method, request.URL.Path, recorder.Code, recorder.Body)
+		}
+	}
+}
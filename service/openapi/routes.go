@@ -0,0 +1,30 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Spec is the OpenAPI 3 document of the API. It is maintained by hand and
+// must be updated together with the routes and request types.
+//
+//go:embed openapi.json
+var Spec []byte
+
+type Handler struct{}
+
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/openapi.json", h.handleGetSpec).Methods("GET")
+}
+
+func (h *Handler) handleGetSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(Spec)
+}
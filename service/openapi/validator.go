@@ -0,0 +1,118 @@
+package openapi
+
+import (
+	"context"
+	"football-simulation/types"
+	"football-simulation/utils"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// Validator checks requests against Spec before they reach the handlers.
+type Validator struct {
+	router routers.Router
+}
+
+func NewValidator() (*Validator, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Validator{router: router}, nil
+}
+
+// Middleware rejects requests whose parameters or body do not match the
+// operation in the spec with a 400 listing every offending field. Requests
+// for routes missing from the spec are passed through unchecked.
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := v.router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// clients that omit the Content-Type still send JSON
+		if r.Header.Get("Content-Type") == "" && r.ContentLength != 0 {
+			r.Header.Set("Content-Type", "application/json")
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError:         true,
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		}
+
+		if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			utils.WriteValidationError(w, fieldErrors(err))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// fieldErrors flattens the errors returned by openapi3filter into one
+// FieldError per offending parameter or body field.
+func fieldErrors(err error) []types.FieldError {
+	switch err := err.(type) {
+	case openapi3.MultiError:
+		fieldErrs := make([]types.FieldError, 0, len(err))
+		for _, err := range err {
+			fieldErrs = append(fieldErrs, fieldErrors(err)...)
+		}
+		return fieldErrs
+
+	case *openapi3filter.RequestError:
+		field := "body"
+		if err.Parameter != nil {
+			field = err.Parameter.Name
+		}
+
+		if err.Err == nil {
+			return []types.FieldError{{Field: field, Message: err.Reason}}
+		}
+
+		fieldErrs := fieldErrors(err.Err)
+		for i := range fieldErrs {
+			if err.Parameter != nil || fieldErrs[i].Field == "" {
+				fieldErrs[i].Field = field
+			}
+		}
+		return fieldErrs
+
+	case *openapi3.SchemaError:
+		message := err.Reason
+		if err.SchemaField == "required" {
+			message = "is required"
+		}
+		return []types.FieldError{{
+			Field:   strings.Join(err.JSONPointer(), "."),
+			Message: message,
+		}}
+
+	case *openapi3filter.ParseError:
+		return []types.FieldError{{Message: err.Reason}}
+	}
+
+	return []types.FieldError{{Message: err.Error()}}
+}
@@ -0,0 +1,150 @@
+package playoff
+
+import (
+	"fmt"
+	"football-simulation/types"
+)
+
+// entrant is a team in the playoffs, seeded by its league position.
+type entrant struct {
+	id   int
+	name string
+	seed int
+}
+
+// ValidatePairings checks the pairings on their own; draw checks them
+// against the standings.
+func ValidatePairings(config types.PlayoffConfig) error {
+	switch len(config.Pairings) {
+	case 0, 1, 2, 4, 8:
+	default:
+		return ErrInvalidPlayoffConfig.Errorf("there are %d pairings, the playoffs need 1, 2, 4 or 8 to end in a final", len(config.Pairings))
+	}
+
+	seen := make(map[int]bool)
+	for _, pairing := range config.Pairings {
+		for _, position := range pairing {
+			if seen[position] {
+				return ErrInvalidPlayoffConfig.Errorf("position %d is paired more than once", position)
+			}
+			seen[position] = true
+		}
+	}
+
+	return nil
+}
+
+// draw returns the next round of the playoffs: the first round from the
+// pairings and the standings when played is empty, otherwise the winners of
+// consecutive ties of the last round played. The higher seed plays at home.
+func draw(config types.PlayoffConfig, standings []types.Team, played []types.PlayoffMatch, season int) ([]types.PlayoffMatch, error) {
+	var matches []types.PlayoffMatch
+	if len(played) == 0 {
+		if len(config.Pairings) == 0 {
+			return nil, ErrPlayoffsNotConfigured
+		}
+		if err := ValidatePairings(config); err != nil {
+			return nil, err
+		}
+
+		for slot, pairing := range config.Pairings {
+			var teams [2]entrant
+			for i, position := range pairing {
+				if position > len(standings) {
+					return nil, ErrInvalidPlayoffConfig.Errorf("position %d is paired but the league has %d teams", position, len(standings))
+				}
+				team := standings[position-1]
+				teams[i] = entrant{id: team.ID, name: team.Name, seed: position}
+			}
+			matches = append(matches, newMatch(season, 1, slot+1, teams[0], teams[1]))
+		}
+		return matches, nil
+	}
+
+	last := lastRound(played)
+	if len(last) == 1 {
+		return nil, ErrPlayoffsFinished
+	}
+
+	round := last[0].Round + 1
+	for i := 0; i+1 < len(last); i += 2 {
+		matches = append(matches, newMatch(season, round, i/2+1, winner(last[i]), winner(last[i+1])))
+	}
+	return matches, nil
+}
+
+func newMatch(season, round, slot int, team1, team2 entrant) types.PlayoffMatch {
+	if team2.seed < team1.seed {
+		team1, team2 = team2, team1
+	}
+
+	return types.PlayoffMatch{
+		Season:    season,
+		Round:     round,
+		Slot:      slot,
+		Team1ID:   team1.id,
+		Team1Name: team1.name,
+		Team1Seed: team1.seed,
+		Team2ID:   team2.id,
+		Team2Name: team2.name,
+		Team2Seed: team2.seed,
+	}
+}
+
+// lastRound returns the matches of the latest round of played, which is
+// ordered by round and slot.
+func lastRound(played []types.PlayoffMatch) []types.PlayoffMatch {
+	start := len(played) - 1
+	for start > 0 && played[start-1].Round == played[len(played)-1].Round {
+		start--
+	}
+	return played[start:]
+}
+
+func winner(match types.PlayoffMatch) entrant {
+	team1 := entrant{id: match.Team1ID, name: match.Team1Name, seed: match.Team1Seed}
+	team2 := entrant{id: match.Team2ID, name: match.Team2Name, seed: match.Team2Seed}
+
+	switch {
+	case match.Team1Score > match.Team2Score:
+		return team1
+	case match.Team2Score > match.Team1Score:
+		return team2
+	case match.Team1Penalties != nil && match.Team2Penalties != nil && *match.Team2Penalties > *match.Team1Penalties:
+		return team2
+	}
+	return team1
+}
+
+// rounds groups played, ordered by round and slot, into rounds.
+func rounds(played []types.PlayoffMatch) []types.PlayoffRound {
+	var grouped []types.PlayoffRound
+	for _, match := range played {
+		if match.Played {
+			match.WinnerName = winner(match).name
+		}
+
+		if len(grouped) == 0 || grouped[len(grouped)-1].Round != match.Round {
+			grouped = append(grouped, types.PlayoffRound{Round: match.Round})
+		}
+		last := &grouped[len(grouped)-1]
+		last.Matches = append(last.Matches, match)
+	}
+
+	for i := range grouped {
+		grouped[i].Name = roundName(len(grouped[i].Matches))
+	}
+	return grouped
+}
+
+func roundName(matches int) string {
+	switch matches {
+	case 1:
+		return "Final"
+	case 2:
+		return "Semi-finals"
+	case 4:
+		return "Quarter-finals"
+	}
+	return fmt.Sprintf("Round of %d", 2*matches)
+}
@@ -0,0 +1,98 @@
+package playoff
+
+import (
+	"errors"
+	"football-simulation/types"
+	"testing"
+)
+
+var standings = []types.Team{
+	{ID: 10, Name: "Arsenal"},
+	{ID: 11, Name: "Chelsea"},
+	{ID: 12, Name: "Liverpool"},
+	{ID: 13, Name: "Everton"},
+	{ID: 14, Name: "Fulham"},
+	{ID: 15, Name: "Leeds"},
+}
+
+func TestDrawFirstRound(t *testing.T) {
+	config := types.PlayoffConfig{Pairings: [][2]int{{6, 3}, {4, 5}}}
+
+	matches, err := draw(config, standings, nil, 2)
+	if err != nil {
+		t.Fatalf("draw: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("draw returned %d matches, want 2", len(matches))
+	}
+
+	// the higher seed plays at home whatever the order of the pairing
+	first := matches[0]
+	if first.Season != 2 || first.Round != 1 || first.Slot != 1 || first.Team1ID != 12 || first.Team1Seed != 3 || first.Team2ID != 15 || first.Team2Seed != 6 {
+		t.Errorf("first tie = %+v, want Liverpool (3) hosting Leeds (6)", first)
+	}
+	second := matches[1]
+	if second.Slot != 2 || second.Team1Name != "Everton" || second.Team2Name != "Fulham" {
+		t.Errorf("second tie = %+v, want Everton hosting Fulham", second)
+	}
+}
+
+func TestDrawAdvancesWinners(t *testing.T) {
+	config := types.PlayoffConfig{Pairings: [][2]int{{3, 6}, {4, 5}}}
+	three, four := 3, 4
+
+	played := []types.PlayoffMatch{
+		{Season: 1, Round: 1, Slot: 1, Team1ID: 12, Team1Name: "Liverpool", Team1Seed: 3, Team2ID: 15, Team2Name: "Leeds", Team2Seed: 6,
+			KnockoutResult: types.KnockoutResult{Team1Score: 0, Team2Score: 1}, Played: true},
+		{Season: 1, Round: 1, Slot: 2, Team1ID: 13, Team1Name: "Everton", Team1Seed: 4, Team2ID: 14, Team2Name: "Fulham", Team2Seed: 5,
+			KnockoutResult: types.KnockoutResult{Team1Score: 2, Team2Score: 2, ExtraTime: true, Team1Penalties: &three, Team2Penalties: &four}, Played: true},
+	}
+
+	final, err := draw(config, standings, played, 1)
+	if err != nil {
+		t.Fatalf("draw: %v", err)
+	}
+	if len(final) != 1 {
+		t.Fatalf("draw returned %d matches, want the final", len(final))
+	}
+	// Fulham won on penalties and, as the fifth seed, host sixth-seeded Leeds
+	if match := final[0]; match.Round != 2 || match.Slot != 1 || match.Team1Name != "Fulham" || match.Team2Name != "Leeds" {
+		t.Errorf("final = %+v, want Fulham hosting Leeds", match)
+	}
+
+	final[0].KnockoutResult = types.KnockoutResult{Team1Score: 1, Team2Score: 3}
+	final[0].Played = true
+	if _, err := draw(config, standings, append(played, final...), 1); !errors.Is(err, ErrPlayoffsFinished) {
+		t.Errorf("draw after the final = %v, want %v", err, ErrPlayoffsFinished)
+	}
+
+	grouped := rounds(append(played, final...))
+	if len(grouped) != 2 || grouped[0].Name != "Semi-finals" || grouped[1].Name != "Final" {
+		t.Fatalf("rounds = %+v, want the semi-finals and the final", grouped)
+	}
+	if grouped[0].Matches[1].WinnerName != "Fulham" || grouped[1].Matches[0].WinnerName != "Leeds" {
+		t.Errorf("winners = %q, %q, want Fulham and Leeds", grouped[0].Matches[1].WinnerName, grouped[1].Matches[0].WinnerName)
+	}
+}
+
+func TestDrawInvalidPairings(t *testing.T) {
+	tests := []struct {
+		name     string
+		pairings [][2]int
+		want     error
+	}{
+		{"none", nil, ErrPlayoffsNotConfigured},
+		{"three pairings", [][2]int{{1, 2}, {3, 4}, {5, 6}}, ErrInvalidPlayoffConfig},
+		{"position paired twice", [][2]int{{1, 4}, {2, 4}}, ErrInvalidPlayoffConfig},
+		{"position beyond the league", [][2]int{{1, 7}}, ErrInvalidPlayoffConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := draw(types.PlayoffConfig{Pairings: tt.pairings}, standings, nil, 1)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("draw = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package playoff
+
+import (
+	"football-simulation/types"
+	"football-simulation/utils"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	service types.PlayoffService
+}
+
+func NewHandler(service types.PlayoffService) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/league/playoffs", h.handleGetPlayoffs).Methods("GET")
+	router.HandleFunc("/league/playoffs/play", h.handlePlayRound).Methods("POST")
+	router.HandleFunc("/league/playoffs/config", h.handleGetConfig).Methods("GET")
+	router.HandleFunc("/league/playoffs/config", h.handleSetConfig).Methods("PUT")
+}
+
+func (h *Handler) handleGetPlayoffs(w http.ResponseWriter, r *http.Request) {
+	playoffs, err := h.service.GetPlayoffs()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, playoffs)
+}
+
+func (h *Handler) handlePlayRound(w http.ResponseWriter, r *http.Request) {
+	playoffs, err := h.service.PlayRound()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, playoffs)
+}
+
+func (h *Handler) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	config, err := h.service.GetConfig()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, config)
+}
+
+func (h *Handler) handleSetConfig(w http.ResponseWriter, r *http.Request) {
+	var req types.PlayoffConfig
+	if !utils.ParseAndValidate(w, r, &req) {
+		return
+	}
+
+	config, err := h.service.SetConfig(req)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, config)
+}
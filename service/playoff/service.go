@@ -0,0 +1,237 @@
+package playoff
+
+import (
+	"errors"
+	"fmt"
+	"football-simulation/service/league"
+	"football-simulation/types"
+)
+
+var (
+	ErrPlayoffsNotConfigured = types.NewError(types.ErrorKindConflict, "playoffs_not_configured", "the league has no playoffs, set the pairings first")
+	ErrInvalidPlayoffConfig  = types.NewError(types.ErrorKindValidation, "invalid_playoff_config", "the playoff configuration is invalid")
+	ErrSeasonNotFinished     = types.NewError(types.ErrorKindConflict, "season_not_finished", "the playoffs start once every match of the regular season has been played")
+	ErrPlayoffsFinished      = types.NewError(types.ErrorKindConflict, "playoffs_finished", "the playoffs have been decided, restart the league to play a new season")
+)
+
+type Service struct {
+	store             types.PlayoffStore
+	leagueStore       types.LeagueStore
+	simulationService types.SimulationService
+	eventStore        types.LeagueEventStore
+	transactor        types.Transactor
+}
+
+func NewService(store types.PlayoffStore, leagueStore types.LeagueStore, simulationService types.SimulationService, eventStore types.LeagueEventStore, transactor types.Transactor) *Service {
+	return &Service{
+		store:             store,
+		leagueStore:       leagueStore,
+		simulationService: simulationService,
+		eventStore:        eventStore,
+		transactor:        transactor,
+	}
+}
+
+func (s *Service) WithTx(tx types.DBTX) types.PlayoffService {
+	return s.withTx(tx)
+}
+
+func (s *Service) withTx(tx types.DBTX) *Service {
+	return &Service{
+		store:             s.store.WithTx(tx),
+		leagueStore:       s.leagueStore.WithTx(tx),
+		simulationService: s.simulationService.WithTx(tx),
+		eventStore:        s.eventStore.WithTx(tx),
+		transactor:        s.transactor,
+	}
+}
+
+// GetConfig returns the playoff configuration of the league, without
+// pairings when none has been set.
+func (s *Service) GetConfig() (*types.LeaguePlayoffConfig, error) {
+	league, err := s.leagueStore.GetLeagueInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := s.store.GetPlayoffConfig(league.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	config.LeagueID = league.ID
+	if config.Pairings == nil {
+		config.Pairings = [][2]int{}
+	}
+	return config, nil
+}
+
+// SetConfig replaces the playoff configuration. Playoffs already under way
+// keep their bracket; the pairings apply from the next first round drawn.
+func (s *Service) SetConfig(config types.PlayoffConfig) (*types.LeaguePlayoffConfig, error) {
+	if err := ValidatePairings(config); err != nil {
+		return nil, err
+	}
+
+	league, err := s.leagueStore.GetLeagueInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.SavePlayoffConfig(types.LeaguePlayoffConfig{LeagueID: league.ID, PlayoffConfig: config}); err != nil {
+		return nil, err
+	}
+
+	return s.GetConfig()
+}
+
+// GetPlayoffs returns the bracket of the current season: the rounds played
+// and the next round when it can be drawn.
+func (s *Service) GetPlayoffs() (*types.Playoffs, error) {
+	league, err := s.leagueStore.GetLeagueInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	played, err := s.store.GetPlayoffMatches(league.Season)
+	if err != nil {
+		return nil, err
+	}
+
+	standings, err := s.leagueStore.GetStandings()
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := s.nextRound(league, standings, played)
+	var domainErr *types.Error
+	if err != nil && !errors.As(err, &domainErr) {
+		return nil, err
+	}
+
+	playoffs := &types.Playoffs{
+		Season:           league.Season,
+		Rounds:           rounds(append(played, next...)),
+		ChampionTeamName: league.PlayoffChampionTeamName,
+	}
+	if playoffs.Rounds == nil {
+		playoffs.Rounds = []types.PlayoffRound{}
+	}
+	return playoffs, nil
+}
+
+// PlayRound plays the next round of the playoffs through the match engine
+// and records the winner of the final as the playoff champion.
+func (s *Service) PlayRound() (*types.Playoffs, error) {
+	err := s.transactor.WithinTransaction(func(tx types.DBTX) error {
+		return s.withTx(tx).playRound()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPlayoffs()
+}
+
+func (s *Service) playRound() error {
+	leagueInfo, err := s.leagueStore.GetLeagueInfo()
+	if err != nil {
+		return err
+	}
+
+	locked, err := s.leagueStore.TryLockLeague(leagueInfo.ID)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return league.ErrLeagueBusy
+	}
+
+	played, err := s.store.GetPlayoffMatches(leagueInfo.Season)
+	if err != nil {
+		return err
+	}
+
+	standings, err := s.leagueStore.GetStandings()
+	if err != nil {
+		return err
+	}
+
+	matches, err := s.nextRound(leagueInfo, standings, played)
+	if err != nil {
+		return err
+	}
+
+	teams := make(map[int]types.Team, len(standings))
+	for _, team := range standings {
+		teams[team.ID] = team
+	}
+
+	// every round is played from its own seed, following the weeks of the
+	// season, so its results can be reproduced
+	if leagueInfo.Seed != 0 {
+		s.simulationService.Reseed(leagueInfo.Seed + int64(leagueInfo.TotalWeeks+matches[0].Round))
+	}
+
+	for i, match := range matches {
+		team1, ok1 := teams[match.Team1ID]
+		team2, ok2 := teams[match.Team2ID]
+		if !ok1 || !ok2 {
+			return fmt.Errorf("playoff match %d-%d references a team that no longer exists", match.Team1ID, match.Team2ID)
+		}
+
+		matches[i].KnockoutResult = s.simulationService.PlayKnockout(team1, team2)
+	}
+
+	saved, err := s.store.SavePlayoffMatches(matches)
+	if err != nil {
+		return err
+	}
+
+	if len(saved) > 1 {
+		return nil
+	}
+
+	champion := winner(saved[0])
+	leagueInfo.PlayoffChampionTeamName = champion.name
+	if err := s.leagueStore.UpdateLeague(leagueInfo); err != nil {
+		return err
+	}
+
+	return s.eventStore.AppendEvent(types.EventPlayoffChampionDecided, types.PlayoffChampionDecidedEvent{
+		Season:   leagueInfo.Season,
+		TeamID:   champion.id,
+		TeamName: champion.name,
+	})
+}
+
+// nextRound draws the next round once the regular season is over.
+func (s *Service) nextRound(league types.League, standings []types.Team, played []types.PlayoffMatch) ([]types.PlayoffMatch, error) {
+	config, err := s.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Pairings) == 0 && len(played) == 0 {
+		return nil, ErrPlayoffsNotConfigured
+	}
+
+	matches, err := s.leagueStore.GetAllMatches()
+	if err != nil {
+		return nil, err
+	}
+
+	lastWeek := 0
+	for _, match := range matches {
+		if !match.Played {
+			return nil, ErrSeasonNotFinished
+		}
+		lastWeek = max(lastWeek, match.Week)
+	}
+	// a split league has weeks left before its split rounds are drawn
+	if len(matches) == 0 || lastWeek < league.TotalWeeks {
+		return nil, ErrSeasonNotFinished
+	}
+
+	return draw(config.PlayoffConfig, standings, played, league.Season)
+}
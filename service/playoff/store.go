@@ -0,0 +1,143 @@
+package playoff
+
+import (
+	"database/sql"
+	"encoding/json"
+	"football-simulation/types"
+	"time"
+)
+
+type Store struct {
+	db types.DBTX
+}
+
+func NewStore(db types.DBTX) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) WithTx(tx types.DBTX) types.PlayoffStore {
+	return &Store{db: tx}
+}
+
+// GetPlayoffConfig returns the playoff configuration of the league, or an
+// empty one when none has been saved.
+func (s *Store) GetPlayoffConfig(leagueID int) (*types.LeaguePlayoffConfig, error) {
+	rows, err := s.db.Query("SELECT league_id, config, updated_at FROM league_playoff_config WHERE league_id = $1", leagueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	config := &types.LeaguePlayoffConfig{}
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&config.LeagueID, &data, &config.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(data, &config.PlayoffConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// SavePlayoffConfig creates or replaces the configuration of config.LeagueID.
+func (s *Store) SavePlayoffConfig(config types.LeaguePlayoffConfig) error {
+	data, err := json.Marshal(config.PlayoffConfig)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`INSERT INTO league_playoff_config (league_id, config, updated_at) VALUES ($1, $2, $3)
+		ON CONFLICT (league_id) DO UPDATE SET config = $2, updated_at = $3`,
+		config.LeagueID, data, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) GetPlayoffMatches(season int) ([]types.PlayoffMatch, error) {
+	rows, err := s.db.Query(`SELECT id, season, round, slot, team1_id, team1_name, team1_seed, team2_id, team2_name, team2_seed,
+		team1_score, team2_score, extra_time, team1_penalties, team2_penalties
+		FROM playoff_matches WHERE season = $1 ORDER BY round, slot`, season)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []types.PlayoffMatch{}
+	for rows.Next() {
+		var match types.PlayoffMatch
+		var team1Penalties, team2Penalties sql.NullInt64
+		err := rows.Scan(
+			&match.ID,
+			&match.Season,
+			&match.Round,
+			&match.Slot,
+			&match.Team1ID,
+			&match.Team1Name,
+			&match.Team1Seed,
+			&match.Team2ID,
+			&match.Team2Name,
+			&match.Team2Seed,
+			&match.Team1Score,
+			&match.Team2Score,
+			&match.ExtraTime,
+			&team1Penalties,
+			&team2Penalties,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if team1Penalties.Valid && team2Penalties.Valid {
+			team1, team2 := int(team1Penalties.Int64), int(team2Penalties.Int64)
+			match.Team1Penalties, match.Team2Penalties = &team1, &team2
+		}
+		// matches are saved once played
+		match.Played = true
+		matches = append(matches, match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// SavePlayoffMatches saves played matches and returns them with their ids.
+func (s *Store) SavePlayoffMatches(matches []types.PlayoffMatch) ([]types.PlayoffMatch, error) {
+	saved := make([]types.PlayoffMatch, 0, len(matches))
+	for _, match := range matches {
+		err := s.db.QueryRow(`INSERT INTO playoff_matches (season, round, slot, team1_id, team1_name, team1_seed, team2_id, team2_name, team2_seed,
+			team1_score, team2_score, extra_time, team1_penalties, team2_penalties)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id`,
+			match.Season, match.Round, match.Slot, match.Team1ID, match.Team1Name, match.Team1Seed, match.Team2ID, match.Team2Name, match.Team2Seed,
+			match.Team1Score, match.Team2Score, match.ExtraTime, match.Team1Penalties, match.Team2Penalties,
+		).Scan(&match.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		match.Played = true
+		saved = append(saved, match)
+	}
+
+	return saved, nil
+}
+
+func (s *Store) DeletePlayoffMatches(fromSeason int) error {
+	_, err := s.db.Exec("DELETE FROM playoff_matches WHERE season >= $1", fromSeason)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package sanction
+
+import (
+	"football-simulation/types"
+	"football-simulation/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	service types.SanctionService
+}
+
+func NewHandler(service types.SanctionService) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/league/sanctions", h.handleApplySanction).Methods("POST")
+	router.HandleFunc("/league/sanctions", h.handleGetSanctions).Methods("GET")
+	router.HandleFunc("/teams/{id}/sanctions", h.handleGetTeamSanctions).Methods("GET")
+}
+
+func (h *Handler) handleApplySanction(w http.ResponseWriter, r *http.Request) {
+	var req types.SanctionRequest
+	if !utils.ParseAndValidate(w, r, &req) {
+		return
+	}
+
+	sanction, err := h.service.ApplySanction(req)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusCreated, sanction)
+}
+
+func (h *Handler) handleGetSanctions(w http.ResponseWriter, r *http.Request) {
+	sanctions, err := h.service.GetSanctions()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, sanctions)
+}
+
+func (h *Handler) handleGetTeamSanctions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	teamID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sanctions, err := h.service.GetSanctionsByTeam(teamID)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, sanctions)
+}
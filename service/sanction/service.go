@@ -0,0 +1,215 @@
+package sanction
+
+import (
+	"football-simulation/service/league"
+	"football-simulation/service/team"
+	"football-simulation/types"
+)
+
+// ErrInvalidSanction is returned for sanctions that cannot apply to the team.
+var ErrInvalidSanction = types.NewError(types.ErrorKindValidation, "invalid_sanction", "invalid sanction")
+
+// forfeitScore is the result awarded to the opponent of a team that forfeits a match.
+const forfeitScore = 3
+
+type Service struct {
+	store          types.SanctionStore
+	leagueStore    types.LeagueStore
+	teamService    types.TeamService
+	eventStore     types.LeagueEventStore
+	webhookService types.WebhookService
+	transactor     types.Transactor
+}
+
+func NewService(store types.SanctionStore, leagueStore types.LeagueStore, teamService types.TeamService, eventStore types.LeagueEventStore, webhookService types.WebhookService, transactor types.Transactor) *Service {
+	return &Service{
+		store:          store,
+		leagueStore:    leagueStore,
+		teamService:    teamService,
+		eventStore:     eventStore,
+		webhookService: webhookService,
+		transactor:     transactor,
+	}
+}
+
+// ApplySanction records the sanction and adjusts the standings in a single
+// transaction.
+func (s *Service) ApplySanction(request types.SanctionRequest) (*types.Sanction, error) {
+	var sanction *types.Sanction
+
+	err := s.transactor.WithinTransaction(func(tx types.DBTX) error {
+		txService := &Service{
+			store:          s.store.WithTx(tx),
+			leagueStore:    s.leagueStore.WithTx(tx),
+			teamService:    s.teamService.WithTx(tx),
+			eventStore:     s.eventStore.WithTx(tx),
+			webhookService: s.webhookService.WithTx(tx),
+		}
+
+		leagueInfo, err := txService.leagueStore.GetLeagueInfo()
+		if err != nil {
+			return err
+		}
+
+		locked, err := txService.leagueStore.TryLockLeague(leagueInfo.ID)
+		if err != nil {
+			return err
+		}
+
+		if !locked {
+			return league.ErrLeagueBusy
+		}
+
+		sanction, err = txService.applySanction(request)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sanction, nil
+}
+
+func (s *Service) applySanction(request types.SanctionRequest) (*types.Sanction, error) {
+	sanctioned, err := s.teamService.GetTeamByID(request.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	if sanctioned.ID == 0 {
+		return nil, team.ErrTeamNotFound.Errorf("team %d not found", request.TeamID)
+	}
+
+	sanction := types.Sanction{
+		TeamID: sanctioned.ID,
+		Type:   request.Type,
+		Reason: request.Reason,
+	}
+
+	switch request.Type {
+	case types.SanctionPointsDeduction:
+		err = s.deductPoints(*sanctioned, request.Points)
+		sanction.Points = request.Points
+	case types.SanctionForfeit:
+		err = s.forfeitMatch(*sanctioned, request.MatchID, request.Reason)
+		sanction.MatchID = request.MatchID
+	default:
+		err = ErrInvalidSanction.Errorf("unknown sanction type %q", request.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.CreateSanction(sanction)
+}
+
+func (s *Service) GetSanctions() ([]types.Sanction, error) {
+	sanctions, err := s.store.GetSanctions()
+	if err != nil {
+		return nil, err
+	}
+
+	return sanctions, nil
+}
+
+func (s *Service) GetSanctionsByTeam(teamID int) ([]types.Sanction, error) {
+	sanctioned, err := s.teamService.GetTeamByID(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	if sanctioned.ID == 0 {
+		return nil, team.ErrTeamNotFound.Errorf("team %d not found", teamID)
+	}
+
+	sanctions, err := s.store.GetSanctionsByTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	return sanctions, nil
+}
+
+func (s *Service) deductPoints(team types.Team, points int) error {
+	team.Points -= points
+	team.PointsDeducted += points
+
+	if err := s.teamService.UpdateTeam(team); err != nil {
+		return err
+	}
+
+	return s.eventStore.AppendEvent(types.EventPointsDeducted, types.PointsDeductedEvent{TeamID: team.ID, Points: points})
+}
+
+// forfeitMatch awards the match to the opponent of team by a 3-0 scoreline,
+// replacing any result that was already recorded. Subscribers are notified
+// as for a manual edit.
+func (s *Service) forfeitMatch(team types.Team, matchID int, reason string) error {
+	match, err := s.leagueStore.GetMatchByID(matchID)
+	if err != nil {
+		return err
+	}
+
+	if match.ID == 0 {
+		return league.ErrMatchNotFound.Errorf("match %d not found", matchID)
+	}
+
+	if match.Team1ID != team.ID && match.Team2ID != team.ID {
+		return ErrInvalidSanction.Errorf("team %d did not take part in match %d", team.ID, matchID)
+	}
+
+	before := *match
+	if match.Played {
+		if err := s.teamService.UpdateTeamStatsReverse(*match, true); err != nil {
+			return err
+		}
+	}
+
+	match.Team1Score, match.Team2Score = forfeitScore, 0
+	if match.Team1ID == team.ID {
+		match.Team1Score, match.Team2Score = 0, forfeitScore
+	}
+	match.Played = true
+
+	if err := s.leagueStore.UpdateMatch(*match); err != nil {
+		return err
+	}
+
+	team1, err := s.teamService.GetTeamByID(match.Team1ID)
+	if err != nil {
+		return err
+	}
+
+	team2, err := s.teamService.GetTeamByID(match.Team2ID)
+	if err != nil {
+		return err
+	}
+
+	err = s.teamService.UpdateTeamStats(*team1, *team2, match.Team1Score, match.Team2Score, before.Played)
+	if err != nil {
+		return err
+	}
+
+	event := types.MatchEvent{
+		MatchID:          match.ID,
+		BeforeTeam1Score: before.Team1Score,
+		BeforeTeam2Score: before.Team2Score,
+		BeforePlayed:     before.Played,
+		AfterTeam1Score:  match.Team1Score,
+		AfterTeam2Score:  match.Team2Score,
+		AfterPlayed:      match.Played,
+		Actor:            types.SanctionActor,
+		Reason:           reason,
+	}
+
+	if err := s.leagueStore.SaveMatchEvent(event); err != nil {
+		return err
+	}
+
+	if err := s.eventStore.AppendEvent(types.EventResultCorrected, event); err != nil {
+		return err
+	}
+
+	return s.webhookService.Enqueue(types.WebhookResultEdited, event)
+}
@@ -0,0 +1,125 @@
+package sanction_test
+
+import (
+	"errors"
+	"football-simulation/database/memory"
+	"football-simulation/service/sanction"
+	"football-simulation/service/team"
+	"football-simulation/types"
+	"testing"
+)
+
+// recordingWebhooks records the events enqueued, failing with err when set.
+type recordingWebhooks struct {
+	types.WebhookService
+	err    error
+	events []string
+	last   any
+}
+
+func (w *recordingWebhooks) WithTx(tx types.DBTX) types.WebhookService {
+	return w
+}
+
+func (w *recordingWebhooks) Enqueue(eventType string, payload any) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.events = append(w.events, eventType)
+	w.last = payload
+	return nil
+}
+
+// newService returns a sanction service on a league of Chelsea hosting
+// Arsenal in match 1, already played and won 2-1 by Chelsea.
+func newService(t *testing.T, webhooks types.WebhookService) (*sanction.Service, *memory.DB) {
+	t.Helper()
+
+	db := memory.NewDB()
+	teamStore, leagueStore, eventStore := memory.NewTeamStore(db), memory.NewLeagueStore(db), memory.NewEventStore(db)
+	transactor := memory.NewTransactor(db)
+	teamService := team.NewService(teamStore, leagueStore, eventStore, transactor)
+
+	chelsea, err := teamStore.CreateTeam(types.Team{Name: "Chelsea", Strength: 80})
+	if err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+	arsenal, err := teamStore.CreateTeam(types.Team{Name: "Arsenal", Strength: 85})
+	if err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+
+	matches, err := memory.NewSimulationStore(db).SaveFixture([]types.Match{{Week: 1, Team1ID: chelsea.ID, Team2ID: arsenal.ID}})
+	if err != nil {
+		t.Fatalf("SaveFixture: %v", err)
+	}
+	match := matches[0]
+	match.Team1Score, match.Team2Score, match.Played = 2, 1, true
+	if err := leagueStore.UpdateMatch(match); err != nil {
+		t.Fatalf("UpdateMatch: %v", err)
+	}
+	if err := teamService.UpdateTeamStats(*chelsea, *arsenal, 2, 1, false); err != nil {
+		t.Fatalf("UpdateTeamStats: %v", err)
+	}
+
+	service := sanction.NewService(memory.NewSanctionStore(db), leagueStore, teamService, eventStore, webhooks, transactor)
+	return service, db
+}
+
+func forfeit(service *sanction.Service) error {
+	_, err := service.ApplySanction(types.SanctionRequest{TeamID: 1, Type: types.SanctionForfeit, MatchID: 1, Reason: "fielded a suspended player"})
+	return err
+}
+
+func TestForfeitNotifiesResultEdited(t *testing.T) {
+	webhooks := &recordingWebhooks{}
+	service, db := newService(t, webhooks)
+
+	if err := forfeit(service); err != nil {
+		t.Fatalf("ApplySanction: %v", err)
+	}
+
+	if len(webhooks.events) != 1 || webhooks.events[0] != types.WebhookResultEdited {
+		t.Fatalf("enqueued %v, want one %s", webhooks.events, types.WebhookResultEdited)
+	}
+	event, ok := webhooks.last.(types.MatchEvent)
+	if !ok {
+		t.Fatalf("payload = %T, want a match history entry", webhooks.last)
+	}
+	if event.MatchID != 1 || event.BeforeTeam1Score != 2 || event.AfterTeam1Score != 0 || event.AfterTeam2Score != 3 || event.Actor != types.SanctionActor {
+		t.Errorf("payload = %+v, want the 2-1 result replaced by a 0-3 forfeit", event)
+	}
+
+	match, err := memory.NewLeagueStore(db).GetMatchByID(1)
+	if err != nil {
+		t.Fatalf("GetMatchByID: %v", err)
+	}
+	if match.Team1Score != 0 || match.Team2Score != 3 {
+		t.Errorf("match = %d-%d, want 0-3", match.Team1Score, match.Team2Score)
+	}
+}
+
+func TestForfeitRollsBackWhenNotificationFails(t *testing.T) {
+	errQueue := errors.New("queue unavailable")
+	service, db := newService(t, &recordingWebhooks{err: errQueue})
+
+	if err := forfeit(service); !errors.Is(err, errQueue) {
+		t.Fatalf("ApplySanction = %v, want %v", err, errQueue)
+	}
+
+	match, err := memory.NewLeagueStore(db).GetMatchByID(1)
+	if err != nil {
+		t.Fatalf("GetMatchByID: %v", err)
+	}
+	if match.Team1Score != 2 || match.Team2Score != 1 {
+		t.Errorf("match = %d-%d, want the 2-1 result kept", match.Team1Score, match.Team2Score)
+	}
+
+	sanctions, err := service.GetSanctions()
+	if err != nil {
+		t.Fatalf("GetSanctions: %v", err)
+	}
+	if len(sanctions) != 0 {
+		t.Errorf("sanctions = %+v, want none recorded", sanctions)
+	}
+}
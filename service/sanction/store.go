@@ -0,0 +1,83 @@
+package sanction
+
+import (
+	"database/sql"
+	"football-simulation/types"
+)
+
+type Store struct {
+	db types.DBTX
+}
+
+func NewStore(db types.DBTX) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) WithTx(tx types.DBTX) types.SanctionStore {
+	return &Store{db: tx}
+}
+
+func (s *Store) CreateSanction(sanction types.Sanction) (*types.Sanction, error) {
+	err := s.db.QueryRow(`INSERT INTO sanctions (team_id, type, points, match_id, season, reason)
+	VALUES ($1, $2, $3, NULLIF($4, 0), (SELECT season FROM league LIMIT 1), $5)
+	RETURNING id, season, created_at`,
+		sanction.TeamID, sanction.Type, sanction.Points, sanction.MatchID, sanction.Reason).Scan(&sanction.ID, &sanction.Season, &sanction.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &sanction, nil
+}
+
+func (s *Store) GetSanctions() ([]types.Sanction, error) {
+	rows, err := s.db.Query("SELECT id, team_id, type, points, match_id, season, reason, created_at FROM sanctions ORDER BY created_at, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRowsIntoSanctions(rows)
+}
+
+func (s *Store) GetSanctionsByTeam(teamID int) ([]types.Sanction, error) {
+	rows, err := s.db.Query("SELECT id, team_id, type, points, match_id, season, reason, created_at FROM sanctions WHERE team_id = $1 ORDER BY created_at, id", teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRowsIntoSanctions(rows)
+}
+
+func scanRowsIntoSanctions(rows *sql.Rows) ([]types.Sanction, error) {
+	sanctions := make([]types.Sanction, 0)
+
+	for rows.Next() {
+		sanction := types.Sanction{}
+		var matchID sql.NullInt64
+		err := rows.Scan(
+			&sanction.ID,
+			&sanction.TeamID,
+			&sanction.Type,
+			&sanction.Points,
+			&matchID,
+			&sanction.Season,
+			&sanction.Reason,
+			&sanction.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if matchID.Valid {
+			sanction.MatchID = int(matchID.Int64)
+		}
+
+		sanctions = append(sanctions, sanction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sanctions, nil
+}
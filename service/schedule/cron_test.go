@@ -0,0 +1,48 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// a Wednesday
+	from := time.Date(2024, time.July, 31, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		expression string
+		want       time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.July, 31, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.July, 31, 10, 30, 0, 0, time.UTC)},
+		{"0 20 * * fri", time.Date(2024, time.August, 2, 20, 0, 0, 0, time.UTC)},
+		{"30 9-17/4 * * 1-5", time.Date(2024, time.July, 31, 13, 30, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2024, time.August, 4, 12, 0, 0, 0, time.UTC)},
+		{"0 12 29 2 *", time.Date(2028, time.February, 29, 12, 0, 0, 0, time.UTC)},
+		// day of month or day of week when both are restricted
+		{"0 8 15 * mon", time.Date(2024, time.August, 5, 8, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, test := range tests {
+		c, err := parseCron(test.expression)
+		if err != nil {
+			t.Errorf("parseCron(%q): %v", test.expression, err)
+			continue
+		}
+
+		if got := c.Next(from); !got.Equal(test.want) {
+			t.Errorf("parseCron(%q).Next = %v, want %v", test.expression, got, test.want)
+		}
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "5-1 * * * *", "*/0 * * * *", "* * * * funday"} {
+		if _, err := parseCron(expression); err == nil {
+			t.Errorf("parseCron(%q) succeeded, want an error", expression)
+		}
+	}
+}
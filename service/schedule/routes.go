@@ -0,0 +1,79 @@
+package schedule
+
+import (
+	"football-simulation/types"
+	"football-simulation/utils"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	service types.ScheduleService
+}
+
+func NewHandler(service types.ScheduleService) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/league/schedule", h.handleGetSchedule).Methods("GET")
+	router.HandleFunc("/league/schedule", h.handleSetSchedule).Methods("PUT")
+	router.HandleFunc("/league/schedule", h.handleDeleteSchedule).Methods("DELETE")
+	router.HandleFunc("/league/schedule/pause", h.handlePauseSchedule).Methods("POST")
+	router.HandleFunc("/league/schedule/resume", h.handleResumeSchedule).Methods("POST")
+}
+
+func (h *Handler) handleGetSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := h.service.GetSchedule()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, schedule)
+}
+
+func (h *Handler) handleSetSchedule(w http.ResponseWriter, r *http.Request) {
+	var req types.ScheduleRequest
+	if !utils.ParseAndValidate(w, r, &req) {
+		return
+	}
+
+	schedule, err := h.service.SetSchedule(req)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, schedule)
+}
+
+func (h *Handler) handleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteSchedule(); err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, nil)
+}
+
+func (h *Handler) handlePauseSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := h.service.Pause()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, schedule)
+}
+
+func (h *Handler) handleResumeSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := h.service.Resume()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, schedule)
+}
@@ -0,0 +1,91 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"football-simulation/service/league"
+	"football-simulation/types"
+	"log"
+	"time"
+)
+
+// Scheduler plays the next week whenever the schedule of the league is due.
+// Every server instance runs one; claiming a run in the store makes sure
+// only one of them plays it.
+type Scheduler struct {
+	store         types.ScheduleStore
+	leagueService types.LeagueService
+	interval      time.Duration
+}
+
+func NewScheduler(store types.ScheduleStore, leagueService types.LeagueService, interval time.Duration) *Scheduler {
+	return &Scheduler{store: store, leagueService: leagueService, interval: interval}
+}
+
+// Run checks the schedule every interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunDue(); err != nil {
+			log.Printf("league scheduler: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue plays the next week if the schedule is due. Runs missed while no
+// server was running are not caught up: one week is played and the schedule
+// moves on to its next run after now.
+func (s *Scheduler) RunDue() error {
+	leagueInfo, err := s.leagueService.GetLeague()
+	if err != nil {
+		return err
+	}
+
+	schedule, err := s.store.GetSchedule(leagueInfo.ID)
+	if err != nil {
+		return err
+	}
+
+	current := now()
+	if schedule.LeagueID == 0 || schedule.Paused || schedule.NextRunAt == nil || schedule.NextRunAt.After(current) {
+		return nil
+	}
+
+	c, err := parseCadence(*schedule)
+	if err != nil {
+		return err
+	}
+
+	following := c.Next(*schedule.NextRunAt)
+	if !following.After(current) {
+		following = c.Next(current)
+	}
+
+	claimed, err := s.store.ClaimRun(schedule.LeagueID, *schedule.NextRunAt, following)
+	if err != nil || !claimed {
+		return err
+	}
+
+	runErr := ""
+	_, _, err = s.leagueService.NextWeek(0)
+	if err != nil {
+		runErr = err.Error()
+	}
+
+	// a finished league has nothing left to play until it is restarted
+	if errors.Is(err, league.ErrLeagueFinished) {
+		if err := s.store.SetPaused(schedule.LeagueID, true, nil); err != nil {
+			return err
+		}
+	}
+
+	return s.store.RecordRun(schedule.LeagueID, current, runErr)
+}
@@ -0,0 +1,175 @@
+package schedule
+
+import (
+	"football-simulation/types"
+	"time"
+)
+
+// minInterval keeps a fixed interval from playing a whole season in seconds.
+const minInterval = 10 * time.Second
+
+var (
+	ErrScheduleNotFound = types.NewError(types.ErrorKindNotFound, "schedule_not_found", "the league has no schedule")
+	ErrInvalidSchedule  = types.NewError(types.ErrorKindValidation, "invalid_schedule", "the schedule needs a valid cron expression or interval")
+)
+
+// cadence returns the next run after a given time.
+type cadence interface {
+	Next(after time.Time) time.Time
+}
+
+type interval time.Duration
+
+func (i interval) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(i))
+}
+
+type Service struct {
+	store         types.ScheduleStore
+	leagueService types.LeagueService
+}
+
+func NewService(store types.ScheduleStore, leagueService types.LeagueService) *Service {
+	return &Service{store: store, leagueService: leagueService}
+}
+
+func (s *Service) GetSchedule() (*types.LeagueSchedule, error) {
+	league, err := s.leagueService.GetLeague()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getSchedule(league.ID)
+}
+
+// SetSchedule replaces the schedule of the league and starts it, the first
+// run being the next one of the new cadence.
+func (s *Service) SetSchedule(request types.ScheduleRequest) (*types.LeagueSchedule, error) {
+	league, err := s.leagueService.GetLeague()
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.store.GetSchedule(league.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.LeagueID = league.ID
+	schedule.Cron = request.Cron
+	schedule.Interval = request.Interval
+	schedule.Paused = false
+	schedule.LastError = ""
+
+	next, err := firstRun(*schedule)
+	if err != nil {
+		return nil, err
+	}
+	schedule.NextRunAt = &next
+
+	if err := s.store.SaveSchedule(*schedule); err != nil {
+		return nil, err
+	}
+
+	return s.getSchedule(league.ID)
+}
+
+func (s *Service) DeleteSchedule() error {
+	schedule, err := s.GetSchedule()
+	if err != nil {
+		return err
+	}
+
+	return s.store.DeleteSchedule(schedule.LeagueID)
+}
+
+// Pause stops the schedule until it is resumed. A run already in progress
+// completes.
+func (s *Service) Pause() (*types.LeagueSchedule, error) {
+	schedule, err := s.GetSchedule()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.SetPaused(schedule.LeagueID, true, nil); err != nil {
+		return nil, err
+	}
+
+	return s.getSchedule(schedule.LeagueID)
+}
+
+// Resume restarts a paused schedule from now; runs missed while it was
+// paused are not caught up.
+func (s *Service) Resume() (*types.LeagueSchedule, error) {
+	schedule, err := s.GetSchedule()
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := firstRun(*schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.SetPaused(schedule.LeagueID, false, &next); err != nil {
+		return nil, err
+	}
+
+	return s.getSchedule(schedule.LeagueID)
+}
+
+func (s *Service) getSchedule(leagueID int) (*types.LeagueSchedule, error) {
+	schedule, err := s.store.GetSchedule(leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule.LeagueID == 0 {
+		return nil, ErrScheduleNotFound
+	}
+	return schedule, nil
+}
+
+// firstRun returns the first run of schedule from now.
+func firstRun(schedule types.LeagueSchedule) (time.Time, error) {
+	c, err := parseCadence(schedule)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	next := c.Next(now())
+	if next.IsZero() {
+		return time.Time{}, ErrInvalidSchedule.Errorf("the cron expression %q never matches", schedule.Cron)
+	}
+	return next, nil
+}
+
+func parseCadence(schedule types.LeagueSchedule) (cadence, error) {
+	if schedule.Cron != "" && schedule.Interval != "" {
+		return nil, ErrInvalidSchedule.Errorf("the schedule needs either a cron expression or an interval, not both")
+	}
+
+	if schedule.Cron != "" {
+		c, err := parseCron(schedule.Cron)
+		if err != nil {
+			return nil, ErrInvalidSchedule.Errorf("invalid cron expression %q: %v", schedule.Cron, err)
+		}
+		return c, nil
+	}
+
+	every, err := time.ParseDuration(schedule.Interval)
+	if err != nil {
+		return nil, ErrInvalidSchedule.Errorf("invalid interval %q, use a duration like 90s, 30m or 24h", schedule.Interval)
+	}
+
+	if every < minInterval {
+		return nil, ErrInvalidSchedule.Errorf("the interval must be at least %s", minInterval)
+	}
+	return interval(every), nil
+}
+
+// now is the current time as stored by both SQL backends, which keep
+// microseconds.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
@@ -0,0 +1,118 @@
+package schedule
+
+import (
+	"database/sql"
+	"football-simulation/types"
+	"time"
+)
+
+type Store struct {
+	db types.DBTX
+}
+
+func NewStore(db types.DBTX) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) WithTx(tx types.DBTX) types.ScheduleStore {
+	return &Store{db: tx}
+}
+
+// GetSchedule returns the schedule of the league, or an empty schedule when
+// none has been set.
+func (s *Store) GetSchedule(leagueID int) (*types.LeagueSchedule, error) {
+	rows, err := s.db.Query(`SELECT league_id, cron, run_interval, paused, next_run_at, last_run_at, last_error, updated_at
+		FROM league_schedule WHERE league_id = $1`, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedule := &types.LeagueSchedule{}
+	for rows.Next() {
+		var nextRunAt, lastRunAt sql.NullTime
+		err := rows.Scan(
+			&schedule.LeagueID,
+			&schedule.Cron,
+			&schedule.Interval,
+			&schedule.Paused,
+			&nextRunAt,
+			&lastRunAt,
+			&schedule.LastError,
+			&schedule.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if nextRunAt.Valid {
+			schedule.NextRunAt = &nextRunAt.Time
+		}
+		if lastRunAt.Valid {
+			schedule.LastRunAt = &lastRunAt.Time
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// SaveSchedule creates or replaces the schedule of schedule.LeagueID.
+func (s *Store) SaveSchedule(schedule types.LeagueSchedule) error {
+	_, err := s.db.Exec(`INSERT INTO league_schedule (league_id, cron, run_interval, paused, next_run_at, last_run_at, last_error, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (league_id) DO UPDATE SET cron = $2, run_interval = $3, paused = $4, next_run_at = $5,
+			last_run_at = $6, last_error = $7, updated_at = $8`,
+		schedule.LeagueID, schedule.Cron, schedule.Interval, schedule.Paused, schedule.NextRunAt,
+		schedule.LastRunAt, schedule.LastError, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) DeleteSchedule(leagueID int) error {
+	_, err := s.db.Exec("DELETE FROM league_schedule WHERE league_id = $1", leagueID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) SetPaused(leagueID int, paused bool, nextRunAt *time.Time) error {
+	_, err := s.db.Exec("UPDATE league_schedule SET paused = $1, next_run_at = $2, updated_at = $3 WHERE league_id = $4",
+		paused, nextRunAt, time.Now().UTC(), leagueID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ClaimRun moves the next run of an active schedule from nextRunAt to
+// followingRunAt. Only one of several server instances polling the same
+// schedule sees true, so every run happens once.
+func (s *Store) ClaimRun(leagueID int, nextRunAt, followingRunAt time.Time) (bool, error) {
+	result, err := s.db.Exec("UPDATE league_schedule SET next_run_at = $1 WHERE league_id = $2 AND paused = $3 AND next_run_at = $4",
+		followingRunAt, leagueID, false, nextRunAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (s *Store) RecordRun(leagueID int, ranAt time.Time, runErr string) error {
+	_, err := s.db.Exec("UPDATE league_schedule SET last_run_at = $1, last_error = $2 WHERE league_id = $3",
+		ranAt, runErr, leagueID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
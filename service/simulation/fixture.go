@@ -0,0 +1,426 @@
+package simulation
+
+import (
+	"fmt"
+	"football-simulation/types"
+	"math/rand"
+	"strings"
+)
+
+const (
+	defaultCycles         = 2
+	defaultMaxConsecutive = 2
+	// fixtureAttempts is how many team orders are tried before settling for
+	// the fixture that breaks the fewest constraints.
+	fixtureAttempts = 50
+	// improvePasses bounds the moves tried on one team order.
+	improvePasses = 10
+	byeTeamID     = -1
+)
+
+// pairing is one game of a first-half round, as indexes into the team order.
+// The teams swap home and away in the mirrored second half.
+type pairing struct {
+	home, away int
+}
+
+// candidate is a round robin under construction: rounds holds the first
+// cycle in week order, every other cycle mirrors it and the others repeat it.
+// weeks is the length of the fixture, shorter than the cycles when the
+// league splits.
+type candidate struct {
+	teams          []types.Team
+	rounds         [][]pairing
+	weeks          int
+	pinned         []bool
+	maxConsecutive int
+	stadiums       [][2]int
+	derbies        []derbyWeek
+}
+
+type derbyWeek struct {
+	derby      types.Derby
+	team1      int
+	team2      int
+	week       int
+	teamsKnown bool
+}
+
+// planFixture searches for a round robin satisfying config. The first
+// attempt keeps the team order unless config asks for a shuffle; later
+// attempts shuffle it. Every attempt places the derbies first and then moves
+// rounds and swaps home and away teams while that breaks fewer constraints.
+func planFixture(rng *rand.Rand, teams []types.Team, config types.FixtureConfig) ([]types.Match, *types.FixtureReport) {
+	order := append([]types.Team(nil), teams...)
+	// for scalability: If the number of teams is odd, add a dummy team.
+	if len(order)%2 != 0 {
+		order = append(order, types.Team{ID: byeTeamID, Name: "BYE"})
+	}
+
+	if len(order) < 2 {
+		return nil, &types.FixtureReport{Satisfied: true, Unmet: []types.UnmetConstraint{}}
+	}
+
+	var best *candidate
+	bestCost := 0
+	attempts := 0
+
+	for attempt := 0; attempt < fixtureAttempts; attempt++ {
+		attempts++
+
+		shuffle := attempt > 0 || config.Shuffle
+		if shuffle {
+			rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		}
+
+		c := newCandidate(order, config)
+		if shuffle {
+			c.orderRounds(rng)
+		} else {
+			c.orderRounds(nil)
+		}
+
+		cost := c.improve(rng, shuffle)
+		if best == nil || cost < bestCost {
+			best, bestCost = c, cost
+		}
+		if bestCost == 0 {
+			break
+		}
+	}
+
+	_, unmet := best.check(true)
+	return best.matches(), &types.FixtureReport{Attempts: attempts, Satisfied: len(unmet) == 0, Unmet: unmet}
+}
+
+// newCandidate builds the circle method rotation of teams.
+func newCandidate(teams []types.Team, config types.FixtureConfig) *candidate {
+	c := &candidate{
+		teams:          append([]types.Team(nil), teams...),
+		maxConsecutive: config.MaxConsecutive,
+	}
+	if c.maxConsecutive == 0 {
+		c.maxConsecutive = defaultMaxConsecutive
+	}
+
+	numTeams := len(c.teams)
+	halfSeasonWeeks := numTeams - 1
+	weekMatches := numTeams / 2
+
+	cycles := config.Cycles
+	if cycles == 0 {
+		cycles = defaultCycles
+	}
+	c.weeks = cycles * halfSeasonWeeks
+	if config.SplitAfter > 0 && config.SplitAfter < c.weeks {
+		c.weeks = config.SplitAfter
+	}
+
+	for week := 0; week < halfSeasonWeeks; week++ {
+		round := make([]pairing, 0, weekMatches)
+		for match := 0; match < weekMatches; match++ {
+			home := (week + match) % (numTeams - 1)
+			away := (numTeams - 1 - match + week) % (numTeams - 1)
+
+			if match == 0 {
+				away = numTeams - 1
+			}
+
+			round = append(round, pairing{home: home, away: away})
+		}
+		c.rounds = append(c.rounds, round)
+	}
+
+	// teams share a stadium when their "stadium" metadata matches
+	byStadium := make(map[string][]int)
+	for i, team := range c.teams {
+		if stadium := strings.ToLower(strings.TrimSpace(team.Metadata["stadium"])); stadium != "" && team.ID != byeTeamID {
+			byStadium[stadium] = append(byStadium[stadium], i)
+		}
+	}
+	for i := range c.teams {
+		stadium := strings.ToLower(strings.TrimSpace(c.teams[i].Metadata["stadium"]))
+		for _, j := range byStadium[stadium] {
+			if i < j {
+				c.stadiums = append(c.stadiums, [2]int{i, j})
+			}
+		}
+	}
+
+	index := make(map[int]int, numTeams)
+	for i, team := range c.teams {
+		index[team.ID] = i
+	}
+	for _, derby := range config.Derbies {
+		team1, ok1 := index[derby.Team1ID]
+		team2, ok2 := index[derby.Team2ID]
+		c.derbies = append(c.derbies, derbyWeek{
+			derby:      derby,
+			team1:      team1,
+			team2:      team2,
+			week:       derby.Week - 1,
+			teamsKnown: ok1 && ok2 && derby.Team1ID != byeTeamID && derby.Team2ID != byeTeamID,
+		})
+	}
+
+	return c
+}
+
+// orderRounds moves the rounds holding a derby to the week of the derby, a
+// derby in a later cycle moving the round it repeats, and pins them there.
+// The other rounds keep their order, or are shuffled when rng is set.
+func (c *candidate) orderRounds(rng *rand.Rand) {
+	halfSeasonWeeks := len(c.rounds)
+	ordered := make([][]pairing, halfSeasonWeeks)
+	placed := make([]bool, halfSeasonWeeks)
+
+	for _, d := range c.derbies {
+		if !d.teamsKnown || d.week >= c.weeks {
+			continue
+		}
+
+		position := d.week % halfSeasonWeeks
+		round := c.roundOf(d.team1, d.team2)
+		if round >= 0 && ordered[position] == nil && !placed[round] {
+			ordered[position] = c.rounds[round]
+			placed[round] = true
+		}
+	}
+
+	var free []int
+	for round := range c.rounds {
+		if !placed[round] {
+			free = append(free, round)
+		}
+	}
+	if rng != nil {
+		rng.Shuffle(len(free), func(i, j int) { free[i], free[j] = free[j], free[i] })
+	}
+
+	for position := range ordered {
+		if ordered[position] == nil {
+			ordered[position] = c.rounds[free[0]]
+			free = free[1:]
+		}
+	}
+
+	c.rounds = ordered
+	c.pinned = make([]bool, halfSeasonWeeks)
+	for _, d := range c.derbies {
+		if d.teamsKnown && d.week < c.weeks && c.meet(d.team1, d.team2, d.week) {
+			c.pinned[d.week%halfSeasonWeeks] = true
+		}
+	}
+}
+
+func (c *candidate) roundOf(team1, team2 int) int {
+	for round, pairings := range c.rounds {
+		for _, p := range pairings {
+			if (p.home == team1 && p.away == team2) || (p.home == team2 && p.away == team1) {
+				return round
+			}
+		}
+	}
+	return -1
+}
+
+// improve tries one move at a time, keeping every move that breaks fewer
+// constraints, and returns the remaining cost. A move either swaps the home
+// and away team of one game or swaps two rounds not pinned by a derby. The
+// moves are tried in order, or in a random order when shuffle is set.
+func (c *candidate) improve(rng *rand.Rand, shuffle bool) int {
+	var moves []func()
+	for round, pairings := range c.rounds {
+		for index := range pairings {
+			round, index := round, index
+			p := &c.rounds[round][index]
+			if c.teams[p.home].ID == byeTeamID || c.teams[p.away].ID == byeTeamID {
+				continue
+			}
+			moves = append(moves, func() {
+				p := &c.rounds[round][index]
+				p.home, p.away = p.away, p.home
+			})
+		}
+	}
+	for i := range c.rounds {
+		for j := i + 1; j < len(c.rounds); j++ {
+			i, j := i, j
+			if c.pinned[i] || c.pinned[j] {
+				continue
+			}
+			moves = append(moves, func() { c.rounds[i], c.rounds[j] = c.rounds[j], c.rounds[i] })
+		}
+	}
+
+	cost, _ := c.check(false)
+	for pass := 0; pass < improvePasses && cost > 0; pass++ {
+		if shuffle {
+			rng.Shuffle(len(moves), func(i, j int) { moves[i], moves[j] = moves[j], moves[i] })
+		}
+
+		improved := false
+		for _, move := range moves {
+			// every move undoes itself
+			move()
+			if moved, _ := c.check(false); moved < cost {
+				cost = moved
+				improved = true
+			} else {
+				move()
+			}
+		}
+
+		if !improved {
+			break
+		}
+	}
+
+	return cost
+}
+
+// venues returns for every team and week whether it plays at home (1), away
+// (-1) or not at all (0).
+func (c *candidate) venues() [][]int8 {
+	halfSeasonWeeks := len(c.rounds)
+	venues := make([][]int8, len(c.teams))
+	for i := range venues {
+		venues[i] = make([]int8, c.weeks)
+	}
+
+	for week := 0; week < c.weeks; week++ {
+		var home int8 = 1
+		if (week/halfSeasonWeeks)%2 != 0 {
+			home = -1
+		}
+
+		for _, p := range c.rounds[week%halfSeasonWeeks] {
+			if c.teams[p.home].ID == byeTeamID || c.teams[p.away].ID == byeTeamID {
+				continue
+			}
+			venues[p.home][week], venues[p.away][week] = home, -home
+		}
+	}
+
+	return venues
+}
+
+// check counts the constraints the candidate breaks. A run of home or away
+// games costs one per game over the limit. With report set it also describes
+// every broken constraint.
+func (c *candidate) check(report bool) (int, []types.UnmetConstraint) {
+	cost := 0
+	unmet := []types.UnmetConstraint{}
+	venues := c.venues()
+	weeks := c.weeks
+
+	for i, team := range c.teams {
+		if team.ID == byeTeamID {
+			continue
+		}
+
+		start := 0
+		for week := 1; week <= weeks; week++ {
+			if week < weeks && venues[i][week] == venues[i][start] {
+				continue
+			}
+
+			if run := week - start; venues[i][start] != 0 && run > c.maxConsecutive {
+				cost += run - c.maxConsecutive
+				if report {
+					venue := "home"
+					if venues[i][start] < 0 {
+						venue = "away"
+					}
+					unmet = append(unmet, types.UnmetConstraint{
+						Constraint: types.ConstraintMaxConsecutive,
+						Week:       start + 1,
+						Teams:      []string{team.Name},
+						Message:    fmt.Sprintf("%s plays %d %s games in a row from week %d, the limit is %d", team.Name, run, venue, start+1, c.maxConsecutive),
+					})
+				}
+			}
+			start = week
+		}
+	}
+
+	for _, pair := range c.stadiums {
+		for week := 0; week < weeks; week++ {
+			if venues[pair[0]][week] == 1 && venues[pair[1]][week] == 1 {
+				cost++
+				if report {
+					team1, team2 := c.teams[pair[0]], c.teams[pair[1]]
+					unmet = append(unmet, types.UnmetConstraint{
+						Constraint: types.ConstraintSharedStadium,
+						Week:       week + 1,
+						Teams:      []string{team1.Name, team2.Name},
+						Message:    fmt.Sprintf("%s and %s share %s and both play at home in week %d", team1.Name, team2.Name, team1.Metadata["stadium"], week+1),
+					})
+				}
+			}
+		}
+	}
+
+	for _, d := range c.derbies {
+		if d.teamsKnown && d.week < weeks && c.meet(d.team1, d.team2, d.week) {
+			continue
+		}
+
+		cost++
+		if report {
+			unmet = append(unmet, c.unmetDerby(d, weeks))
+		}
+	}
+
+	return cost, unmet
+}
+
+func (c *candidate) meet(team1, team2, week int) bool {
+	for _, p := range c.rounds[week%len(c.rounds)] {
+		if (p.home == team1 && p.away == team2) || (p.home == team2 && p.away == team1) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *candidate) unmetDerby(d derbyWeek, weeks int) types.UnmetConstraint {
+	unmet := types.UnmetConstraint{Constraint: types.ConstraintDerbyWeek, Week: d.derby.Week, Teams: []string{}}
+
+	switch {
+	case !d.teamsKnown:
+		unmet.Message = fmt.Sprintf("the derby of teams %d and %d in week %d names a team that is not in the league", d.derby.Team1ID, d.derby.Team2ID, d.derby.Week)
+	case d.week >= weeks:
+		unmet.Teams = []string{c.teams[d.team1].Name, c.teams[d.team2].Name}
+		unmet.Message = fmt.Sprintf("%s and %s cannot meet in week %d, the fixture has %d weeks", c.teams[d.team1].Name, c.teams[d.team2].Name, d.derby.Week, weeks)
+	default:
+		unmet.Teams = []string{c.teams[d.team1].Name, c.teams[d.team2].Name}
+		unmet.Message = fmt.Sprintf("%s and %s do not meet in week %d", c.teams[d.team1].Name, c.teams[d.team2].Name, d.derby.Week)
+	}
+
+	return unmet
+}
+
+// matches lists the games of every cycle week by week, leaving out the
+// weeks off of an odd number of teams.
+func (c *candidate) matches() []types.Match {
+	halfSeasonWeeks := len(c.rounds)
+	var matches []types.Match
+
+	for week := 0; week < c.weeks; week++ {
+		for _, p := range c.rounds[week%halfSeasonWeeks] {
+			home, away := c.teams[p.home], c.teams[p.away]
+			if (week/halfSeasonWeeks)%2 != 0 {
+				home, away = away, home
+			}
+
+			if home.ID == byeTeamID || away.ID == byeTeamID {
+				continue
+			}
+
+			matches = append(matches, types.Match{Week: week + 1, Team1ID: home.ID, Team2ID: away.ID, Played: false})
+		}
+	}
+
+	return matches
+}
@@ -0,0 +1,207 @@
+package simulation
+
+import (
+	"football-simulation/types"
+	"math/rand"
+	"testing"
+)
+
+func fixtureTeams(n int) []types.Team {
+	teams := make([]types.Team, n)
+	for i := range teams {
+		teams[i] = types.Team{ID: i + 1, Name: string(rune('A' + i)), Metadata: map[string]string{}}
+	}
+	return teams
+}
+
+func TestPlanFixtureIsADoubleRoundRobin(t *testing.T) {
+	for _, n := range []int{5, 6, 20} {
+		matches, report := planFixture(rand.New(rand.NewSource(1)), fixtureTeams(n), types.FixtureConfig{})
+
+		weeks := 2 * (n - 1)
+		if n%2 != 0 {
+			weeks = 2 * n
+		}
+
+		games := make(map[[2]int]int)
+		played := make(map[[2]int]bool)
+		for _, match := range matches {
+			if match.Week < 1 || match.Week > weeks {
+				t.Fatalf("%d teams: match in week %d, the season has %d weeks", n, match.Week, weeks)
+			}
+			games[[2]int{match.Team1ID, match.Team2ID}]++
+
+			for _, id := range []int{match.Team1ID, match.Team2ID} {
+				if played[[2]int{id, match.Week}] {
+					t.Fatalf("%d teams: team %d plays twice in week %d", n, id, match.Week)
+				}
+				played[[2]int{id, match.Week}] = true
+			}
+		}
+
+		if len(games) != n*(n-1) {
+			t.Errorf("%d teams: %d home and away pairs, want %d", n, len(games), n*(n-1))
+		}
+		for pair, count := range games {
+			if count != 1 {
+				t.Errorf("%d teams: %d hosts %d %d times", n, pair[0], pair[1], count)
+			}
+		}
+		if !report.Satisfied || len(report.Unmet) != 0 {
+			t.Errorf("%d teams: report = %+v, want every constraint met", n, report)
+		}
+	}
+}
+
+func TestPlanFixtureLimitsHomeAndAwayRuns(t *testing.T) {
+	matches, report := planFixture(rand.New(rand.NewSource(1)), fixtureTeams(10), types.FixtureConfig{MaxConsecutive: 2})
+	if !report.Satisfied {
+		t.Fatalf("report = %+v, want every constraint met", report)
+	}
+
+	venues := make(map[int][]bool)
+	for _, match := range matches {
+		venues[match.Team1ID] = append(venues[match.Team1ID], true)
+		venues[match.Team2ID] = append(venues[match.Team2ID], false)
+	}
+
+	for id, home := range venues {
+		run := 1
+		for week := 1; week < len(home); week++ {
+			if home[week] == home[week-1] {
+				run++
+			} else {
+				run = 1
+			}
+			if run > 2 {
+				t.Errorf("team %d plays %d games in a row at the same venue up to week %d", id, run, week+1)
+			}
+		}
+	}
+}
+
+func TestPlanFixtureSeparatesSharedStadiums(t *testing.T) {
+	teams := fixtureTeams(8)
+	teams[2].Metadata["stadium"] = "San Siro"
+	teams[5].Metadata["stadium"] = "san siro "
+
+	matches, report := planFixture(rand.New(rand.NewSource(1)), teams, types.FixtureConfig{})
+	if !report.Satisfied {
+		t.Fatalf("report = %+v, want every constraint met", report)
+	}
+
+	home := make(map[int]int)
+	for _, match := range matches {
+		if match.Team1ID == teams[2].ID || match.Team1ID == teams[5].ID {
+			home[match.Week]++
+		}
+	}
+	for week, count := range home {
+		if count > 1 {
+			t.Errorf("both teams of the shared stadium play at home in week %d", week)
+		}
+	}
+}
+
+func TestPlanFixturePlacesDerbies(t *testing.T) {
+	teams := fixtureTeams(6)
+	derbies := []types.Derby{
+		{Team1ID: 1, Team2ID: 2, Week: 3},
+		{Team1ID: 2, Team2ID: 1, Week: 8},
+		{Team1ID: 4, Team2ID: 6, Week: 1},
+	}
+
+	matches, report := planFixture(rand.New(rand.NewSource(1)), teams, types.FixtureConfig{Derbies: derbies})
+	if !report.Satisfied {
+		t.Fatalf("report = %+v, want every constraint met", report)
+	}
+
+	for _, derby := range derbies {
+		found := false
+		for _, match := range matches {
+			if match.Week == derby.Week &&
+				((match.Team1ID == derby.Team1ID && match.Team2ID == derby.Team2ID) || (match.Team1ID == derby.Team2ID && match.Team2ID == derby.Team1ID)) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("teams %d and %d do not meet in week %d", derby.Team1ID, derby.Team2ID, derby.Week)
+		}
+	}
+}
+
+func TestPlanFixtureReportsUnmetConstraints(t *testing.T) {
+	teams := fixtureTeams(4)
+	derbies := []types.Derby{
+		// the same round cannot be in week 1 and week 2
+		{Team1ID: 1, Team2ID: 2, Week: 1},
+		{Team1ID: 1, Team2ID: 2, Week: 2},
+		{Team1ID: 3, Team2ID: 4, Week: 7},
+		{Team1ID: 3, Team2ID: 99, Week: 1},
+	}
+
+	matches, report := planFixture(rand.New(rand.NewSource(1)), teams, types.FixtureConfig{Derbies: derbies})
+	if len(matches) != 12 {
+		t.Fatalf("%d matches, want the full fixture of 12 regardless", len(matches))
+	}
+	if report.Satisfied || report.Attempts != fixtureAttempts {
+		t.Errorf("report = %+v, want it unsatisfied after every attempt", report)
+	}
+
+	unmet := make(map[int]int)
+	for _, constraint := range report.Unmet {
+		if constraint.Constraint == types.ConstraintDerbyWeek {
+			unmet[constraint.Week]++
+		}
+	}
+	if unmet[1]+unmet[2] != 2 || unmet[7] != 1 {
+		t.Errorf("unmet derbies by week = %v, want one of weeks 1 and 2, the unknown team and week 7", unmet)
+	}
+}
+
+func TestPlanFixtureCycles(t *testing.T) {
+	for cycles := 1; cycles <= 4; cycles++ {
+		matches, _ := planFixture(rand.New(rand.NewSource(1)), fixtureTeams(6), types.FixtureConfig{Cycles: cycles})
+
+		if want := cycles * 15; len(matches) != want {
+			t.Errorf("%d cycles: %d matches, want %d", cycles, len(matches), want)
+		}
+
+		meetings := make(map[[2]int][]int)
+		for _, match := range matches {
+			if match.Week > cycles*5 {
+				t.Fatalf("%d cycles: match in week %d, want at most %d weeks", cycles, match.Week, cycles*5)
+			}
+			pair := [2]int{min(match.Team1ID, match.Team2ID), max(match.Team1ID, match.Team2ID)}
+			meetings[pair] = append(meetings[pair], match.Team1ID)
+		}
+
+		for pair, hosts := range meetings {
+			if len(hosts) != cycles {
+				t.Errorf("%d cycles: %v meet %d times", cycles, pair, len(hosts))
+			}
+			for i := 1; i < len(hosts); i++ {
+				if hosts[i] == hosts[i-1] {
+					t.Errorf("%d cycles: %d hosts %v in two cycles in a row", cycles, hosts[i], pair)
+				}
+			}
+		}
+	}
+}
+
+func TestPlanFixtureStopsAtTheSplit(t *testing.T) {
+	derbies := []types.Derby{{Team1ID: 1, Team2ID: 2, Week: 14}}
+	matches, report := planFixture(rand.New(rand.NewSource(1)), fixtureTeams(6), types.FixtureConfig{Cycles: 3, SplitAfter: 12, Derbies: derbies})
+
+	weeks := 0
+	for _, match := range matches {
+		weeks = max(weeks, match.Week)
+	}
+	if weeks != 12 || len(matches) != 12*3 {
+		t.Errorf("%d matches over %d weeks, want 36 over 12", len(matches), weeks)
+	}
+
+	if len(report.Unmet) != 1 || report.Unmet[0].Constraint != types.ConstraintDerbyWeek {
+		t.Errorf("report = %+v, want the derby after the split unmet", report)
+	}
+}
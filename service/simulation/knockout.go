@@ -0,0 +1,84 @@
+package simulation
+
+import (
+	"football-simulation/types"
+	"math/rand"
+)
+
+const (
+	// homeAdvantage is the strength the home team of a knockout match gains.
+	homeAdvantage = 5
+	// extraTimeShare is the part of a full match extra time lasts.
+	extraTimeShare = 1.0 / 3
+	penaltyRounds  = 5
+	penaltyChance  = 0.75
+)
+
+// PlayKnockout plays a match that needs a winner, team1 at home.
+func (s *Service) PlayKnockout(team1, team2 types.Team) types.KnockoutResult {
+	s.engine.mu.Lock()
+	defer s.engine.mu.Unlock()
+
+	return playKnockout(s.engine.rng, team1, team2)
+}
+
+// playKnockout plays 90 minutes and, on a draw, extra time: every goal of
+// another full match counts with the chance extra time lasts. A draw after
+// extra time goes to penalties.
+func playKnockout(rng *rand.Rand, team1, team2 types.Team) types.KnockoutResult {
+	team1.Strength += homeAdvantage
+
+	var result types.KnockoutResult
+	result.Team1Score, result.Team2Score = playMatch(rng, team1, team2)
+	if result.Team1Score != result.Team2Score {
+		return result
+	}
+
+	result.ExtraTime = true
+	team1Extra, team2Extra := playMatch(rng, team1, team2)
+	result.Team1Score += extraTimeGoals(rng, team1Extra)
+	result.Team2Score += extraTimeGoals(rng, team2Extra)
+	if result.Team1Score != result.Team2Score {
+		return result
+	}
+
+	team1Penalties, team2Penalties := penaltyShootout(rng)
+	result.Team1Penalties, result.Team2Penalties = &team1Penalties, &team2Penalties
+	return result
+}
+
+func extraTimeGoals(rng *rand.Rand, goals int) int {
+	scored := 0
+	for i := 0; i < goals; i++ {
+		if rng.Float64() < extraTimeShare {
+			scored++
+		}
+	}
+	return scored
+}
+
+// penaltyShootout takes five penalties each, stopping as soon as one team
+// cannot catch up, then one each until a round is decided.
+func penaltyShootout(rng *rand.Rand) (int, int) {
+	var scored, remaining [2]int
+	remaining[0], remaining[1] = penaltyRounds, penaltyRounds
+
+	for round := 1; ; round++ {
+		for team := 0; team < 2; team++ {
+			if rng.Float64() < penaltyChance {
+				scored[team]++
+			}
+
+			if round <= penaltyRounds {
+				remaining[team]--
+				if scored[0]+remaining[0] < scored[1] || scored[1]+remaining[1] < scored[0] {
+					return scored[0], scored[1]
+				}
+			}
+		}
+
+		if round >= penaltyRounds && scored[0] != scored[1] {
+			return scored[0], scored[1]
+		}
+	}
+}
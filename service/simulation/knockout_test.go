@@ -0,0 +1,55 @@
+package simulation
+
+import (
+	"football-simulation/types"
+	"math/rand"
+	"testing"
+)
+
+func TestPlayKnockoutAlwaysHasAWinner(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	team1 := types.Team{ID: 1, Strength: 60}
+	team2 := types.Team{ID: 2, Strength: 60}
+
+	extraTime, penalties := 0, 0
+	for i := 0; i < 2000; i++ {
+		result := playKnockout(rng, team1, team2)
+		if result.ExtraTime {
+			extraTime++
+		}
+
+		if result.Team1Score != result.Team2Score {
+			if result.Team1Penalties != nil {
+				t.Fatalf("result %+v has penalties without a draw", result)
+			}
+			continue
+		}
+
+		if !result.ExtraTime || result.Team1Penalties == nil || result.Team2Penalties == nil {
+			t.Fatalf("draw %+v was not decided by extra time and penalties", result)
+		}
+		if *result.Team1Penalties == *result.Team2Penalties {
+			t.Fatalf("penalty shoot-out %d-%d has no winner", *result.Team1Penalties, *result.Team2Penalties)
+		}
+		penalties++
+	}
+
+	if extraTime == 0 || penalties == 0 {
+		t.Errorf("%d matches went to extra time and %d to penalties, want some of both", extraTime, penalties)
+	}
+}
+
+func TestPenaltyShootoutStopsWhenDecided(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 2000; i++ {
+		team1, team2 := penaltyShootout(rng)
+		if team1 == team2 {
+			t.Fatalf("shoot-out %d-%d has no winner", team1, team2)
+		}
+		// a shoot-out decided within five rounds never has a margin over three
+		if diff := team1 - team2; diff > 3 || diff < -3 {
+			t.Fatalf("shoot-out %d-%d went on after it was decided", team1, team2)
+		}
+	}
+}
@@ -1,81 +1,89 @@
 package simulation
 
 import (
+	"context"
 	"fmt"
 	"football-simulation/types"
 	"math/rand"
+	"sync"
+	"time"
 )
 
+// engine is the random source of the match engine. It is shared with the
+// transaction-scoped copies of the service so a reseed applies to both.
+type engine struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
 type Service struct {
-	store types.SimulationStore
+	store  types.SimulationStore
+	engine *engine
 }
 
 func NewService(store types.SimulationStore) *Service {
-	return &Service{store: store}
+	return &Service{
+		store:  store,
+		engine: &engine{rng: rand.New(rand.NewSource(time.Now().UnixNano()))},
+	}
 }
 
-func (s *Service) GenerateFixture(teams []types.Team) error {
-	var matches []types.Match
-	numTeams := len(teams)
-	// for scalability: If the number of teams is odd, add a dummy team.
-	if numTeams%2 != 0 {
-		teams = append(teams, types.Team{ID: -1, Name: "BYE"})
-		numTeams++
-	}
+func (s *Service) WithTx(tx types.DBTX) types.SimulationService {
+	return &Service{store: s.store.WithTx(tx), engine: s.engine}
+}
 
-	halfSeasonWeeks := (numTeams - 1)
-	weekMatches := numTeams / 2
+// Reseed restarts the match engine from seed, so the same sequence of
+// PlayMatch calls produces the same scores.
+func (s *Service) Reseed(seed int64) {
+	s.engine.mu.Lock()
+	defer s.engine.mu.Unlock()
 
-	// first half of the season
-	for week := 0; week < halfSeasonWeeks; week++ {
-		for match := 0; match < weekMatches; match++ {
-			home := (week + match) % (numTeams - 1)
-			away := (numTeams - 1 - match + week) % (numTeams - 1)
+	s.engine.rng = rand.New(rand.NewSource(seed))
+}
 
-			if match == 0 {
-				away = numTeams - 1
-			}
+// GenerateFixture plans a fixture with PlanFixture and saves it.
+func (s *Service) GenerateFixture(teams []types.Team, config types.FixtureConfig) ([]types.Match, *types.FixtureReport, error) {
+	matches, report := s.PlanFixture(teams, config)
 
-			matches = append(matches, types.Match{Week: week + 1, Team1ID: teams[home].ID, Team2ID: teams[away].ID, Played: false})
-		}
+	savedMatches, err := s.SaveFixture(matches)
+	if err != nil {
+		return nil, nil, err
 	}
+	return savedMatches, report, nil
+}
 
-	// second half of the season
-	for week := 0; week < halfSeasonWeeks; week++ {
-		for match := 0; match < weekMatches; match++ {
-			home := (week + match) % (numTeams - 1)
-			away := (numTeams - 1 - match + week) % (numTeams - 1)
+// SaveFixture saves planned matches and returns them with their ids.
+func (s *Service) SaveFixture(matches []types.Match) ([]types.Match, error) {
+	savedMatches, err := s.store.SaveFixture(matches)
 
-			if match == 0 {
-				away = numTeams - 1
-			}
-
-			matches = append(matches, types.Match{Week: week + 1 + halfSeasonWeeks, Team1ID: teams[away].ID, Team2ID: teams[home].ID, Played: false})
-		}
+	if err != nil {
+		return nil, fmt.Errorf("could not save filtered matches: %v", err)
 	}
+	return savedMatches, nil
+}
 
-	// filter out matches involving the dummy team
-	filteredMatches := matches[:0]
-	for _, match := range matches {
-		if match.Team1ID != -1 && match.Team2ID != -1 {
-			filteredMatches = append(filteredMatches, match)
-		}
-	}
+// PlanFixture returns a round robin of teams meeting as many of the
+// constraints of config as it can find, and the report of the ones it does
+// not meet.
+func (s *Service) PlanFixture(teams []types.Team, config types.FixtureConfig) ([]types.Match, *types.FixtureReport) {
+	// fixtures use their own source so they do not advance the seeded engine
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return planFixture(rng, teams, config)
+}
 
-	err := s.store.SaveFixture(filteredMatches)
+func (s *Service) PlayMatch(team1, team2 types.Team) (team1Score, team2Score int) {
+	s.engine.mu.Lock()
+	defer s.engine.mu.Unlock()
 
-	if err != nil {
-		return fmt.Errorf("could not save filtered matches: %v", err)
-	}
-	return nil
+	return playMatch(s.engine.rng, team1, team2)
 }
 
-func (s *Service) PlayMatch(team1, team2 types.Team) (team1Score, team2Score int) {
-	team1BaseScore := float64(team1.Strength) / float64(40+rand.Intn(31))
-	team2BaseScore := float64(team2.Strength) / float64(40+rand.Intn(31))
+func playMatch(rng *rand.Rand, team1, team2 types.Team) (team1Score, team2Score int) {
+	team1BaseScore := float64(team1.Strength) / float64(40+rng.Intn(31))
+	team2BaseScore := float64(team2.Strength) / float64(40+rng.Intn(31))
 
-	team1RandomFactor := rand.Float64() * 2
-	team2RandomFactor := rand.Float64() * 2
+	team1RandomFactor := rng.Float64() * 2
+	team2RandomFactor := rng.Float64() * 2
 
 	team1Score = int(team1BaseScore + team1RandomFactor)
 	team2Score = int(team2BaseScore + team2RandomFactor)
@@ -91,11 +99,22 @@ func (s *Service) PlayMatch(team1, team2 types.Team) (team1Score, team2Score int
 	return team1Score, team2Score
 }
 
-func (s *Service) CalculateChampionshipOdds(teams []types.Team, matches []types.Match) ([]types.Prediction, error) {
-	const simulationCount = 1000
+// CalculateChampionshipOdds plays the unplayed matches simulations times and
+// returns how often each team finished first. progress, when not nil, is
+// called with the number of completed simulations; the run stops with the
+// context error once ctx is done.
+func (s *Service) CalculateChampionshipOdds(ctx context.Context, teams []types.Team, matches []types.Match, simulations int, progress func(done int)) ([]types.Prediction, error) {
 	teamChampionshipCounts := make(map[int]int)
+	// predictions use their own source so they do not advance the seeded engine
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	for i := 0; i < simulations; i++ {
+		if i%100 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 
-	for i := 0; i < simulationCount; i++ {
 		simulatedStandings := make(map[int]int)
 		for _, team := range teams {
 			simulatedStandings[team.ID] = team.Points
@@ -105,7 +124,7 @@ func (s *Service) CalculateChampionshipOdds(teams []types.Team, matches []types.
 			if !match.Played {
 				team1 := simulatedStandings[match.Team1ID]
 				team2 := simulatedStandings[match.Team2ID]
-				team1Score, team2Score := s.PlayMatch(types.Team{Strength: team1}, types.Team{Strength: team2})
+				team1Score, team2Score := playMatch(rng, types.Team{Strength: team1}, types.Team{Strength: team2})
 
 				if team1Score > team2Score {
 					simulatedStandings[match.Team1ID] += 3
@@ -127,12 +146,16 @@ func (s *Service) CalculateChampionshipOdds(teams []types.Team, matches []types.
 			}
 		}
 		teamChampionshipCounts[championTeamID]++
+
+		if progress != nil {
+			progress(i + 1)
+		}
 	}
 
 	var predictions []types.Prediction
 	for _, team := range teams {
 		championshipCount := teamChampionshipCounts[team.ID]
-		odds := float64(championshipCount) / float64(simulationCount) * 100
+		odds := float64(championshipCount) / float64(simulations) * 100
 		predictions = append(predictions, types.Prediction{
 			TeamID:           team.ID,
 			TeamName:         team.Name,
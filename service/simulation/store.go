@@ -1,27 +1,32 @@
 package simulation
 
 import (
-	"database/sql"
 	"football-simulation/types"
 )
 
 type Store struct {
-	db *sql.DB
+	db types.DBTX
 }
 
-func NewStore(db *sql.DB) *Store {
+func NewStore(db types.DBTX) *Store {
 	return &Store{db: db}
 }
 
-func (s *Store) SaveFixture(matches []types.Match) error {
+func (s *Store) WithTx(tx types.DBTX) types.SimulationStore {
+	return &Store{db: tx}
+}
+
+func (s *Store) SaveFixture(matches []types.Match) ([]types.Match, error) {
+	savedMatches := make([]types.Match, 0, len(matches))
 
 	for _, match := range matches {
-		_, err := s.db.Exec(`INSERT INTO matches (week, team1_id, team2_id, team1_score, team2_score, played) VALUES ($1, $2, $3, $4, $5, $6)`,
-			match.Week, match.Team1ID, match.Team2ID, match.Team1Score, match.Team2Score, match.Played)
+		err := s.db.QueryRow(`INSERT INTO matches (week, team1_id, team2_id, team1_score, team2_score, played, kickoff_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, version`,
+			match.Week, match.Team1ID, match.Team2ID, match.Team1Score, match.Team2Score, match.Played, match.KickoffAt).Scan(&match.ID, &match.Version)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		savedMatches = append(savedMatches, match)
 	}
 
-	return nil
+	return savedMatches, nil
 }
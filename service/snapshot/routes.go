@@ -0,0 +1,61 @@
+package snapshot
+
+import (
+	"football-simulation/types"
+	"football-simulation/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	service types.SnapshotService
+}
+
+func NewHandler(service types.SnapshotService) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/league/snapshot", h.handleSaveSnapshot).Methods("GET")
+	router.HandleFunc("/league/snapshot", h.handleLoadSnapshot).Methods("POST")
+}
+
+func (h *Handler) handleSaveSnapshot(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := h.service.SaveSnapshot()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="snapshot.json"`)
+	utils.WriteJSON(w, http.StatusOK, snapshot)
+}
+
+func (h *Handler) handleLoadSnapshot(w http.ResponseWriter, r *http.Request) {
+	var options types.SnapshotLoadOptions
+	if value := r.URL.Query().Get("replace"); value != "" {
+		replace, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, err)
+			return
+		}
+		options.Replace = replace
+	}
+
+	var snapshot types.Snapshot
+	if err := utils.ParseJSON(r, &snapshot); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	loaded, err := h.service.LoadSnapshot(snapshot, options)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.SetETag(w, loaded.League.Version)
+	utils.WriteSuccess(w, http.StatusOK, loaded)
+}
@@ -0,0 +1,358 @@
+package snapshot
+
+import (
+	"fmt"
+	"football-simulation/service/league"
+	"football-simulation/service/playoff"
+	"football-simulation/types"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrInvalidSnapshot is returned when a snapshot cannot be loaded as is.
+	ErrInvalidSnapshot = types.NewError(types.ErrorKindValidation, "invalid_snapshot", "invalid snapshot")
+	// ErrLeagueNotEmpty is returned when loading into a league that already
+	// has teams or matches without asking to replace them.
+	ErrLeagueNotEmpty = types.NewError(types.ErrorKindConflict, "league_not_empty", "the league already has teams or matches, load with replace to overwrite them")
+)
+
+type Service struct {
+	leagueStore     types.LeagueStore
+	teamStore       types.Teamstore
+	simulationStore types.SimulationStore
+	playoffStore    types.PlayoffStore
+	eventStore      types.LeagueEventStore
+	transactor      types.Transactor
+}
+
+func NewService(leagueStore types.LeagueStore, teamStore types.Teamstore, simulationStore types.SimulationStore, playoffStore types.PlayoffStore, eventStore types.LeagueEventStore, transactor types.Transactor) *Service {
+	return &Service{
+		leagueStore:     leagueStore,
+		teamStore:       teamStore,
+		simulationStore: simulationStore,
+		playoffStore:    playoffStore,
+		eventStore:      eventStore,
+		transactor:      transactor,
+	}
+}
+
+// SaveSnapshot captures the league, the teams, the fixture and the playoffs
+// of the season as they are committed in the store.
+func (s *Service) SaveSnapshot() (*types.Snapshot, error) {
+	var snapshot *types.Snapshot
+
+	err := s.transactor.WithinTransaction(func(tx types.DBTX) error {
+		var err error
+		snapshot, err = s.withTx(tx).saveSnapshot()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+func (s *Service) saveSnapshot() (*types.Snapshot, error) {
+	leagueInfo, err := s.leagueStore.GetLeagueInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := s.teamStore.GetTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(teams, func(i, j int) bool { return teams[i].ID < teams[j].ID })
+
+	matches, err := s.leagueStore.GetAllMatches()
+	if err != nil {
+		return nil, err
+	}
+
+	if matches == nil {
+		matches = make([]types.Match, 0)
+	}
+
+	playoffConfig, err := s.playoffStore.GetPlayoffConfig(leagueInfo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if playoffConfig.Pairings == nil {
+		playoffConfig.Pairings = [][2]int{}
+	}
+
+	playoffMatches, err := s.playoffStore.GetPlayoffMatches(leagueInfo.Season)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Snapshot{
+		FormatVersion:  types.SnapshotFormatVersion,
+		CreatedAt:      time.Now().UTC(),
+		League:         leagueInfo,
+		Teams:          teams,
+		Matches:        matches,
+		PlayoffConfig:  playoffConfig.PlayoffConfig,
+		PlayoffMatches: playoffMatches,
+	}, nil
+}
+
+// LoadSnapshot writes snapshot into the current league in a single
+// transaction. Teams, matches and playoff matches get new ids; the returned
+// snapshot carries them. The playoff matches of the season of the snapshot
+// and of the seasons after it are replaced, as the loaded league plays them
+// again. The load is recorded in the event log so replays start from it.
+func (s *Service) LoadSnapshot(snapshot types.Snapshot, options types.SnapshotLoadOptions) (*types.Snapshot, error) {
+	if err := validateSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+
+	var loaded *types.Snapshot
+
+	err := s.transactor.WithinTransaction(func(tx types.DBTX) error {
+		txService := s.withTx(tx)
+
+		leagueInfo, err := txService.leagueStore.GetLeagueInfo()
+		if err != nil {
+			return err
+		}
+
+		locked, err := txService.leagueStore.TryLockLeague(leagueInfo.ID)
+		if err != nil {
+			return err
+		}
+
+		if !locked {
+			return league.ErrLeagueBusy
+		}
+
+		loaded, err = txService.loadSnapshot(leagueInfo, snapshot, options)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loaded, nil
+}
+
+func (s *Service) loadSnapshot(leagueInfo types.League, snapshot types.Snapshot, options types.SnapshotLoadOptions) (*types.Snapshot, error) {
+	teams, err := s.teamStore.GetTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := s.leagueStore.GetAllMatches()
+	if err != nil {
+		return nil, err
+	}
+
+	if (len(teams) > 0 || len(matches) > 0) && !options.Replace {
+		return nil, ErrLeagueNotEmpty
+	}
+
+	err = s.leagueStore.ClearFixtures()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, team := range teams {
+		err = s.teamStore.DeleteTeam(team.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	teamIDs := make(map[int]int)
+	loadedTeams := make([]types.Team, 0, len(snapshot.Teams))
+
+	for _, team := range snapshot.Teams {
+		created, err := s.teamStore.CreateTeam(team)
+		if err != nil {
+			return nil, err
+		}
+
+		teamIDs[team.ID] = created.ID
+		team.ID = created.ID
+
+		//CreateTeam only stores the team itself, the statistics are written separately
+		err = s.teamStore.UpdateTeam(team)
+		if err != nil {
+			return nil, err
+		}
+		loadedTeams = append(loadedTeams, team)
+	}
+
+	fixture := make([]types.Match, 0, len(snapshot.Matches))
+	for _, match := range snapshot.Matches {
+		match.ID = 0
+		match.Team1ID = teamIDs[match.Team1ID]
+		match.Team2ID = teamIDs[match.Team2ID]
+		fixture = append(fixture, match)
+	}
+
+	loadedMatches, err := s.simulationStore.SaveFixture(fixture)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.playoffStore.DeletePlayoffMatches(snapshot.League.Season)
+	if err != nil {
+		return nil, err
+	}
+
+	bracket := make([]types.PlayoffMatch, 0, len(snapshot.PlayoffMatches))
+	for _, match := range snapshot.PlayoffMatches {
+		match.ID = 0
+		match.Team1ID = teamIDs[match.Team1ID]
+		match.Team2ID = teamIDs[match.Team2ID]
+		bracket = append(bracket, match)
+	}
+
+	loadedBracket, err := s.playoffStore.SavePlayoffMatches(bracket)
+	if err != nil {
+		return nil, err
+	}
+
+	playoffConfig := snapshot.PlayoffConfig
+	if playoffConfig.Pairings == nil {
+		playoffConfig.Pairings = [][2]int{}
+	}
+
+	err = s.playoffStore.SavePlayoffConfig(types.LeaguePlayoffConfig{LeagueID: leagueInfo.ID, PlayoffConfig: playoffConfig})
+	if err != nil {
+		return nil, err
+	}
+
+	loadedLeague := snapshot.League
+	loadedLeague.ID = leagueInfo.ID
+
+	err = s.leagueStore.UpdateLeague(loadedLeague)
+	if err != nil {
+		return nil, err
+	}
+
+	loadedLeague.Version = leagueInfo.Version + 1
+
+	loaded := &types.Snapshot{
+		FormatVersion:  snapshot.FormatVersion,
+		CreatedAt:      snapshot.CreatedAt,
+		League:         loadedLeague,
+		Teams:          loadedTeams,
+		Matches:        loadedMatches,
+		PlayoffConfig:  playoffConfig,
+		PlayoffMatches: loadedBracket,
+	}
+
+	err = s.eventStore.AppendEvent(types.EventSnapshotLoaded, loaded)
+	if err != nil {
+		return nil, err
+	}
+
+	return loaded, nil
+}
+
+func (s *Service) withTx(tx types.DBTX) *Service {
+	return &Service{
+		leagueStore:     s.leagueStore.WithTx(tx),
+		teamStore:       s.teamStore.WithTx(tx),
+		simulationStore: s.simulationStore.WithTx(tx),
+		playoffStore:    s.playoffStore.WithTx(tx),
+		eventStore:      s.eventStore.WithTx(tx),
+		transactor:      s.transactor,
+	}
+}
+
+// validateSnapshot checks that the snapshot is self-consistent before
+// anything is written.
+func validateSnapshot(snapshot types.Snapshot) error {
+	if snapshot.FormatVersion != types.SnapshotFormatVersion {
+		return fmt.Errorf("%w: unsupported format version %d, expected %d", ErrInvalidSnapshot, snapshot.FormatVersion, types.SnapshotFormatVersion)
+	}
+
+	if snapshot.League.Name == "" {
+		return fmt.Errorf("%w: league name is required", ErrInvalidSnapshot)
+	}
+
+	teamIDs := make(map[int]bool)
+	teamNames := make(map[string]bool)
+	for _, team := range snapshot.Teams {
+		if team.Name == "" {
+			return fmt.Errorf("%w: team %d has no name", ErrInvalidSnapshot, team.ID)
+		}
+
+		if teamIDs[team.ID] {
+			return fmt.Errorf("%w: duplicate team id %d", ErrInvalidSnapshot, team.ID)
+		}
+
+		if teamNames[team.Name] {
+			return fmt.Errorf("%w: duplicate team name %q", ErrInvalidSnapshot, team.Name)
+		}
+
+		teamIDs[team.ID] = true
+		teamNames[team.Name] = true
+	}
+
+	for _, match := range snapshot.Matches {
+		if !teamIDs[match.Team1ID] || !teamIDs[match.Team2ID] {
+			return fmt.Errorf("%w: match %d references an unknown team", ErrInvalidSnapshot, match.ID)
+		}
+
+		if match.Team1ID == match.Team2ID {
+			return fmt.Errorf("%w: match %d has the same team on both sides", ErrInvalidSnapshot, match.ID)
+		}
+
+		if match.Week < 1 {
+			return fmt.Errorf("%w: match %d has week %d", ErrInvalidSnapshot, match.ID, match.Week)
+		}
+
+		if match.Team1Score < 0 || match.Team2Score < 0 {
+			return fmt.Errorf("%w: match %d has a negative score", ErrInvalidSnapshot, match.ID)
+		}
+	}
+
+	if err := playoff.ValidatePairings(snapshot.PlayoffConfig); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+
+	for _, pairing := range snapshot.PlayoffConfig.Pairings {
+		if pairing[0] < 1 || pairing[1] < 1 {
+			return fmt.Errorf("%w: playoff pairing %v has a position below 1", ErrInvalidSnapshot, pairing)
+		}
+	}
+
+	slots := make(map[[2]int]bool)
+	for _, match := range snapshot.PlayoffMatches {
+		if !teamIDs[match.Team1ID] || !teamIDs[match.Team2ID] {
+			return fmt.Errorf("%w: playoff match %d references an unknown team", ErrInvalidSnapshot, match.ID)
+		}
+
+		if match.Team1ID == match.Team2ID {
+			return fmt.Errorf("%w: playoff match %d has the same team on both sides", ErrInvalidSnapshot, match.ID)
+		}
+
+		if match.Season != snapshot.League.Season {
+			return fmt.Errorf("%w: playoff match %d is of season %d, the league is in season %d", ErrInvalidSnapshot, match.ID, match.Season, snapshot.League.Season)
+		}
+
+		if match.Round < 1 || match.Slot < 1 {
+			return fmt.Errorf("%w: playoff match %d has round %d, slot %d", ErrInvalidSnapshot, match.ID, match.Round, match.Slot)
+		}
+
+		if slots[[2]int{match.Round, match.Slot}] {
+			return fmt.Errorf("%w: playoff round %d has slot %d twice", ErrInvalidSnapshot, match.Round, match.Slot)
+		}
+
+		if match.Team1Score < 0 || match.Team2Score < 0 {
+			return fmt.Errorf("%w: playoff match %d has a negative score", ErrInvalidSnapshot, match.ID)
+		}
+
+		slots[[2]int{match.Round, match.Slot}] = true
+	}
+
+	return nil
+}
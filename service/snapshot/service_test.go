@@ -0,0 +1,310 @@
+package snapshot_test
+
+import (
+	"errors"
+	"football-simulation/database/memory"
+	"football-simulation/service/calendar"
+	"football-simulation/service/fixture"
+	"football-simulation/service/league"
+	"football-simulation/service/simulation"
+	"football-simulation/service/snapshot"
+	"football-simulation/service/team"
+	"football-simulation/service/webhook"
+	"football-simulation/types"
+	"reflect"
+	"testing"
+)
+
+// leagueServices is a league and its snapshot service on the memory backend.
+type leagueServices struct {
+	league   *league.Service
+	snapshot *snapshot.Service
+	teams    *memory.TeamStore
+	playoffs *memory.PlayoffStore
+}
+
+func newLeague() leagueServices {
+	db := memory.NewDB()
+	leagueStore, teamStore, eventStore := memory.NewLeagueStore(db), memory.NewTeamStore(db), memory.NewEventStore(db)
+	simulationStore, playoffStore, transactor := memory.NewSimulationStore(db), memory.NewPlayoffStore(db), memory.NewTransactor(db)
+
+	simulationService := simulation.NewService(simulationStore)
+	teamService := team.NewService(teamStore, leagueStore, eventStore, transactor)
+	calendarService := calendar.NewService(memory.NewCalendarStore(db), leagueStore, teamStore, transactor)
+	fixtureService := fixture.NewService(memory.NewFixtureStore(db), leagueStore, teamStore, simulationService)
+	leagueService := league.NewService(leagueStore, simulationService, teamService, eventStore, webhook.NewService(memory.NewWebhookStore(db)), calendarService, fixtureService, transactor)
+
+	return leagueServices{
+		league:   leagueService,
+		snapshot: snapshot.NewService(leagueStore, teamStore, simulationStore, playoffStore, eventStore, transactor),
+		teams:    teamStore,
+		playoffs: playoffStore,
+	}
+}
+
+func (l leagueServices) createTeams(t *testing.T, names ...string) {
+	t.Helper()
+
+	for i, name := range names {
+		if _, err := l.teams.CreateTeam(types.Team{Name: name, Strength: 60 + 7*i}); err != nil {
+			t.Fatalf("CreateTeam: %v", err)
+		}
+	}
+}
+
+// saveSemiFinal saves a played semi-final between the teams named home and
+// away in season.
+func (l leagueServices) saveSemiFinal(t *testing.T, season, slot int, home, away string) {
+	t.Helper()
+
+	ids := make(map[string]int)
+	for id, name := range l.teamNames(t) {
+		ids[name] = id
+	}
+
+	_, err := l.playoffs.SavePlayoffMatches([]types.PlayoffMatch{{
+		Season: season, Round: 1, Slot: slot,
+		Team1ID: ids[home], Team1Name: home, Team1Seed: slot,
+		Team2ID: ids[away], Team2Name: away, Team2Seed: 5 - slot,
+		KnockoutResult: types.KnockoutResult{Team1Score: 2, Team2Score: 1},
+	}})
+	if err != nil {
+		t.Fatalf("SavePlayoffMatches: %v", err)
+	}
+}
+
+// playWeek plays the next week and returns its results keyed by the
+// names of the teams, so leagues with different team ids can be compared.
+func (l leagueServices) playWeek(t *testing.T) map[string][2]int {
+	t.Helper()
+
+	played, _, err := l.league.NextWeek(0)
+	if err != nil {
+		t.Fatalf("NextWeek: %v", err)
+	}
+
+	names := l.teamNames(t)
+	results := make(map[string][2]int)
+	for _, match := range played {
+		results[names[match.Team1ID]+" - "+names[match.Team2ID]] = [2]int{match.Team1Score, match.Team2Score}
+	}
+	return results
+}
+
+func (l leagueServices) teamNames(t *testing.T) map[int]string {
+	t.Helper()
+
+	teams, err := l.teams.GetTeams()
+	if err != nil {
+		t.Fatalf("GetTeams: %v", err)
+	}
+
+	names := make(map[int]string)
+	for _, team := range teams {
+		names[team.ID] = team.Name
+	}
+	return names
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	source := newLeague()
+	source.createTeams(t, "Chelsea", "Arsenal", "Manchester City", "Liverpool")
+	source.playWeek(t)
+
+	saved, err := source.snapshot.SaveSnapshot()
+	if err != nil {
+		t.Fatalf("SaveSnapshot: %v", err)
+	}
+	if saved.League.Seed == 0 {
+		t.Fatal("the saved league has no seed")
+	}
+	wantWeek := source.playWeek(t)
+
+	// the target already holds other teams, so every id is remapped
+	target := newLeague()
+	target.createTeams(t, "Everton", "Fulham", "Brentford", "Wolves", "Burnley")
+	if _, err := target.snapshot.LoadSnapshot(*saved, types.SnapshotLoadOptions{}); !errors.Is(err, snapshot.ErrLeagueNotEmpty) {
+		t.Fatalf("LoadSnapshot without replace = %v, want %v", err, snapshot.ErrLeagueNotEmpty)
+	}
+
+	loaded, err := target.snapshot.LoadSnapshot(*saved, types.SnapshotLoadOptions{Replace: true})
+	if err != nil {
+		t.Fatalf("LoadSnapshot: %v", err)
+	}
+
+	savedNames := make(map[int]string)
+	for _, team := range saved.Teams {
+		savedNames[team.ID] = team.Name
+	}
+	loadedNames := target.teamNames(t)
+	if len(loadedNames) != len(saved.Teams) {
+		t.Fatalf("loaded %d teams, want %d", len(loadedNames), len(saved.Teams))
+	}
+
+	teams, err := target.teams.GetTeams()
+	if err != nil {
+		t.Fatalf("GetTeams: %v", err)
+	}
+	byName := make(map[string]types.Team)
+	for _, team := range teams {
+		byName[team.Name] = team
+	}
+	for _, team := range saved.Teams {
+		stored := byName[team.Name]
+		if stored.ID == team.ID {
+			t.Errorf("team %q kept id %d", team.Name, team.ID)
+		}
+		team.ID = stored.ID
+		if !reflect.DeepEqual(stored, team) {
+			t.Errorf("loaded team = %+v, want %+v", stored, team)
+		}
+	}
+
+	if len(loaded.Matches) != len(saved.Matches) {
+		t.Fatalf("loaded %d matches, want %d", len(loaded.Matches), len(saved.Matches))
+	}
+	for i, match := range loaded.Matches {
+		want := saved.Matches[i]
+		if loadedNames[match.Team1ID] != savedNames[want.Team1ID] || loadedNames[match.Team2ID] != savedNames[want.Team2ID] {
+			t.Errorf("match %d is %s - %s, want %s - %s", i, loadedNames[match.Team1ID], loadedNames[match.Team2ID], savedNames[want.Team1ID], savedNames[want.Team2ID])
+		}
+		if match.Week != want.Week || match.Played != want.Played || match.Team1Score != want.Team1Score || match.Team2Score != want.Team2Score {
+			t.Errorf("match %d = %+v, want %+v", i, match, want)
+		}
+	}
+
+	leagueInfo, err := target.league.GetLeague()
+	if err != nil {
+		t.Fatalf("GetLeague: %v", err)
+	}
+	if leagueInfo.Seed != saved.League.Seed || leagueInfo.CurrentWeek != saved.League.CurrentWeek {
+		t.Errorf("loaded league = %+v, want the seed and week of %+v", leagueInfo, saved.League)
+	}
+
+	// the seed makes the loaded league play the week exactly as the original did
+	gotWeek := target.playWeek(t)
+	if len(gotWeek) == 0 || len(gotWeek) != len(wantWeek) {
+		t.Fatalf("week results = %v, want %v", gotWeek, wantWeek)
+	}
+	for fixture, score := range wantWeek {
+		if gotWeek[fixture] != score {
+			t.Errorf("%s ended %v, want %v", fixture, gotWeek[fixture], score)
+		}
+	}
+}
+
+func TestSnapshotKeepsPlayoffs(t *testing.T) {
+	source := newLeague()
+	source.createTeams(t, "Chelsea", "Arsenal", "Manchester City", "Liverpool")
+	leagueInfo, err := source.league.GetLeague()
+	if err != nil {
+		t.Fatalf("GetLeague: %v", err)
+	}
+
+	config := types.PlayoffConfig{Pairings: [][2]int{{1, 4}, {2, 3}}}
+	if err := source.playoffs.SavePlayoffConfig(types.LeaguePlayoffConfig{LeagueID: leagueInfo.ID, PlayoffConfig: config}); err != nil {
+		t.Fatalf("SavePlayoffConfig: %v", err)
+	}
+	// the playoffs are under way: one semi-final is played
+	source.saveSemiFinal(t, leagueInfo.Season, 1, "Chelsea", "Liverpool")
+
+	saved, err := source.snapshot.SaveSnapshot()
+	if err != nil {
+		t.Fatalf("SaveSnapshot: %v", err)
+	}
+	if !reflect.DeepEqual(saved.PlayoffConfig, config) || len(saved.PlayoffMatches) != 1 {
+		t.Fatalf("saved playoffs = %+v, %+v, want the pairings and the semi-final", saved.PlayoffConfig, saved.PlayoffMatches)
+	}
+
+	// the target has a bracket of its own in the same season and the next
+	target := newLeague()
+	target.createTeams(t, "Everton", "Fulham", "Brentford", "Wolves")
+	target.saveSemiFinal(t, leagueInfo.Season, 1, "Everton", "Wolves")
+	target.saveSemiFinal(t, leagueInfo.Season, 2, "Fulham", "Brentford")
+	target.saveSemiFinal(t, leagueInfo.Season+1, 1, "Fulham", "Wolves")
+
+	loaded, err := target.snapshot.LoadSnapshot(*saved, types.SnapshotLoadOptions{Replace: true})
+	if err != nil {
+		t.Fatalf("LoadSnapshot: %v", err)
+	}
+
+	targetInfo, err := target.league.GetLeague()
+	if err != nil {
+		t.Fatalf("GetLeague: %v", err)
+	}
+	loadedConfig, err := target.playoffs.GetPlayoffConfig(targetInfo.ID)
+	if err != nil {
+		t.Fatalf("GetPlayoffConfig: %v", err)
+	}
+	if !reflect.DeepEqual(loadedConfig.PlayoffConfig, config) {
+		t.Errorf("loaded pairings = %v, want %v", loadedConfig.Pairings, config.Pairings)
+	}
+
+	bracket, err := target.playoffs.GetPlayoffMatches(leagueInfo.Season)
+	if err != nil {
+		t.Fatalf("GetPlayoffMatches: %v", err)
+	}
+	if !reflect.DeepEqual(bracket, loaded.PlayoffMatches) {
+		t.Errorf("stored bracket = %+v, want the loaded one %+v", bracket, loaded.PlayoffMatches)
+	}
+
+	names := target.teamNames(t)
+	if len(bracket) != 1 || names[bracket[0].Team1ID] != "Chelsea" || names[bracket[0].Team2ID] != "Liverpool" || bracket[0].Team1Score != 2 {
+		t.Fatalf("loaded bracket = %+v, want only Chelsea - Liverpool 2-1 with the new team ids", bracket)
+	}
+
+	if later, err := target.playoffs.GetPlayoffMatches(leagueInfo.Season + 1); err != nil || len(later) != 0 {
+		t.Errorf("bracket of the next season = %+v, %v, want it deleted", later, err)
+	}
+}
+
+func TestLoadSnapshotRejectsInvalid(t *testing.T) {
+	valid := func() types.Snapshot {
+		return types.Snapshot{
+			FormatVersion:  types.SnapshotFormatVersion,
+			League:         types.League{Name: "Premier League"},
+			Teams:          []types.Team{{ID: 1, Name: "Chelsea"}, {ID: 2, Name: "Arsenal"}},
+			Matches:        []types.Match{{ID: 1, Week: 1, Team1ID: 1, Team2ID: 2}},
+			PlayoffConfig:  types.PlayoffConfig{Pairings: [][2]int{{1, 2}}},
+			PlayoffMatches: []types.PlayoffMatch{{ID: 1, Round: 1, Slot: 1, Team1ID: 1, Team1Name: "Chelsea", Team1Seed: 1, Team2ID: 2, Team2Name: "Arsenal", Team2Seed: 2}},
+		}
+	}
+
+	tests := map[string]func(s *types.Snapshot){
+		"format version":    func(s *types.Snapshot) { s.FormatVersion++ },
+		"league name":       func(s *types.Snapshot) { s.League.Name = "" },
+		"team name":         func(s *types.Snapshot) { s.Teams[1].Name = "" },
+		"duplicate team id": func(s *types.Snapshot) { s.Teams[1].ID = 1 },
+		"duplicate name":    func(s *types.Snapshot) { s.Teams[1].Name = "Chelsea" },
+		"unknown team":      func(s *types.Snapshot) { s.Matches[0].Team2ID = 3 },
+		"same team twice":   func(s *types.Snapshot) { s.Matches[0].Team2ID = 1 },
+		"week zero":         func(s *types.Snapshot) { s.Matches[0].Week = 0 },
+		"negative score":    func(s *types.Snapshot) { s.Matches[0].Team1Score = -1 },
+		"paired twice":      func(s *types.Snapshot) { s.PlayoffConfig.Pairings[0][1] = 1 },
+		"position zero":     func(s *types.Snapshot) { s.PlayoffConfig.Pairings[0][1] = 0 },
+		"playoff team":      func(s *types.Snapshot) { s.PlayoffMatches[0].Team2ID = 3 },
+		"playoff season":    func(s *types.Snapshot) { s.PlayoffMatches[0].Season = 2 },
+		"playoff slot":      func(s *types.Snapshot) { s.PlayoffMatches[0].Slot = 0 },
+		"playoff score":     func(s *types.Snapshot) { s.PlayoffMatches[0].Team2Score = -1 },
+	}
+
+	l := newLeague()
+	if _, err := l.snapshot.LoadSnapshot(valid(), types.SnapshotLoadOptions{}); err != nil {
+		t.Fatalf("LoadSnapshot of a valid snapshot: %v", err)
+	}
+
+	for name, corrupt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := valid()
+			corrupt(&s)
+			if _, err := l.snapshot.LoadSnapshot(s, types.SnapshotLoadOptions{Replace: true}); !errors.Is(err, snapshot.ErrInvalidSnapshot) {
+				t.Fatalf("LoadSnapshot = %v, want %v", err, snapshot.ErrInvalidSnapshot)
+			}
+		})
+	}
+
+	// nothing of the rejected snapshots was written
+	if names := l.teamNames(t); len(names) != 2 {
+		t.Errorf("league has teams %v after the rejected loads, want Chelsea and Arsenal", names)
+	}
+}
@@ -0,0 +1,233 @@
+package team
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"football-simulation/types"
+	"football-simulation/utils"
+	"io"
+	"maps"
+	"strconv"
+	"strings"
+)
+
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatJSON = "json"
+)
+
+// ErrInvalidImport is returned when the import document cannot be read at all.
+var ErrInvalidImport = types.NewError(types.ErrorKindValidation, "invalid_import", "invalid import document")
+
+// errRollbackImport aborts the import transaction for dry runs and for imports
+// with invalid rows; it never reaches the caller.
+var errRollbackImport = errors.New("rollback import")
+
+type importRow struct {
+	row  int
+	team types.CreateTeamRequest
+}
+
+// ImportTeams loads teams from a CSV or JSON document. Every row is validated
+// first and nothing is written unless all of them are valid. Existing teams
+// are rejected, or updated in place when options.Upsert is set, which makes
+// re-running the same import a no-op. A dry run reports what would happen
+// without writing anything.
+func (s *Service) ImportTeams(format string, r io.Reader, options types.TeamImportOptions) (*types.TeamImportReport, error) {
+	report := &types.TeamImportReport{DryRun: options.DryRun, Teams: []types.TeamImportResult{}}
+
+	rows, err := parseTeams(format, r, report)
+	if err != nil {
+		return nil, err
+	}
+
+	rows = validateTeams(rows, report)
+
+	err = s.transactor.WithinTransaction(func(tx types.DBTX) error {
+		txService := s.withTxService(tx)
+
+		for _, row := range rows {
+			if err := txService.importTeam(row, options, report); err != nil {
+				return err
+			}
+		}
+
+		if options.DryRun || len(report.Errors) > 0 {
+			return errRollbackImport
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollbackImport) {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (s *Service) importTeam(row importRow, options types.TeamImportOptions, report *types.TeamImportReport) error {
+	existing, err := s.store.GetTeamByName(row.team.Name)
+	if err != nil {
+		return err
+	}
+
+	result := types.TeamImportResult{Row: row.row, Name: row.team.Name}
+
+	switch {
+	case existing.ID == 0:
+		_, err = s.createTeam(row.team)
+		result.Action = types.ImportActionCreate
+	case !options.Upsert:
+		err = ErrTeamNameTaken
+	case existing.Strength == row.team.Strength && (row.team.Metadata == nil || maps.Equal(existing.Metadata, row.team.Metadata)):
+		result.Action = types.ImportActionSkip
+	default:
+		_, err = s.patchTeam(existing.ID, types.PatchTeamRequest{Strength: &row.team.Strength, Metadata: row.team.Metadata})
+		result.Action = types.ImportActionUpdate
+	}
+
+	if errors.Is(err, ErrTeamNameTaken) || errors.Is(err, ErrLeagueInProgress) {
+		report.Errors = append(report.Errors, types.ImportError{Row: row.row, Field: "name", Message: err.Error()})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	report.Teams = append(report.Teams, result)
+	switch result.Action {
+	case types.ImportActionCreate:
+		report.Created++
+	case types.ImportActionUpdate:
+		report.Updated++
+	case types.ImportActionSkip:
+		report.Skipped++
+	}
+
+	return nil
+}
+
+// validateTeams runs the request validation on every row and removes the
+// invalid ones, recording why they were rejected.
+func validateTeams(rows []importRow, report *types.TeamImportReport) []importRow {
+	seen := make(map[string]int)
+	valid := rows[:0]
+
+	for _, row := range rows {
+		row.team.Name = strings.TrimSpace(row.team.Name)
+		rowValid := true
+
+		if err := utils.Validate.Struct(row.team); err != nil {
+			for _, fieldErr := range utils.FieldErrors(err) {
+				report.Errors = append(report.Errors, types.ImportError{
+					Row:     row.row,
+					Field:   fieldErr.Field,
+					Message: fieldErr.Message,
+				})
+			}
+			rowValid = false
+		}
+
+		if firstRow, ok := seen[strings.ToLower(row.team.Name)]; ok && row.team.Name != "" {
+			report.Errors = append(report.Errors, types.ImportError{
+				Row:     row.row,
+				Field:   "name",
+				Message: fmt.Sprintf("duplicate of row %d", firstRow),
+			})
+			rowValid = false
+		} else {
+			seen[strings.ToLower(row.team.Name)] = row.row
+		}
+
+		if rowValid {
+			valid = append(valid, row)
+		}
+	}
+
+	return valid
+}
+
+func parseTeams(format string, r io.Reader, report *types.TeamImportReport) ([]importRow, error) {
+	switch strings.ToLower(format) {
+	case ImportFormatCSV:
+		return parseTeamsCSV(r, report)
+	case ImportFormatJSON:
+		return parseTeamsJSON(r, report)
+	}
+	return nil, fmt.Errorf("%w: unsupported format %q, expected %q or %q", ErrInvalidImport, format, ImportFormatCSV, ImportFormatJSON)
+}
+
+// parseTeamsCSV reads a CSV document with a header row. The name and strength
+// columns are required; any other column is stored as team metadata.
+func parseTeamsCSV(r io.Reader, report *types.TeamImportReport) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not read the CSV header: %v", ErrInvalidImport, err)
+	}
+
+	columns := make(map[string]int)
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	for _, required := range []string{"name", "strength"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: the CSV header has no %q column", ErrInvalidImport, required)
+		}
+	}
+
+	rows := make([]importRow, 0)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, types.ImportError{Row: line, Message: err.Error()})
+			continue
+		}
+
+		row := importRow{row: line}
+		row.team.Name = record[columns["name"]]
+
+		strength := strings.TrimSpace(record[columns["strength"]])
+		row.team.Strength, err = strconv.Atoi(strength)
+		if err != nil {
+			report.Errors = append(report.Errors, types.ImportError{Row: line, Field: "strength", Message: fmt.Sprintf("%q is not a number", strength)})
+			continue
+		}
+
+		for column, i := range columns {
+			if column == "name" || column == "strength" || strings.TrimSpace(record[i]) == "" {
+				continue
+			}
+			if row.team.Metadata == nil {
+				row.team.Metadata = make(map[string]string)
+			}
+			row.team.Metadata[column] = strings.TrimSpace(record[i])
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseTeamsJSON reads a JSON array of teams shaped like CreateTeamRequest.
+func parseTeamsJSON(r io.Reader, report *types.TeamImportReport) ([]importRow, error) {
+	var teams []types.CreateTeamRequest
+	if err := json.NewDecoder(r).Decode(&teams); err != nil {
+		return nil, fmt.Errorf("%w: could not decode the JSON document: %v", ErrInvalidImport, err)
+	}
+
+	rows := make([]importRow, 0, len(teams))
+	for i, team := range teams {
+		rows = append(rows, importRow{row: i + 1, team: team})
+	}
+
+	return rows, nil
+}
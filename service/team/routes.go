@@ -4,6 +4,8 @@ import (
 	"football-simulation/types"
 	"football-simulation/utils"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
@@ -18,6 +20,13 @@ func NewHandler(service types.TeamService) *Handler {
 
 func (h *Handler) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/teams", h.handleGetTeams).Methods("GET")
+	router.HandleFunc("/teams", h.handleCreateTeam).Methods("POST")
+	router.HandleFunc("/teams/import", h.handleImportTeams).Methods("POST")
+	router.HandleFunc("/teams/{id}", h.handleGetTeam).Methods("GET")
+	router.HandleFunc("/teams/{id}", h.handleReplaceTeam).Methods("PUT")
+	router.HandleFunc("/teams/{id}", h.handlePatchTeam).Methods("PATCH")
+	router.HandleFunc("/teams/{id}", h.handleDeleteTeam).Methods("DELETE")
+	router.HandleFunc("/teams/{id}/vs/{otherId}", h.handleGetHeadToHead).Methods("GET")
 
 }
 
@@ -25,9 +34,160 @@ func (h *Handler) handleGetTeams(w http.ResponseWriter, r *http.Request) {
 	teams, err := h.service.GetTeams()
 
 	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, err)
+		utils.WriteServiceError(w, err)
 		return
 	}
 
 	utils.WriteSuccess(w, http.StatusOK, teams)
 }
+
+func (h *Handler) handleGetTeam(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	team, err := h.service.GetTeamByID(id)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	if team.ID == 0 {
+		utils.WriteServiceError(w, ErrTeamNotFound.Errorf("team %d not found", id))
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, team)
+}
+
+func (h *Handler) handleCreateTeam(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateTeamRequest
+	if !utils.ParseAndValidate(w, r, &req) {
+		return
+	}
+
+	team, err := h.service.CreateTeam(req)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusCreated, team)
+}
+
+func (h *Handler) handleReplaceTeam(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req types.CreateTeamRequest
+	if !utils.ParseAndValidate(w, r, &req) {
+		return
+	}
+
+	team, err := h.service.ReplaceTeam(id, req)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, team)
+}
+
+func (h *Handler) handlePatchTeam(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var req types.PatchTeamRequest
+	if !utils.ParseAndValidate(w, r, &req) {
+		return
+	}
+
+	team, err := h.service.PatchTeam(id, req)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, team)
+}
+
+func (h *Handler) handleDeleteTeam(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.DeleteTeam(id); err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, nil)
+}
+
+func (h *Handler) handleImportTeams(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = ImportFormatJSON
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "text/csv") {
+			format = ImportFormatCSV
+		}
+	}
+
+	options := types.TeamImportOptions{
+		DryRun: query.Get("dry_run") == "true",
+		Upsert: query.Get("upsert") == "true",
+	}
+
+	report, err := h.service.ImportTeams(format, r.Body, options)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	if len(report.Errors) > 0 {
+		utils.WriteJSON(w, http.StatusBadRequest, types.Response{
+			Status:  "error",
+			Code:    ErrInvalidImport.Code,
+			Data:    report,
+			Message: "the import has invalid rows, nothing was written",
+		})
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, report)
+}
+
+func (h *Handler) handleGetHeadToHead(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	otherID, err := strconv.Atoi(vars["otherId"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	headToHead, err := h.service.GetHeadToHead(id, otherID)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, headToHead)
+}
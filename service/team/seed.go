@@ -0,0 +1,9 @@
+package team
+
+import _ "embed"
+
+// DefaultTeams is the bundled team dataset in the JSON import format, used to
+// seed a fresh database.
+//
+//go:embed data/default_teams.json
+var DefaultTeams []byte
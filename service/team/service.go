@@ -1,13 +1,57 @@
 package team
 
-import "football-simulation/types"
+import (
+	"football-simulation/service/league"
+	"football-simulation/types"
+	"strings"
+)
+
+var (
+	ErrTeamNotFound     = types.NewError(types.ErrorKindNotFound, "team_not_found", "team not found")
+	ErrTeamNameTaken    = types.NewError(types.ErrorKindConflict, "team_name_taken", "a team with this name already exists")
+	ErrTeamNameBlank    = types.NewError(types.ErrorKindValidation, "team_name_blank", "team name cannot be blank")
+	ErrLeagueInProgress = types.NewError(types.ErrorKindConflict, "league_in_progress", "teams cannot be added or removed while a league is in progress, restart the league first")
+	ErrSameTeam         = types.NewError(types.ErrorKindValidation, "same_team", "a team cannot be compared with itself")
+)
 
 type Service struct {
-	store types.Teamstore
+	store       types.Teamstore
+	leagueStore types.LeagueStore
+	eventStore  types.LeagueEventStore
+	transactor  types.Transactor
+	// inTx is set on the copies made by WithTx, whose methods run in the
+	// caller's transaction instead of opening their own.
+	inTx bool
 }
 
-func NewService(store types.Teamstore) *Service {
-	return &Service{store: store}
+func NewService(store types.Teamstore, leagueStore types.LeagueStore, eventStore types.LeagueEventStore, transactor types.Transactor) *Service {
+	return &Service{store: store, leagueStore: leagueStore, eventStore: eventStore, transactor: transactor}
+}
+
+func (s *Service) WithTx(tx types.DBTX) types.TeamService {
+	return s.withTxService(tx)
+}
+
+func (s *Service) withTxService(tx types.DBTX) *Service {
+	return &Service{
+		store:       s.store.WithTx(tx),
+		leagueStore: s.leagueStore.WithTx(tx),
+		eventStore:  s.eventStore.WithTx(tx),
+		transactor:  s.transactor,
+		inTx:        true,
+	}
+}
+
+// withinTransaction runs fn against a copy of the service bound to a new
+// transaction, or to the current one when the service already has one.
+func (s *Service) withinTransaction(fn func(txService *Service) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	return s.transactor.WithinTransaction(func(tx types.DBTX) error {
+		return fn(s.withTxService(tx))
+	})
 }
 
 func (s *Service) GetTeams() ([]types.Team, error) {
@@ -41,6 +85,252 @@ func (s *Service) GetTeamByName(name string) (*types.Team, error) {
 	return team, nil
 }
 
+// CreateTeam adds a team in one transaction with its event, holding the
+// league lock so a league cannot start between the check and the insert.
+func (s *Service) CreateTeam(request types.CreateTeamRequest) (*types.Team, error) {
+	var team *types.Team
+	err := s.withinTransaction(func(txService *Service) error {
+		var err error
+		team, err = txService.createTeam(request)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
+func (s *Service) createTeam(request types.CreateTeamRequest) (*types.Team, error) {
+	if err := s.checkLeagueNotInProgress(); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if err := s.checkNameAvailable(name, 0); err != nil {
+		return nil, err
+	}
+
+	team, err := s.store.CreateTeam(types.Team{Name: name, Strength: request.Strength, Metadata: request.Metadata})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.eventStore.AppendEvent(types.EventTeamCreated, teamChangedEvent(*team)); err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
+func (s *Service) ReplaceTeam(id int, request types.CreateTeamRequest) (*types.Team, error) {
+	metadata := request.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	return s.PatchTeam(id, types.PatchTeamRequest{Name: &request.Name, Strength: &request.Strength, Metadata: metadata})
+}
+
+func (s *Service) PatchTeam(id int, request types.PatchTeamRequest) (*types.Team, error) {
+	var team *types.Team
+	err := s.withinTransaction(func(txService *Service) error {
+		var err error
+		team, err = txService.patchTeam(id, request)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
+func (s *Service) patchTeam(id int, request types.PatchTeamRequest) (*types.Team, error) {
+	team, err := s.getExistingTeam(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Name != nil {
+		name := strings.TrimSpace(*request.Name)
+		if err := s.checkNameAvailable(name, team.ID); err != nil {
+			return nil, err
+		}
+		team.Name = name
+	}
+
+	if request.Strength != nil {
+		team.Strength = *request.Strength
+	}
+
+	if request.Metadata != nil {
+		team.Metadata = request.Metadata
+	}
+
+	if err := s.store.UpdateTeam(*team); err != nil {
+		return nil, err
+	}
+
+	if err := s.eventStore.AppendEvent(types.EventTeamUpdated, teamChangedEvent(*team)); err != nil {
+		return nil, err
+	}
+
+	return team, nil
+}
+
+func (s *Service) DeleteTeam(id int) error {
+	return s.withinTransaction(func(txService *Service) error {
+		return txService.deleteTeam(id)
+	})
+}
+
+func (s *Service) deleteTeam(id int) error {
+	team, err := s.getExistingTeam(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkLeagueNotInProgress(); err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteTeam(team.ID); err != nil {
+		return err
+	}
+
+	return s.eventStore.AppendEvent(types.EventTeamDeleted, teamChangedEvent(*team))
+}
+
+func (s *Service) getExistingTeam(id int) (*types.Team, error) {
+	team, err := s.store.GetTeamByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if team.ID == 0 {
+		return nil, ErrTeamNotFound
+	}
+
+	return team, nil
+}
+
+func (s *Service) checkNameAvailable(name string, teamID int) error {
+	if name == "" {
+		return ErrTeamNameBlank
+	}
+
+	existing, err := s.store.GetTeamByName(name)
+	if err != nil {
+		return err
+	}
+
+	if existing.ID != 0 && existing.ID != teamID {
+		return ErrTeamNameTaken
+	}
+
+	return nil
+}
+
+// checkLeagueNotInProgress rejects changes to the set of teams while a fixture
+// exists, since the fixture was generated for the current teams. It takes the
+// league lock first, so the caller must run in a transaction.
+func (s *Service) checkLeagueNotInProgress() error {
+	leagueInfo, err := s.leagueStore.GetLeagueInfo()
+	if err != nil {
+		return err
+	}
+
+	locked, err := s.leagueStore.TryLockLeague(leagueInfo.ID)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return league.ErrLeagueBusy
+	}
+
+	matches, err := s.leagueStore.GetAllMatches()
+	if err != nil {
+		return err
+	}
+
+	if len(matches) > 0 {
+		return ErrLeagueInProgress
+	}
+
+	return nil
+}
+
+func teamChangedEvent(team types.Team) types.TeamChangedEvent {
+	return types.TeamChangedEvent{TeamID: team.ID, Name: team.Name, Strength: team.Strength}
+}
+
+func (s *Service) GetHeadToHead(teamID, opponentID int) (*types.HeadToHead, error) {
+	if teamID == opponentID {
+		return nil, ErrSameTeam
+	}
+
+	team, err := s.GetTeamByID(teamID)
+	if err != nil {
+		return nil, err
+	}
+	if team.ID == 0 {
+		return nil, ErrTeamNotFound.Errorf("team %d not found", teamID)
+	}
+
+	opponent, err := s.GetTeamByID(opponentID)
+	if err != nil {
+		return nil, err
+	}
+	if opponent.ID == 0 {
+		return nil, ErrTeamNotFound.Errorf("team %d not found", opponentID)
+	}
+
+	matches, err := s.leagueStore.GetHeadToHeadMatches(team.ID, opponent.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	headToHead := &types.HeadToHead{
+		TeamID:       team.ID,
+		TeamName:     team.Name,
+		OpponentID:   opponent.ID,
+		OpponentName: opponent.Name,
+		Matches:      matches,
+	}
+
+	for i, match := range matches {
+		if !match.Played {
+			continue
+		}
+
+		goalsFor, goalsAgainst := match.Team1Score, match.Team2Score
+		if match.Team2ID == team.ID {
+			goalsFor, goalsAgainst = match.Team2Score, match.Team1Score
+		}
+
+		headToHead.Played++
+		headToHead.GoalsFor += goalsFor
+		headToHead.GoalsAgainst += goalsAgainst
+
+		if goalsFor > goalsAgainst {
+			headToHead.Wins++
+			if isBiggerWin(&matches[i], headToHead.BiggestWin) {
+				headToHead.BiggestWin = &matches[i]
+			}
+		} else if goalsFor < goalsAgainst {
+			headToHead.Losses++
+			if isBiggerWin(&matches[i], headToHead.BiggestLoss) {
+				headToHead.BiggestLoss = &matches[i]
+			}
+		} else {
+			headToHead.Draws++
+		}
+	}
+
+	return headToHead, nil
+}
+
 func (s *Service) UpdateTeam(team types.Team) error {
 	err := s.store.UpdateTeam(team)
 
@@ -50,7 +340,7 @@ func (s *Service) UpdateTeam(team types.Team) error {
 	return nil
 }
 
-func (s *Service) UpdateTeamStatsReverse(match types.Match) error {
+func (s *Service) UpdateTeamStatsReverse(match types.Match, isUpdate bool) error {
 	team1, err := s.GetTeamByID(match.Team1ID)
 	if err != nil {
 		return err
@@ -76,6 +366,11 @@ func (s *Service) UpdateTeamStatsReverse(match types.Match) error {
 		team2.Points--
 	}
 
+	if !isUpdate {
+		team1.Matches--
+		team2.Matches--
+	}
+
 	team1.GoalsFor -= match.Team1Score
 	team1.GoalsAgainst -= match.Team2Score
 	team1.GoalsDifference = team1.GoalsFor - team1.GoalsAgainst
@@ -147,3 +442,25 @@ func (s *Service) ResetTeams() error {
 
 	return nil
 }
+
+// isBiggerWin reports whether match was won by a wider margin than current,
+// using the winner's goals to break ties.
+func isBiggerWin(match, current *types.HeadToHeadMatch) bool {
+	if current == nil {
+		return true
+	}
+
+	margin, currentMargin := winningMargin(*match), winningMargin(*current)
+	if margin != currentMargin {
+		return margin > currentMargin
+	}
+
+	return max(match.Team1Score, match.Team2Score) > max(current.Team1Score, current.Team2Score)
+}
+
+func winningMargin(match types.HeadToHeadMatch) int {
+	if match.Team1Score > match.Team2Score {
+		return match.Team1Score - match.Team2Score
+	}
+	return match.Team2Score - match.Team1Score
+}
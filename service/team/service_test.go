@@ -0,0 +1,90 @@
+package team_test
+
+import (
+	"errors"
+	"football-simulation/database/memory"
+	"football-simulation/service/team"
+	"football-simulation/types"
+	"testing"
+)
+
+var errAppend = errors.New("event log unavailable")
+
+// failingEventStore fails every append once fail is set.
+type failingEventStore struct {
+	types.LeagueEventStore
+	fail bool
+}
+
+func (s *failingEventStore) WithTx(tx types.DBTX) types.LeagueEventStore {
+	return s
+}
+
+func (s *failingEventStore) AppendEvent(eventType string, payload any) error {
+	if s.fail {
+		return errAppend
+	}
+	return s.LeagueEventStore.AppendEvent(eventType, payload)
+}
+
+func newService(db *memory.DB, events types.LeagueEventStore) *team.Service {
+	return team.NewService(memory.NewTeamStore(db), memory.NewLeagueStore(db), events, memory.NewTransactor(db))
+}
+
+func TestTeamChangesRollBackWithoutTheirEvent(t *testing.T) {
+	db := memory.NewDB()
+	events := &failingEventStore{LeagueEventStore: memory.NewEventStore(db)}
+	service := newService(db, events)
+
+	chelsea, err := service.CreateTeam(types.CreateTeamRequest{Name: "Chelsea", Strength: 80})
+	if err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+
+	events.fail = true
+	if _, err := service.CreateTeam(types.CreateTeamRequest{Name: "Arsenal", Strength: 85}); !errors.Is(err, errAppend) {
+		t.Fatalf("CreateTeam = %v, want %v", err, errAppend)
+	}
+	if _, err := service.PatchTeam(chelsea.ID, types.PatchTeamRequest{Name: ptr("Chelsea FC")}); !errors.Is(err, errAppend) {
+		t.Fatalf("PatchTeam = %v, want %v", err, errAppend)
+	}
+	if err := service.DeleteTeam(chelsea.ID); !errors.Is(err, errAppend) {
+		t.Fatalf("DeleteTeam = %v, want %v", err, errAppend)
+	}
+
+	teams, err := service.GetTeams()
+	if err != nil {
+		t.Fatalf("GetTeams: %v", err)
+	}
+	if len(teams) != 1 || teams[0].Name != "Chelsea" {
+		t.Errorf("teams = %+v, want Chelsea alone and unchanged", teams)
+	}
+}
+
+func TestTeamChangesJoinTheCallersTransaction(t *testing.T) {
+	db := memory.NewDB()
+	service := newService(db, memory.NewEventStore(db))
+
+	errRollback := errors.New("rollback")
+	err := memory.NewTransactor(db).WithinTransaction(func(tx types.DBTX) error {
+		if _, err := service.WithTx(tx).CreateTeam(types.CreateTeamRequest{Name: "Chelsea", Strength: 80}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("WithinTransaction = %v, want %v", err, errRollback)
+	}
+
+	teams, err := service.GetTeams()
+	if err != nil {
+		t.Fatalf("GetTeams: %v", err)
+	}
+	if len(teams) != 0 {
+		t.Errorf("teams = %+v, want the team rolled back with the caller's transaction", teams)
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
@@ -2,24 +2,30 @@ package team
 
 import (
 	"database/sql"
+	"encoding/json"
 	"football-simulation/types"
 )
 
 type Store struct {
-	db *sql.DB
+	db types.DBTX
 }
 
-func NewStore(db *sql.DB) *Store {
+func NewStore(db types.DBTX) *Store {
 	return &Store{db: db}
 }
 
+func (s *Store) WithTx(tx types.DBTX) types.Teamstore {
+	return &Store{db: tx}
+}
+
 func (s *Store) GetTeams() ([]types.Team, error) {
 
-	rows, err := s.db.Query("SELECT * FROM teams")
+	rows, err := s.db.Query("SELECT id, name, strength, points, matches, wins, draws, losses, goals_for, goals_against, goals_difference, temporary_drop, points_deducted, metadata FROM teams")
 
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	teams := make([]types.Team, 0)
 
@@ -37,11 +43,12 @@ func (s *Store) GetTeams() ([]types.Team, error) {
 }
 
 func (s *Store) GetTeamByID(id int) (*types.Team, error) {
-	rows, err := s.db.Query("SELECT * FROM teams WHERE id = $1", id)
+	rows, err := s.db.Query("SELECT id, name, strength, points, matches, wins, draws, losses, goals_for, goals_against, goals_difference, temporary_drop, points_deducted, metadata FROM teams WHERE id = $1", id)
 
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	team := new(types.Team)
 
@@ -56,11 +63,12 @@ func (s *Store) GetTeamByID(id int) (*types.Team, error) {
 	return team, nil
 }
 func (s *Store) GetTeamByName(name string) (*types.Team, error) {
-	rows, err := s.db.Query("SELECT * FROM teams WHERE name = $1", name)
+	rows, err := s.db.Query("SELECT id, name, strength, points, matches, wins, draws, losses, goals_for, goals_against, goals_difference, temporary_drop, points_deducted, metadata FROM teams WHERE name = $1", name)
 
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	team := new(types.Team)
 
@@ -75,10 +83,36 @@ func (s *Store) GetTeamByName(name string) (*types.Team, error) {
 	return team, nil
 }
 
+func (s *Store) CreateTeam(team types.Team) (*types.Team, error) {
+	metadata, err := encodeMetadata(team.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.QueryRow("INSERT INTO teams (name, strength, metadata) VALUES ($1, $2, $3) RETURNING id", team.Name, team.Strength, metadata).Scan(&team.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (s *Store) DeleteTeam(id int) error {
+	_, err := s.db.Exec("DELETE FROM teams WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (s *Store) UpdateTeam(team types.Team) error {
-	_, err := s.db.Exec(`UPDATE teams
-	SET name = $1, strength = $2, points = $3, matches = $4, wins = $5, draws = $6, losses = $7, goals_for = $8, goals_against = $9, goals_difference = $10, temporary_drop = $11
-	WHERE id = $12`, team.Name, team.Strength, team.Points, team.Matches, team.Wins, team.Draws, team.Losses, team.GoalsFor, team.GoalsAgainst, team.GoalsDifference, team.TemporaryDrop, team.ID)
+	metadata, err := encodeMetadata(team.Metadata)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`UPDATE teams
+	SET name = $1, strength = $2, points = $3, matches = $4, wins = $5, draws = $6, losses = $7, goals_for = $8, goals_against = $9, goals_difference = $10, temporary_drop = $11, points_deducted = $12, metadata = $13
+	WHERE id = $14`, team.Name, team.Strength, team.Points, team.Matches, team.Wins, team.Draws, team.Losses, team.GoalsFor, team.GoalsAgainst, team.GoalsDifference, team.TemporaryDrop, team.PointsDeducted, metadata, team.ID)
 
 	if err != nil {
 		return err
@@ -87,7 +121,7 @@ func (s *Store) UpdateTeam(team types.Team) error {
 }
 
 func (s *Store) ResetTeams() error {
-	_, err := s.db.Exec("UPDATE teams SET points = 0, matches = 0, wins = 0, draws = 0, losses = 0, goals_for = 0, goals_against = 0, goals_difference = 0, temporary_drop = 0")
+	_, err := s.db.Exec("UPDATE teams SET points = 0, matches = 0, wins = 0, draws = 0, losses = 0, goals_for = 0, goals_against = 0, goals_difference = 0, temporary_drop = 0, points_deducted = 0")
 	if err != nil {
 		return err
 	}
@@ -96,6 +130,7 @@ func (s *Store) ResetTeams() error {
 
 func scanRowsIntoTeam(rows *sql.Rows) (*types.Team, error) {
 	team := new(types.Team)
+	var metadata []byte
 
 	err := rows.Scan(
 		&team.ID,
@@ -110,11 +145,26 @@ func scanRowsIntoTeam(rows *sql.Rows) (*types.Team, error) {
 		&team.GoalsAgainst,
 		&team.GoalsDifference,
 		&team.TemporaryDrop,
+		&team.PointsDeducted,
+		&metadata,
 	)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &team.Metadata); err != nil {
+			return nil, err
+		}
+	}
+
 	return team, nil
 }
+
+func encodeMetadata(metadata map[string]string) ([]byte, error) {
+	if metadata == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(metadata)
+}
@@ -0,0 +1,184 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"football-simulation/types"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxAttempts is how often a delivery is tried before it is marked failed.
+	maxAttempts = 6
+	// batchSize is how many due deliveries one poll sends.
+	batchSize = 20
+)
+
+// Envelope is the JSON body of every delivery.
+type Envelope struct {
+	ID        int             `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Dispatcher sends pending deliveries. A failed attempt is retried with
+// exponential backoff, starting at backoff, until maxAttempts is reached.
+type Dispatcher struct {
+	store    types.WebhookStore
+	client   *http.Client
+	interval time.Duration
+	backoff  time.Duration
+}
+
+func NewDispatcher(store types.WebhookStore, interval, timeout, backoff time.Duration) *Dispatcher {
+	return &Dispatcher{
+		store:    store,
+		client:   &http.Client{Timeout: timeout},
+		interval: interval,
+		backoff:  backoff,
+	}
+}
+
+// Run polls for due deliveries every interval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DeliverDue(ctx); err != nil {
+			log.Printf("webhook dispatcher: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue makes one attempt at every due delivery. Each delivery is
+// claimed with a lease first, so several dispatchers on one database never
+// send the same attempt twice while the lease holds, and only the holder of
+// the lease records its attempt.
+func (d *Dispatcher) DeliverDue(ctx context.Context) error {
+	deliveries, err := d.store.GetDueDeliveries(time.Now().UTC().Truncate(time.Microsecond), batchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		// The lease starts when the delivery is claimed, not when the batch
+		// was read, so it covers the whole attempt. An attempt that outlives
+		// the client timeout is retried, as if the dispatcher had stopped
+		// mid-attempt.
+		lease := time.Now().UTC().Truncate(time.Microsecond).Add(d.client.Timeout + d.backoff)
+		claimed, err := d.store.ClaimDelivery(delivery.ID, delivery.NextAttemptAt, lease)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		webhook, err := d.store.GetWebhookByID(delivery.WebhookID)
+		if err != nil {
+			return err
+		}
+		if webhook.ID == 0 {
+			continue
+		}
+
+		updated, err := d.store.UpdateDelivery(d.attempt(ctx, *webhook, delivery), lease)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			log.Printf("webhook delivery %d: the lease ran out before the attempt was recorded", delivery.ID)
+		}
+	}
+
+	return nil
+}
+
+// attempt sends delivery once and returns it with the outcome recorded.
+func (d *Dispatcher) attempt(ctx context.Context, webhook types.Webhook, delivery types.WebhookDelivery) types.WebhookDelivery {
+	delivery.Attempts++
+	delivery.ResponseStatus = 0
+	delivery.Error = ""
+
+	status, err := d.send(ctx, webhook, delivery)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	delivery.ResponseStatus = status
+
+	switch {
+	case err == nil:
+		delivery.Status = types.DeliveryDelivered
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= maxAttempts:
+		delivery.Status = types.DeliveryFailed
+		delivery.Error = err.Error()
+	default:
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = now.Add(d.backoff << (delivery.Attempts - 1))
+	}
+
+	return delivery
+}
+
+func (d *Dispatcher) send(ctx context.Context, webhook types.Webhook, delivery types.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(Envelope{
+		ID:        delivery.ID,
+		Event:     delivery.EventType,
+		CreatedAt: delivery.CreatedAt,
+		Data:      delivery.Payload,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "football-simulation-webhooks")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", strconv.Itoa(delivery.ID))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+Sign(webhook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("the endpoint responded with %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of timestamp, a dot and body, keyed with
+// secret. Receivers recompute it to check a delivery came from this server.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"football-simulation/types"
+	"football-simulation/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	service types.WebhookService
+}
+
+func NewHandler(service types.WebhookService) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/webhooks", h.handleCreateWebhook).Methods("POST")
+	router.HandleFunc("/webhooks", h.handleGetWebhooks).Methods("GET")
+	router.HandleFunc("/webhooks/{id}", h.handleGetWebhook).Methods("GET")
+	router.HandleFunc("/webhooks/{id}", h.handleDeleteWebhook).Methods("DELETE")
+	router.HandleFunc("/webhooks/{id}/deliveries", h.handleGetDeliveries).Methods("GET")
+}
+
+func (h *Handler) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateWebhookRequest
+	if !utils.ParseAndValidate(w, r, &req) {
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(req)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusCreated, webhook)
+}
+
+func (h *Handler) handleGetWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.GetWebhooks()
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, webhooks)
+}
+
+func (h *Handler) handleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	webhook, err := h.service.GetWebhook(id)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, webhook)
+}
+
+func (h *Handler) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.service.DeleteWebhook(id); err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, nil)
+}
+
+func (h *Handler) handleGetDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	deliveries, err := h.service.GetDeliveries(id)
+	if err != nil {
+		utils.WriteServiceError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, http.StatusOK, deliveries)
+}
@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"football-simulation/types"
+	"slices"
+	"time"
+)
+
+var ErrWebhookNotFound = types.NewError(types.ErrorKindNotFound, "webhook_not_found", "webhook not found")
+
+type Service struct {
+	store types.WebhookStore
+}
+
+func NewService(store types.WebhookStore) *Service {
+	return &Service{store: store}
+}
+
+func (s *Service) WithTx(tx types.DBTX) types.WebhookService {
+	return &Service{store: s.store.WithTx(tx)}
+}
+
+// CreateWebhook registers a webhook. A secret is generated when the request
+// has none; either way this is the only response that contains it.
+func (s *Service) CreateWebhook(request types.CreateWebhookRequest) (*types.Webhook, error) {
+	secret := request.Secret
+	if secret == "" {
+		generated := make([]byte, 32)
+		if _, err := rand.Read(generated); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(generated)
+	}
+
+	eventTypes := slices.Clone(request.EventTypes)
+	slices.Sort(eventTypes)
+
+	return s.store.CreateWebhook(types.Webhook{
+		URL:        request.URL,
+		Secret:     secret,
+		EventTypes: slices.Compact(eventTypes),
+	})
+}
+
+func (s *Service) GetWebhooks() ([]types.Webhook, error) {
+	webhooks, err := s.store.GetWebhooks()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+func (s *Service) GetWebhook(id int) (*types.Webhook, error) {
+	webhook, err := s.getWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+func (s *Service) DeleteWebhook(id int) error {
+	if _, err := s.getWebhook(id); err != nil {
+		return err
+	}
+	return s.store.DeleteWebhook(id)
+}
+
+func (s *Service) GetDeliveries(webhookID int) ([]types.WebhookDelivery, error) {
+	if _, err := s.getWebhook(webhookID); err != nil {
+		return nil, err
+	}
+	return s.store.GetDeliveries(webhookID)
+}
+
+// Enqueue records a delivery of the event for every webhook subscribed to
+// it. Called inside the transaction that changes the league, the deliveries
+// exist exactly when the change is committed; the Dispatcher sends them.
+func (s *Service) Enqueue(eventType string, payload any) error {
+	webhooks, err := s.store.GetWebhooks()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	for _, webhook := range webhooks {
+		if !slices.Contains(webhook.EventTypes, eventType) {
+			continue
+		}
+
+		_, err := s.store.CreateDelivery(types.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventType:     eventType,
+			Payload:       data,
+			Status:        types.DeliveryPending,
+			NextAttemptAt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) getWebhook(id int) (*types.Webhook, error) {
+	webhook, err := s.store.GetWebhookByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if webhook.ID == 0 {
+		return nil, ErrWebhookNotFound.Errorf("webhook %d not found", id)
+	}
+	return webhook, nil
+}
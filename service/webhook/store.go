@@ -0,0 +1,205 @@
+package webhook
+
+import (
+	"database/sql"
+	"football-simulation/types"
+	"strings"
+	"time"
+)
+
+type Store struct {
+	db types.DBTX
+}
+
+func NewStore(db types.DBTX) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) WithTx(tx types.DBTX) types.WebhookStore {
+	return &Store{db: tx}
+}
+
+func (s *Store) CreateWebhook(webhook types.Webhook) (*types.Webhook, error) {
+	err := s.db.QueryRow("INSERT INTO webhooks (url, secret, event_types) VALUES ($1, $2, $3) RETURNING id, created_at",
+		webhook.URL, webhook.Secret, strings.Join(webhook.EventTypes, ",")).Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (s *Store) GetWebhooks() ([]types.Webhook, error) {
+	rows, err := s.db.Query("SELECT id, url, secret, event_types, created_at FROM webhooks ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRowsIntoWebhooks(rows)
+}
+
+func (s *Store) GetWebhookByID(id int) (*types.Webhook, error) {
+	rows, err := s.db.Query("SELECT id, url, secret, event_types, created_at FROM webhooks WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks, err := scanRowsIntoWebhooks(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(webhooks) == 0 {
+		return &types.Webhook{}, nil
+	}
+	return &webhooks[0], nil
+}
+
+func (s *Store) DeleteWebhook(id int) error {
+	_, err := s.db.Exec("DELETE FROM webhooks WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) CreateDelivery(delivery types.WebhookDelivery) (*types.WebhookDelivery, error) {
+	err := s.db.QueryRow(`INSERT INTO webhook_deliveries (webhook_id, event_type, payload, status, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		delivery.WebhookID, delivery.EventType, []byte(delivery.Payload), delivery.Status, delivery.NextAttemptAt).
+		Scan(&delivery.ID, &delivery.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (s *Store) GetDeliveries(webhookID int) ([]types.WebhookDelivery, error) {
+	rows, err := s.db.Query(`SELECT `+deliveryColumns+` FROM webhook_deliveries
+		WHERE webhook_id = $1 ORDER BY id DESC`, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRowsIntoDeliveries(rows)
+}
+
+// GetDueDeliveries returns the oldest pending deliveries whose next attempt
+// is at or before now.
+func (s *Store) GetDueDeliveries(now time.Time, limit int) ([]types.WebhookDelivery, error) {
+	rows, err := s.db.Query(`SELECT `+deliveryColumns+` FROM webhook_deliveries
+		WHERE status = $1 AND next_attempt_at <= $2 ORDER BY next_attempt_at, id LIMIT $3`,
+		types.DeliveryPending, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanRowsIntoDeliveries(rows)
+}
+
+// ClaimDelivery moves the next attempt of a pending delivery from
+// nextAttemptAt to leaseUntil. It reports false when another dispatcher has
+// moved it first, so every attempt is made once.
+func (s *Store) ClaimDelivery(id int, nextAttemptAt, leaseUntil time.Time) (bool, error) {
+	result, err := s.db.Exec("UPDATE webhook_deliveries SET next_attempt_at = $1 WHERE id = $2 AND status = $3 AND next_attempt_at = $4",
+		leaseUntil, id, types.DeliveryPending, nextAttemptAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// UpdateDelivery records an attempt at a delivery claimed until leaseUntil.
+// It reports false when the lease has been taken over by another dispatcher,
+// whose attempt is then the one recorded.
+func (s *Store) UpdateDelivery(delivery types.WebhookDelivery, leaseUntil time.Time) (bool, error) {
+	result, err := s.db.Exec(`UPDATE webhook_deliveries SET status = $1, attempts = $2, response_status = $3, error = $4,
+		next_attempt_at = $5, delivered_at = $6 WHERE id = $7 AND status = $8 AND next_attempt_at = $9`,
+		delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.Error,
+		delivery.NextAttemptAt, delivery.DeliveredAt, delivery.ID, types.DeliveryPending, leaseUntil)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+const deliveryColumns = "id, webhook_id, event_type, payload, status, attempts, response_status, error, next_attempt_at, created_at, delivered_at"
+
+func scanRowsIntoWebhooks(rows *sql.Rows) ([]types.Webhook, error) {
+	webhooks := make([]types.Webhook, 0)
+
+	for rows.Next() {
+		var webhook types.Webhook
+		var eventTypes string
+		err := rows.Scan(
+			&webhook.ID,
+			&webhook.URL,
+			&webhook.Secret,
+			&eventTypes,
+			&webhook.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		webhook.EventTypes = strings.Split(eventTypes, ",")
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func scanRowsIntoDeliveries(rows *sql.Rows) ([]types.WebhookDelivery, error) {
+	deliveries := make([]types.WebhookDelivery, 0)
+
+	for rows.Next() {
+		var delivery types.WebhookDelivery
+		var payload []byte
+		var deliveredAt sql.NullTime
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventType,
+			&payload,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.ResponseStatus,
+			&delivery.Error,
+			&delivery.NextAttemptAt,
+			&delivery.CreatedAt,
+			&deliveredAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		delivery.Payload = payload
+		if deliveredAt.Valid {
+			delivery.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
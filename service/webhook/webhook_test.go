@@ -0,0 +1,305 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"football-simulation/database/memory"
+	"football-simulation/service/webhook"
+	"football-simulation/types"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receiver is a stand-in for a webhook endpoint. It records every request
+// and answers with the next status of statuses, then with 200.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedRequest
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.requests = append(rc.requests, receivedRequest{header: r.Header.Clone(), body: body})
+
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status = rc.statuses[0]
+		rc.statuses = rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (rc *receiver) received() []receivedRequest {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]receivedRequest(nil), rc.requests...)
+}
+
+func newWebhook(t *testing.T, service *webhook.Service, url string, eventTypes ...string) *types.Webhook {
+	t.Helper()
+
+	created, err := service.CreateWebhook(types.CreateWebhookRequest{URL: url, EventTypes: eventTypes})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	return created
+}
+
+func TestDeliverySignature(t *testing.T) {
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	store := memory.NewWebhookStore(memory.NewDB())
+	service := webhook.NewService(store)
+	created := newWebhook(t, service, server.URL, types.WebhookWeekPlayed)
+	if len(created.Secret) != 64 {
+		t.Fatalf("generated secret %q, want 64 hex characters", created.Secret)
+	}
+
+	if err := service.Enqueue(types.WebhookWeekPlayed, types.WeekPlayedPayload{Season: 1, Week: 3}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	dispatcher := webhook.NewDispatcher(store, time.Second, time.Second, time.Minute)
+	if err := dispatcher.DeliverDue(context.Background()); err != nil {
+		t.Fatalf("DeliverDue: %v", err)
+	}
+
+	requests := rc.received()
+	if len(requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(requests))
+	}
+
+	req := requests[0]
+	timestamp := req.header.Get("X-Webhook-Timestamp")
+	want := "sha256=" + webhook.Sign(created.Secret, timestamp, req.body)
+	if got := req.header.Get("X-Webhook-Signature"); got != want {
+		t.Errorf("X-Webhook-Signature = %q, want %q", got, want)
+	}
+	if got := req.header.Get("X-Webhook-Event"); got != types.WebhookWeekPlayed {
+		t.Errorf("X-Webhook-Event = %q, want %q", got, types.WebhookWeekPlayed)
+	}
+
+	var envelope webhook.Envelope
+	if err := json.Unmarshal(req.body, &envelope); err != nil {
+		t.Fatalf("delivery body is not an envelope: %v", err)
+	}
+
+	var payload types.WeekPlayedPayload
+	if err := json.Unmarshal(envelope.Data, &payload); err != nil || payload.Week != 3 {
+		t.Errorf("envelope data = %s, want week 3", envelope.Data)
+	}
+
+	deliveries, err := service.GetDeliveries(created.ID)
+	if err != nil {
+		t.Fatalf("GetDeliveries: %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Status != types.DeliveryDelivered || deliveries[0].ResponseStatus != http.StatusOK {
+		t.Errorf("deliveries = %+v, want one delivered", deliveries)
+	}
+}
+
+func TestEnqueueSkipsUnsubscribedWebhooks(t *testing.T) {
+	store := memory.NewWebhookStore(memory.NewDB())
+	service := webhook.NewService(store)
+	restarts := newWebhook(t, service, "http://example.com/restarts", types.WebhookLeagueRestarted)
+	weeks := newWebhook(t, service, "http://example.com/weeks", types.WebhookWeekPlayed)
+
+	if err := service.Enqueue(types.WebhookLeagueRestarted, types.LeagueRestartedEvent{Season: 2}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	if deliveries, _ := service.GetDeliveries(restarts.ID); len(deliveries) != 1 {
+		t.Errorf("subscribed webhook has %d deliveries, want 1", len(deliveries))
+	}
+	if deliveries, _ := service.GetDeliveries(weeks.ID); len(deliveries) != 0 {
+		t.Errorf("unsubscribed webhook has %d deliveries, want 0", len(deliveries))
+	}
+}
+
+func TestDeliveryRetriesWithBackoff(t *testing.T) {
+	rc := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusBadGateway}}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	store := memory.NewWebhookStore(memory.NewDB())
+	service := webhook.NewService(store)
+	created := newWebhook(t, service, server.URL, types.WebhookLeagueRestarted)
+
+	if err := service.Enqueue(types.WebhookLeagueRestarted, types.LeagueRestartedEvent{Season: 2}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	backoff := 20 * time.Millisecond
+	dispatcher := webhook.NewDispatcher(store, time.Second, time.Second, backoff)
+
+	// the first attempt fails and the retry is not due yet
+	start := time.Now()
+	deliverDue(t, dispatcher)
+	deliverDue(t, dispatcher)
+	delivery := getDelivery(t, service, created.ID)
+	if len(rc.received()) != 1 || delivery.Attempts != 1 || delivery.Status != types.DeliveryPending {
+		t.Fatalf("after the first attempt: %d requests, delivery %+v, want one pending attempt", len(rc.received()), delivery)
+	}
+	if delivery.ResponseStatus != http.StatusInternalServerError || delivery.Error == "" {
+		t.Errorf("failed attempt recorded as %+v, want status 500 and an error", delivery)
+	}
+	if wait := delivery.NextAttemptAt.Sub(start); wait < backoff {
+		t.Errorf("first retry after %v, want at least %v", wait, backoff)
+	}
+
+	// the second attempt fails too and waits twice as long
+	time.Sleep(backoff)
+	deliverDue(t, dispatcher)
+	delivery = getDelivery(t, service, created.ID)
+	if delivery.Attempts != 2 || delivery.ResponseStatus != http.StatusBadGateway {
+		t.Fatalf("after the second attempt: %+v, want two attempts", delivery)
+	}
+
+	time.Sleep(2 * backoff)
+	deliverDue(t, dispatcher)
+	delivery = getDelivery(t, service, created.ID)
+	if delivery.Attempts != 3 || delivery.Status != types.DeliveryDelivered || delivery.Error != "" || delivery.DeliveredAt == nil {
+		t.Errorf("after the third attempt: %+v, want it delivered", delivery)
+	}
+	if len(rc.received()) != 3 {
+		t.Errorf("receiver got %d requests, want 3", len(rc.received()))
+	}
+}
+
+func TestDeliveryFailsAfterMaxAttempts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	store := memory.NewWebhookStore(memory.NewDB())
+	service := webhook.NewService(store)
+	created := newWebhook(t, service, server.URL, types.WebhookChampionDecided)
+
+	if err := service.Enqueue(types.WebhookChampionDecided, types.ChampionDecidedPayload{Season: 1}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	dispatcher := webhook.NewDispatcher(store, time.Second, time.Second, time.Millisecond)
+	for i := 0; i < 10; i++ {
+		deliverDue(t, dispatcher)
+		time.Sleep(40 * time.Millisecond)
+	}
+
+	delivery := getDelivery(t, service, created.ID)
+	if delivery.Status != types.DeliveryFailed || delivery.Attempts != 6 {
+		t.Errorf("delivery = %+v, want failed after 6 attempts", delivery)
+	}
+}
+
+func deliverDue(t *testing.T, dispatcher *webhook.Dispatcher) {
+	t.Helper()
+
+	if err := dispatcher.DeliverDue(context.Background()); err != nil {
+		t.Fatalf("DeliverDue: %v", err)
+	}
+}
+
+func getDelivery(t *testing.T, service *webhook.Service, webhookID int) types.WebhookDelivery {
+	t.Helper()
+
+	deliveries, err := service.GetDeliveries(webhookID)
+	if err != nil {
+		t.Fatalf("GetDeliveries: %v", err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("GetDeliveries returned %d deliveries, want 1", len(deliveries))
+	}
+	return deliveries[0]
+}
+
+// leaseRecorder records how long each claim leases its delivery for, from
+// the moment it is made.
+type leaseRecorder struct {
+	types.WebhookStore
+	mu     sync.Mutex
+	leases []time.Duration
+}
+
+func (s *leaseRecorder) ClaimDelivery(id int, nextAttemptAt, leaseUntil time.Time) (bool, error) {
+	s.mu.Lock()
+	s.leases = append(s.leases, time.Until(leaseUntil))
+	s.mu.Unlock()
+	return s.WebhookStore.ClaimDelivery(id, nextAttemptAt, leaseUntil)
+}
+
+func TestLeaseCoversEveryAttemptOfABatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	store := &leaseRecorder{WebhookStore: memory.NewWebhookStore(memory.NewDB())}
+	service := webhook.NewService(store)
+	newWebhook(t, service, server.URL, types.WebhookWeekPlayed)
+	for week := 1; week <= 3; week++ {
+		if err := service.Enqueue(types.WebhookWeekPlayed, types.WeekPlayedPayload{Season: 1, Week: week}); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+
+	// every claim must outlast a send that takes the whole client timeout
+	const timeout = time.Second
+	dispatcher := webhook.NewDispatcher(store, time.Second, timeout, 20*time.Millisecond)
+	deliverDue(t, dispatcher)
+
+	if len(store.leases) != 3 {
+		t.Fatalf("got %d claims, want 3", len(store.leases))
+	}
+	for i, lease := range store.leases {
+		if lease < timeout {
+			t.Errorf("claim %d leased its delivery for %v, want at least the %v timeout", i+1, lease, timeout)
+		}
+	}
+}
+
+func TestAttemptAfterLostLeaseIsNotRecorded(t *testing.T) {
+	store := memory.NewWebhookStore(memory.NewDB())
+	service := webhook.NewService(store)
+
+	// while the attempt is sent, the lease runs out and another dispatcher
+	// claims the delivery
+	var created *types.Webhook
+	var takenOver time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivery := getDelivery(t, service, created.ID)
+		takenOver = delivery.NextAttemptAt.Add(time.Hour)
+		if claimed, err := store.ClaimDelivery(delivery.ID, delivery.NextAttemptAt, takenOver); err != nil || !claimed {
+			t.Errorf("ClaimDelivery by the other dispatcher = %v, %v", claimed, err)
+		}
+	}))
+	defer server.Close()
+
+	created = newWebhook(t, service, server.URL, types.WebhookWeekPlayed)
+	if err := service.Enqueue(types.WebhookWeekPlayed, types.WeekPlayedPayload{Season: 1, Week: 1}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	deliverDue(t, webhook.NewDispatcher(store, time.Second, time.Second, time.Minute))
+
+	delivery := getDelivery(t, service, created.ID)
+	if delivery.Status != types.DeliveryPending || delivery.Attempts != 0 || !delivery.NextAttemptAt.Equal(takenOver) {
+		t.Errorf("delivery = %+v, want it left to the dispatcher holding the lease", delivery)
+	}
+}
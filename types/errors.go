@@ -0,0 +1,45 @@
+package types
+
+import "fmt"
+
+// ErrorKind classifies a domain error. The HTTP handlers map every kind to a
+// status code.
+type ErrorKind int
+
+const (
+	ErrorKindInternal ErrorKind = iota
+	ErrorKindValidation
+	ErrorKindNotFound
+	ErrorKindConflict
+	ErrorKindPreconditionFailed
+	ErrorKindUnauthorized
+	ErrorKindForbidden
+)
+
+// Error is a domain error with a stable, machine-readable code that clients
+// can rely on. Messages are meant for people and may change; codes may not.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+}
+
+func NewError(kind ErrorKind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is matches errors by code, so the copies returned by Errorf still match
+// the sentinel they were made from.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Errorf returns a copy of e with a more specific message.
+func (e *Error) Errorf(format string, args ...any) *Error {
+	return &Error{Kind: e.Kind, Code: e.Code, Message: fmt.Sprintf(format, args...)}
+}
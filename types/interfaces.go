@@ -1,8 +1,30 @@
 package types
 
+import (
+	"context"
+	"database/sql"
+	"io"
+	"time"
+)
+
+// DBTX is the query surface shared by *sql.DB and *sql.Tx, so stores can run
+// either directly against the database or inside a transaction.
+type DBTX interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// Transactor runs fn inside a single transaction, committing when fn returns
+// nil and rolling back otherwise.
+type Transactor interface {
+	WithinTransaction(fn func(tx DBTX) error) error
+}
+
 type LeagueStore interface {
 	GetLeagueInfo() (League, error)
 	ClearFixtures() error
+	ArchiveFixtures() error
 	GetStandings() ([]Team, error)
 	GetMatchesByWeek(week int) ([]Match, error)
 	GetMatchByID(id int) (*Match, error)
@@ -11,28 +33,39 @@ type LeagueStore interface {
 	GetMatchesForNextWeek() ([]Match, error)
 	SaveMatchResult(match Match) error
 	IncrementWeek() error
+	TryLockLeague(leagueID int) (bool, error)
 	UpdateLeague(league League) error
 	GetAllMatches() ([]Match, error)
+	GetHeadToHeadMatches(teamID, opponentID int) ([]HeadToHeadMatch, error)
 	GetPredictions() ([]Prediction, error)
+	SaveMatchEvent(event MatchEvent) error
+	GetMatchEvents(matchID int) ([]MatchEvent, error)
+	WithTx(tx DBTX) LeagueStore
 }
 
 type LeagueService interface {
 	StartLeague() error
-	NextWeek() ([]Match, *Team, error)
-	PlayAll() ([]MatchResult, *Team, error)
+	NextWeek(leagueVersion int) ([]Match, *Team, error)
+	PlayAll(leagueVersion int) ([]MatchResult, *Team, error)
+	GetLeague() (League, error)
+	GetMatch(id int) (*MatchResult, error)
 	GetWeekResults() ([]MatchResult, error)
-	UpdateMatch(match Match) error
+	UpdateMatch(match Match, change MatchChange) error
+	GetMatchHistory(matchID int) ([]MatchEvent, error)
+	UndoMatchChange(match Match, change MatchChange) error
 	GetMatchesByWeek(id int) ([]MatchResult, error)
 	GetAllMatches() ([]MatchResult, error)
-	RestartLeague() error
+	RestartLeague(leagueVersion int) error
 	GetStandings() ([]Team, error)
 	GetPredictions() ([]Prediction, error)
+	RunPredictions(ctx context.Context, simulations int, progress func(done int)) ([]Prediction, error)
 }
 
 type MatchStore interface {
 	GetAllMatches() ([]Match, error)
 	UpdateMatch(match Match) error
 	GetMatchByID(id int) (*Match, error)
+	GetHeadToHeadMatches(teamID, opponentID int) ([]HeadToHeadMatch, error)
 }
 
 type MatchService interface {
@@ -44,26 +77,207 @@ type Teamstore interface {
 	GetTeams() ([]Team, error)
 	GetTeamByID(id int) (*Team, error)
 	GetTeamByName(name string) (*Team, error)
+	CreateTeam(team Team) (*Team, error)
 	UpdateTeam(Team) error
+	DeleteTeam(id int) error
 	ResetTeams() error
+	WithTx(tx DBTX) Teamstore
 }
 
 type TeamService interface {
+	ImportTeams(format string, r io.Reader, options TeamImportOptions) (*TeamImportReport, error)
 	GetTeams() ([]Team, error)
 	GetTeamByID(id int) (*Team, error)
 	GetTeamByName(name string) (*Team, error)
-	UpdateTeamStatsReverse(match Match) error
+	GetHeadToHead(teamID, opponentID int) (*HeadToHead, error)
+	CreateTeam(request CreateTeamRequest) (*Team, error)
+	ReplaceTeam(id int, request CreateTeamRequest) (*Team, error)
+	PatchTeam(id int, request PatchTeamRequest) (*Team, error)
+	DeleteTeam(id int) error
+	UpdateTeamStatsReverse(match Match, isUpdate bool) error
 	UpdateTeamStats(team1, team2 Team, team1Score, team2Score int, isUpdate bool) error
 	UpdateTeam(Team) error
 	ResetTeams() error
+	WithTx(tx DBTX) TeamService
+}
+
+type SanctionStore interface {
+	CreateSanction(sanction Sanction) (*Sanction, error)
+	GetSanctions() ([]Sanction, error)
+	GetSanctionsByTeam(teamID int) ([]Sanction, error)
+	WithTx(tx DBTX) SanctionStore
+}
+
+type SanctionService interface {
+	ApplySanction(request SanctionRequest) (*Sanction, error)
+	GetSanctions() ([]Sanction, error)
+	GetSanctionsByTeam(teamID int) ([]Sanction, error)
+}
+
+type LeagueEventStore interface {
+	AppendEvent(eventType string, payload any) error
+	GetEvents(toEventID int) ([]LeagueEvent, error)
+	WithTx(tx DBTX) LeagueEventStore
+}
+
+type LeagueEventService interface {
+	GetEvents(toEventID int) ([]LeagueEvent, error)
+	Replay(toEventID int) (*LeagueState, error)
+	Rebuild() (*LeagueState, error)
+}
+
+type ExportService interface {
+	GetMatches(filter ExportFilter) ([]MatchResult, error)
+	GetStandings(filter ExportFilter) ([]Team, error)
+}
+
+type SnapshotService interface {
+	SaveSnapshot() (*Snapshot, error)
+	LoadSnapshot(snapshot Snapshot, options SnapshotLoadOptions) (*Snapshot, error)
+}
+
+type APIKeyStore interface {
+	CreateAPIKey(key APIKey, hash string) (*APIKey, error)
+	GetAPIKeyByHash(hash string) (*APIKey, error)
+	GetAPIKeys() ([]APIKey, error)
+	RevokeAPIKey(id int) error
+	ReplaceAPIKeyHash(id int, hash string) error
+	WithTx(tx DBTX) APIKeyStore
+}
+
+type AuthService interface {
+	CreateKey(name, role string) (string, *APIKey, error)
+	EnsureKey(name, key, role string) error
+	Authenticate(key string) (*APIKey, error)
+	GetKeys() ([]APIKey, error)
+	RevokeKey(id int) error
+}
+
+type WebhookStore interface {
+	CreateWebhook(webhook Webhook) (*Webhook, error)
+	GetWebhooks() ([]Webhook, error)
+	GetWebhookByID(id int) (*Webhook, error)
+	DeleteWebhook(id int) error
+	CreateDelivery(delivery WebhookDelivery) (*WebhookDelivery, error)
+	GetDeliveries(webhookID int) ([]WebhookDelivery, error)
+	GetDueDeliveries(now time.Time, limit int) ([]WebhookDelivery, error)
+	ClaimDelivery(id int, nextAttemptAt, leaseUntil time.Time) (bool, error)
+	UpdateDelivery(delivery WebhookDelivery, leaseUntil time.Time) (bool, error)
+	WithTx(tx DBTX) WebhookStore
+}
+
+type WebhookService interface {
+	CreateWebhook(request CreateWebhookRequest) (*Webhook, error)
+	GetWebhooks() ([]Webhook, error)
+	GetWebhook(id int) (*Webhook, error)
+	DeleteWebhook(id int) error
+	GetDeliveries(webhookID int) ([]WebhookDelivery, error)
+	Enqueue(eventType string, payload any) error
+	WithTx(tx DBTX) WebhookService
+}
+
+type ScheduleStore interface {
+	GetSchedule(leagueID int) (*LeagueSchedule, error)
+	SaveSchedule(schedule LeagueSchedule) error
+	DeleteSchedule(leagueID int) error
+	SetPaused(leagueID int, paused bool, nextRunAt *time.Time) error
+	ClaimRun(leagueID int, nextRunAt, followingRunAt time.Time) (bool, error)
+	RecordRun(leagueID int, ranAt time.Time, runErr string) error
+	WithTx(tx DBTX) ScheduleStore
+}
+
+type ScheduleService interface {
+	GetSchedule() (*LeagueSchedule, error)
+	SetSchedule(request ScheduleRequest) (*LeagueSchedule, error)
+	DeleteSchedule() error
+	Pause() (*LeagueSchedule, error)
+	Resume() (*LeagueSchedule, error)
+}
+
+type JobStore interface {
+	CreateJob(job Job) (*Job, error)
+	GetJob(id int) (*Job, error)
+	GetJobs(limit int) ([]Job, error)
+	UpdateJob(job Job) error
+	RequestCancel(id int) error
+	Heartbeat(ids []int, now time.Time) error
+	FailStaleJobs(before time.Time, message string) error
+	WithTx(tx DBTX) JobStore
+}
+
+type JobService interface {
+	Submit(request CreateJobRequest) (*Job, error)
+	GetJob(id int) (*Job, error)
+	GetJobs() ([]Job, error)
+	Cancel(id int) (*Job, error)
+}
+
+type CalendarStore interface {
+	GetCalendar(leagueID int) (*LeagueCalendar, error)
+	SaveCalendar(calendar LeagueCalendar) error
+	DeleteCalendar(leagueID int) error
+	GetMatchesBetween(from, to time.Time) ([]Match, error)
+	SetKickoff(matchID int, kickoffAt *time.Time) error
+	WithTx(tx DBTX) CalendarStore
+}
+
+type CalendarService interface {
+	GetCalendar() (*LeagueCalendar, error)
+	SetCalendar(config CalendarConfig) (*LeagueCalendar, error)
+	DeleteCalendar() error
+	ScheduleFixture(matches []Match) error
+	GetFixtures(filter FixtureFilter) ([]MatchResult, error)
+	WithTx(tx DBTX) CalendarService
+}
+
+type FixtureStore interface {
+	GetFixtureConfig(leagueID int) (*LeagueFixtureConfig, error)
+	SaveFixtureConfig(config LeagueFixtureConfig) error
+	WithTx(tx DBTX) FixtureStore
+}
+
+type FixtureService interface {
+	GetConfig() (*LeagueFixtureConfig, error)
+	SetConfig(config FixtureConfig) (*LeagueFixtureConfig, error)
+	Preview() (*FixturePreview, error)
+	Generate(teams []Team) ([]Match, int, error)
+	GenerateSplit(standings []Team, week int) ([]Match, error)
+	Analyse() (*FixtureAnalysis, error)
+	WithTx(tx DBTX) FixtureService
+}
+
+type PlayoffStore interface {
+	GetPlayoffConfig(leagueID int) (*LeaguePlayoffConfig, error)
+	SavePlayoffConfig(config LeaguePlayoffConfig) error
+	// GetPlayoffMatches returns the matches of season by round and slot.
+	GetPlayoffMatches(season int) ([]PlayoffMatch, error)
+	SavePlayoffMatches(matches []PlayoffMatch) ([]PlayoffMatch, error)
+	// DeletePlayoffMatches deletes the matches of fromSeason and of every
+	// season after it.
+	DeletePlayoffMatches(fromSeason int) error
+	WithTx(tx DBTX) PlayoffStore
+}
+
+type PlayoffService interface {
+	GetConfig() (*LeaguePlayoffConfig, error)
+	SetConfig(config PlayoffConfig) (*LeaguePlayoffConfig, error)
+	GetPlayoffs() (*Playoffs, error)
+	PlayRound() (*Playoffs, error)
+	WithTx(tx DBTX) PlayoffService
 }
 
 type SimulationStore interface {
-	SaveFixture(matches []Match) error
+	SaveFixture(matches []Match) ([]Match, error)
+	WithTx(tx DBTX) SimulationStore
 }
 
 type SimulationService interface {
-	GenerateFixture([]Team) error
+	GenerateFixture(teams []Team, config FixtureConfig) ([]Match, *FixtureReport, error)
+	PlanFixture(teams []Team, config FixtureConfig) ([]Match, *FixtureReport)
+	SaveFixture(matches []Match) ([]Match, error)
 	PlayMatch(team1, team2 Team) (int, int)
-	CalculateChampionshipOdds(teams []Team, matches []Match) ([]Prediction, error)
+	PlayKnockout(team1, team2 Team) KnockoutResult
+	Reseed(seed int64)
+	CalculateChampionshipOdds(ctx context.Context, teams []Team, matches []Match, simulations int, progress func(done int)) ([]Prediction, error)
+	WithTx(tx DBTX) SimulationService
 }
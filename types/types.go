@@ -1,62 +1,709 @@
 package types
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type League struct {
-	ID               int    `json:"id"`
-	Name             string `json:"name"`
-	CurrentWeek      int    `json:"current_week"`
-	TotalWeeks       int    `json:"total_weeks"`
-	ChampionTeamName string `json:"champion_team_name,omitempty"`
+	ID                      int    `json:"id"`
+	Name                    string `json:"name"`
+	CurrentWeek             int    `json:"current_week"`
+	TotalWeeks              int    `json:"total_weeks"`
+	ChampionTeamName        string `json:"champion_team_name,omitempty"`
+	PlayoffChampionTeamName string `json:"playoff_champion_team_name,omitempty"`
+	Season                  int    `json:"season"`
+	Seed                    int64  `json:"seed"`
+	Version                 int    `json:"version"`
 }
 
 type Team struct {
-	ID              int    `json:"id"`
-	Name            string `json:"name"`
-	Strength        int    `json:"strength"`
-	Points          int    `json:"points"`
-	Matches         int    `json:"matches"`
-	Wins            int    `json:"wins"`
-	Draws           int    `json:"draws"`
-	Losses          int    `json:"losses"`
-	GoalsFor        int    `json:"goals_for"`
-	GoalsAgainst    int    `json:"goals_against"`
-	GoalsDifference int    `json:"goals_difference"`
-	TemporaryDrop   int    `json:"temporary_drop,omitempty"`
+	ID              int               `json:"id"`
+	Name            string            `json:"name"`
+	Strength        int               `json:"strength"`
+	Points          int               `json:"points"`
+	Matches         int               `json:"matches"`
+	Wins            int               `json:"wins"`
+	Draws           int               `json:"draws"`
+	Losses          int               `json:"losses"`
+	GoalsFor        int               `json:"goals_for"`
+	GoalsAgainst    int               `json:"goals_against"`
+	GoalsDifference int               `json:"goals_difference"`
+	TemporaryDrop   int               `json:"temporary_drop,omitempty"`
+	PointsDeducted  int               `json:"points_deducted,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
 }
 
 type Match struct {
-	ID         int  `json:"id"`
-	Week       int  `json:"week"`
-	Team1ID    int  `json:"team1_id"`
-	Team2ID    int  `json:"team2_id"`
-	Team1Score int  `json:"team1_score"`
-	Team2Score int  `json:"team2_score"`
-	Played     bool `json:"played"`
+	ID         int        `json:"id"`
+	Week       int        `json:"week"`
+	Team1ID    int        `json:"team1_id"`
+	Team2ID    int        `json:"team2_id"`
+	Team1Score int        `json:"team1_score"`
+	Team2Score int        `json:"team2_score"`
+	Played     bool       `json:"played"`
+	Version    int        `json:"version"`
+	KickoffAt  *time.Time `json:"kickoff_at,omitempty"`
 }
 
 type MatchResult struct {
-	ID         int    `json:"id"`
+	ID         int        `json:"id"`
+	Week       int        `json:"week"`
+	Team1Name  string     `json:"team1_name"`
+	Team2Name  string     `json:"team2_name"`
+	Team1Score int        `json:"team1_score"`
+	Team2Score int        `json:"team2_score"`
+	Played     bool       `json:"played"`
+	Version    int        `json:"version"`
+	KickoffAt  *time.Time `json:"kickoff_at,omitempty"`
+}
+
+type HeadToHeadMatch struct {
+	Season     int    `json:"season"`
+	Archived   bool   `json:"archived"`
 	Week       int    `json:"week"`
+	Team1ID    int    `json:"team1_id"`
 	Team1Name  string `json:"team1_name"`
+	Team2ID    int    `json:"team2_id"`
 	Team2Name  string `json:"team2_name"`
 	Team1Score int    `json:"team1_score"`
 	Team2Score int    `json:"team2_score"`
 	Played     bool   `json:"played"`
 }
 
+type HeadToHead struct {
+	TeamID       int               `json:"team_id"`
+	TeamName     string            `json:"team_name"`
+	OpponentID   int               `json:"opponent_id"`
+	OpponentName string            `json:"opponent_name"`
+	Played       int               `json:"played"`
+	Wins         int               `json:"wins"`
+	Draws        int               `json:"draws"`
+	Losses       int               `json:"losses"`
+	GoalsFor     int               `json:"goals_for"`
+	GoalsAgainst int               `json:"goals_against"`
+	BiggestWin   *HeadToHeadMatch  `json:"biggest_win,omitempty"`
+	BiggestLoss  *HeadToHeadMatch  `json:"biggest_loss,omitempty"`
+	Matches      []HeadToHeadMatch `json:"matches"`
+}
+
+const (
+	SimulationActor = "simulation"
+	SanctionActor   = "sanction"
+	ImportActor     = "import"
+	AnonymousActor  = "anonymous"
+)
+
+// MatchChange describes who changed a match result and why.
+type MatchChange struct {
+	Actor  string `json:"actor"`
+	Reason string `json:"reason"`
+}
+
+type MatchEvent struct {
+	ID               int       `json:"id"`
+	MatchID          int       `json:"match_id"`
+	BeforeTeam1Score int       `json:"before_team1_score"`
+	BeforeTeam2Score int       `json:"before_team2_score"`
+	BeforePlayed     bool      `json:"before_played"`
+	AfterTeam1Score  int       `json:"after_team1_score"`
+	AfterTeam2Score  int       `json:"after_team2_score"`
+	AfterPlayed      bool      `json:"after_played"`
+	Actor            string    `json:"actor"`
+	Reason           string    `json:"reason,omitempty"`
+	RevertsEventID   int       `json:"reverts_event_id,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
 type Prediction struct {
 	TeamID           int     `json:"team_id"`
 	TeamName         string  `json:"team_name"`
 	ChampionshipOdds float64 `json:"championship_odds"`
 }
 
+const (
+	SanctionPointsDeduction = "points_deduction"
+	SanctionForfeit         = "forfeit"
+)
+
+type Sanction struct {
+	ID        int       `json:"id"`
+	TeamID    int       `json:"team_id"`
+	Type      string    `json:"type"`
+	Points    int       `json:"points,omitempty"`
+	MatchID   int       `json:"match_id,omitempty"`
+	Season    int       `json:"season"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const (
+	EventFixtureGenerated = "fixture_generated"
+	EventMatchPlayed      = "match_played"
+	EventResultCorrected  = "result_corrected"
+	EventWeekAdvanced     = "week_advanced"
+	EventLeagueRestarted  = "league_restarted"
+	EventPointsDeducted   = "points_deducted"
+	EventTeamCreated      = "team_created"
+	EventTeamUpdated      = "team_updated"
+	EventTeamDeleted      = "team_deleted"
+	EventSnapshotLoaded   = "snapshot_loaded"
+
+	EventPlayoffChampionDecided = "playoff_champion_decided"
+)
+
+// LeagueEvent is an entry of the append-only log the league state can be
+// rebuilt from. Payload holds one of the *Event structs below, or a
+// MatchEvent for played and corrected results.
+type LeagueEvent struct {
+	ID        int             `json:"id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type FixtureGeneratedEvent struct {
+	CurrentWeek int     `json:"current_week"`
+	TotalWeeks  int     `json:"total_weeks"`
+	Seed        int64   `json:"seed,omitempty"`
+	Matches     []Match `json:"matches"`
+}
+
+type WeekAdvancedEvent struct {
+	Week int `json:"week"`
+}
+
+type LeagueRestartedEvent struct {
+	Season int `json:"season"`
+}
+
+type TeamChangedEvent struct {
+	TeamID   int    `json:"team_id"`
+	Name     string `json:"name"`
+	Strength int    `json:"strength"`
+}
+
+type PointsDeductedEvent struct {
+	TeamID int `json:"team_id"`
+	Points int `json:"points"`
+}
+
+type PlayoffChampionDecidedEvent struct {
+	Season   int    `json:"season"`
+	TeamID   int    `json:"team_id"`
+	TeamName string `json:"team_name"`
+}
+
+// SnapshotFormatVersion is the version written to new snapshots. Loading
+// rejects any other version.
+const SnapshotFormatVersion = 1
+
+// Snapshot is the complete simulation state: the league row with its seed,
+// the teams with their statistics, every match of the fixture and the
+// playoffs of the season.
+type Snapshot struct {
+	FormatVersion  int            `json:"format_version"`
+	CreatedAt      time.Time      `json:"created_at"`
+	League         League         `json:"league"`
+	Teams          []Team         `json:"teams"`
+	Matches        []Match        `json:"matches"`
+	PlayoffConfig  PlayoffConfig  `json:"playoff_config"`
+	PlayoffMatches []PlayoffMatch `json:"playoff_matches"`
+}
+
+type SnapshotLoadOptions struct {
+	// Replace deletes the current teams and fixture before loading instead
+	// of requiring an empty league.
+	Replace bool
+}
+
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
+// APIKey identifies a client of the API. Only a hash of the key is stored,
+// the key itself is shown once when it is created.
+type APIKey struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+const (
+	WebhookWeekPlayed      = "week_played"
+	WebhookResultEdited    = "result_edited"
+	WebhookChampionDecided = "champion_decided"
+	WebhookLeagueRestarted = "league_restarted"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is a URL notified of league events. The secret signs every
+// delivery and is only returned when the webhook is created.
+type Webhook struct {
+	ID         int       `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,url,max=2048"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16,max=255"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=week_played result_edited champion_decided league_restarted"`
+}
+
+// WebhookDelivery is one event sent, or still to be sent, to a webhook.
+// Pending deliveries are retried until NextAttemptAt stops moving.
+type WebhookDelivery struct {
+	ID             int             `json:"id"`
+	WebhookID      int             `json:"webhook_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+type WeekPlayedPayload struct {
+	Season  int           `json:"season"`
+	Week    int           `json:"week"`
+	Matches []MatchResult `json:"matches"`
+}
+
+type ChampionDecidedPayload struct {
+	Season   int  `json:"season"`
+	Champion Team `json:"champion"`
+}
+
+// LeagueSchedule advances the league in the background, on a cron
+// expression or a fixed interval. NextRunAt is unset while it is paused.
+type LeagueSchedule struct {
+	LeagueID  int        `json:"league_id"`
+	Cron      string     `json:"cron,omitempty"`
+	Interval  string     `json:"interval,omitempty"`
+	Paused    bool       `json:"paused"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type ScheduleRequest struct {
+	Cron     string `json:"cron" validate:"required_without=Interval,excluded_with=Interval"`
+	Interval string `json:"interval" validate:"required_without=Cron"`
+}
+
+const (
+	JobPlayAll     = "play_all"
+	JobPredictions = "predictions"
+)
+
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job is a long-running operation run in the background. Result is set once
+// the job has succeeded; Error and ErrorCode once it has failed.
+type Job struct {
+	ID              int             `json:"id"`
+	Type            string          `json:"type"`
+	Status          string          `json:"status"`
+	Simulations     int             `json:"simulations,omitempty"`
+	Progress        JobProgress     `json:"progress"`
+	Result          json.RawMessage `json:"result,omitempty"`
+	Error           string          `json:"error,omitempty"`
+	ErrorCode       string          `json:"error_code,omitempty"`
+	CancelRequested bool            `json:"cancel_requested"`
+	CreatedAt       time.Time       `json:"created_at"`
+	StartedAt       *time.Time      `json:"started_at,omitempty"`
+	FinishedAt      *time.Time      `json:"finished_at,omitempty"`
+	// HeartbeatAt is refreshed while the server running the job is alive.
+	HeartbeatAt time.Time `json:"-"`
+}
+
+// JobProgress counts the weeks played or the seasons simulated. Total is 0
+// until it is known.
+type JobProgress struct {
+	Done  int    `json:"done"`
+	Total int    `json:"total"`
+	Unit  string `json:"unit"`
+}
+
+type CreateJobRequest struct {
+	Type        string `json:"type" validate:"required,oneof=play_all predictions"`
+	Simulations int    `json:"simulations" validate:"omitempty,min=1,max=1000000"`
+}
+
+// CalendarConfig maps the weeks of the fixture to real dates. A round is
+// played on the first matchday on or after the day after the previous round,
+// spread over the kick-off times of that matchday and the matchdays directly
+// following it. Midweek rounds are played on a midweek matchday instead, and
+// no round is played on a blackout date. Times are in Timezone, UTC when
+// empty.
+type CalendarConfig struct {
+	StartDate           string     `json:"start_date" validate:"required,datetime=2006-01-02"`
+	Timezone            string     `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	Matchdays           []string   `json:"matchdays" validate:"required,min=1,max=7,dive,oneof=monday tuesday wednesday thursday friday saturday sunday"`
+	KickoffTimes        []string   `json:"kickoff_times" validate:"required,min=1,max=24,dive,datetime=15:04"`
+	MidweekRounds       []int      `json:"midweek_rounds,omitempty" validate:"dive,min=1"`
+	MidweekMatchdays    []string   `json:"midweek_matchdays,omitempty" validate:"max=7,dive,oneof=monday tuesday wednesday thursday friday saturday sunday"`
+	MidweekKickoffTimes []string   `json:"midweek_kickoff_times,omitempty" validate:"max=24,dive,datetime=15:04"`
+	Blackouts           []Blackout `json:"blackouts,omitempty" validate:"dive"`
+}
+
+// Blackout is a range of dates, both included, without matches, such as an
+// international break.
+type Blackout struct {
+	From   string `json:"from" validate:"required,datetime=2006-01-02"`
+	To     string `json:"to" validate:"required,datetime=2006-01-02"`
+	Reason string `json:"reason,omitempty" validate:"max=255"`
+}
+
+// FixtureConfig sets the constraints the fixture generator searches for.
+// Teams whose "stadium" metadata matches never play at home in the same week.
+type FixtureConfig struct {
+	// Cycles is how many times every team meets every other team, 2 when
+	// zero. Every other cycle mirrors the first with home and away swapped.
+	Cycles int `json:"cycles,omitempty" validate:"omitempty,min=1,max=4"`
+	// SplitAfter splits the table in a top and a bottom half once that many
+	// weeks have been played. The fixture ends there and every half then
+	// plays a single round robin among itself. Zero disables the split.
+	SplitAfter int `json:"split_after,omitempty" validate:"omitempty,min=1"`
+	// MaxConsecutive is the longest run of home or of away games a team may
+	// play, 2 when zero.
+	MaxConsecutive int `json:"max_consecutive,omitempty" validate:"omitempty,min=1,max=10"`
+	// Shuffle randomises the team order and the order of the rounds, so
+	// every season gets a different fixture.
+	Shuffle bool    `json:"shuffle"`
+	Derbies []Derby `json:"derbies,omitempty" validate:"dive"`
+}
+
+// Derby asks for the two teams to meet in Week.
+type Derby struct {
+	Team1ID int `json:"team1_id" validate:"required,min=1"`
+	Team2ID int `json:"team2_id" validate:"required,min=1,nefield=Team1ID"`
+	Week    int `json:"week" validate:"required,min=1"`
+}
+
+type LeagueFixtureConfig struct {
+	LeagueID int `json:"league_id"`
+	FixtureConfig
+	// LastReport is the report of the latest fixture generated.
+	LastReport *FixtureReport `json:"last_report,omitempty"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+const (
+	ConstraintSharedStadium  = "shared_stadium"
+	ConstraintMaxConsecutive = "max_consecutive"
+	ConstraintDerbyWeek      = "derby_week"
+)
+
+// FixtureReport lists the constraints the best fixture found breaks.
+type FixtureReport struct {
+	Attempts  int               `json:"attempts"`
+	Satisfied bool              `json:"satisfied"`
+	Unmet     []UnmetConstraint `json:"unmet"`
+}
+
+type UnmetConstraint struct {
+	Constraint string   `json:"constraint"`
+	Week       int      `json:"week,omitempty"`
+	Teams      []string `json:"teams"`
+	Message    string   `json:"message"`
+}
+
+// FixturePreview is a fixture generated without being saved.
+type FixturePreview struct {
+	Matches []MatchResult `json:"matches"`
+	Report  FixtureReport `json:"report"`
+}
+
+// FixtureAnalysis measures how fair the generated fixture is to every team.
+type FixtureAnalysis struct {
+	Weeks int `json:"weeks"`
+	// CycleWeeks is the length of a cycle, in which every team meets every
+	// other team once.
+	CycleWeeks  int `json:"cycle_weeks"`
+	TotalBreaks int `json:"total_breaks"`
+	MaxBreaks   int `json:"max_breaks"`
+	// StrengthSpread is the gap between the hardest and the easiest season
+	// strength of schedule.
+	StrengthSpread float64 `json:"strength_spread"`
+	// MinRestDays is the shortest rest of any team, nil when no match has a
+	// kick-off time.
+	MinRestDays *float64              `json:"min_rest_days,omitempty"`
+	Teams       []TeamFixtureAnalysis `json:"teams"`
+}
+
+type TeamFixtureAnalysis struct {
+	TeamID   int    `json:"team_id"`
+	TeamName string `json:"team_name"`
+	// Venues has one letter per week: H at home, A away and - for a week off.
+	Venues    string `json:"venues"`
+	HomeGames int    `json:"home_games"`
+	AwayGames int    `json:"away_games"`
+	// Breaks counts the games played at the same venue as the game before,
+	// BreakWeeks lists their weeks.
+	Breaks             int                `json:"breaks"`
+	BreakWeeks         []int              `json:"break_weeks"`
+	RestDays           *RestDays          `json:"rest_days,omitempty"`
+	StrengthOfSchedule StrengthOfSchedule `json:"strength_of_schedule"`
+	SecondHalf         HalfBalance        `json:"second_half"`
+	Cycles             []CycleBalance     `json:"cycles"`
+}
+
+// RestDays are the days between the kick-offs of consecutive games.
+type RestDays struct {
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	Average float64 `json:"average"`
+}
+
+// StrengthOfSchedule is the average strength of the opponents.
+type StrengthOfSchedule struct {
+	FirstHalf  float64 `json:"first_half"`
+	SecondHalf float64 `json:"second_half"`
+	Season     float64 `json:"season"`
+}
+
+// HalfBalance compares the second half of the season with the first. The
+// halves are made of whole cycles, the first one taking the extra cycle of an
+// odd number; the rounds after a split belong to the second.
+type HalfBalance struct {
+	FirstHalfHome  int `json:"first_half_home"`
+	SecondHalfHome int `json:"second_half_home"`
+	// Mirrored counts the second-half games replaying the game of the same
+	// round of the cycle before with home and away swapped.
+	Mirrored int `json:"mirrored"`
+	Games    int `json:"games"`
+}
+
+// CycleBalance measures the games of a team in one cycle of the fixture, or
+// in the rounds after the split of a split league.
+type CycleBalance struct {
+	Cycle     int  `json:"cycle"`
+	Split     bool `json:"split,omitempty"`
+	Games     int  `json:"games"`
+	HomeGames int  `json:"home_games"`
+	// Mirrored counts the games replaying the game of the same round of the
+	// cycle before with home and away swapped.
+	Mirrored int `json:"mirrored"`
+	// StrengthOfSchedule is the average strength of the opponents.
+	StrengthOfSchedule float64 `json:"strength_of_schedule"`
+}
+
+// PlayoffConfig is the post-season stage played once the regular season is
+// over.
+type PlayoffConfig struct {
+	// Pairings are the ties of the first round as two league positions each.
+	// The winners of consecutive ties meet in the next round until one team
+	// is left, so there must be 1, 2, 4 or 8 of them.
+	Pairings [][2]int `json:"pairings" validate:"max=8,dive,dive,min=1"`
+}
+
+type LeaguePlayoffConfig struct {
+	LeagueID int `json:"league_id"`
+	PlayoffConfig
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// KnockoutResult is a match that needs a winner. A draw goes to extra time,
+// whose goals are included in the score, and then to penalties.
+type KnockoutResult struct {
+	Team1Score     int  `json:"team1_score"`
+	Team2Score     int  `json:"team2_score"`
+	ExtraTime      bool `json:"extra_time"`
+	Team1Penalties *int `json:"team1_penalties,omitempty"`
+	Team2Penalties *int `json:"team2_penalties,omitempty"`
+}
+
+// PlayoffMatch is a tie of the playoffs. Team1 is the higher seed and plays
+// at home; seeds are league positions.
+type PlayoffMatch struct {
+	ID        int    `json:"id"`
+	Season    int    `json:"season"`
+	Round     int    `json:"round"`
+	Slot      int    `json:"slot"`
+	Team1ID   int    `json:"team1_id"`
+	Team1Name string `json:"team1_name"`
+	Team1Seed int    `json:"team1_seed"`
+	Team2ID   int    `json:"team2_id"`
+	Team2Name string `json:"team2_name"`
+	Team2Seed int    `json:"team2_seed"`
+	KnockoutResult
+	Played     bool   `json:"played"`
+	WinnerName string `json:"winner_name,omitempty"`
+}
+
+type PlayoffRound struct {
+	Round   int            `json:"round"`
+	Name    string         `json:"name"`
+	Matches []PlayoffMatch `json:"matches"`
+}
+
+// Playoffs is the bracket of a season: the rounds played and, when it can
+// be drawn, the next one.
+type Playoffs struct {
+	Season           int            `json:"season"`
+	Rounds           []PlayoffRound `json:"rounds"`
+	ChampionTeamName string         `json:"champion_team_name,omitempty"`
+}
+
+type LeagueCalendar struct {
+	LeagueID int `json:"league_id"`
+	CalendarConfig
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LeagueState is the league as rebuilt by replaying the event log up to EventID.
+type LeagueState struct {
+	EventID   int     `json:"event_id"`
+	League    League  `json:"league"`
+	Standings []Team  `json:"standings"`
+	Matches   []Match `json:"matches"`
+}
+
 type Response struct {
-	Status  string      `json:"status"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   interface{} `json:"error,omitempty"`
-	Message string      `json:"message,omitempty"`
+	Status  string       `json:"status"`
+	Data    interface{}  `json:"data,omitempty"`
+	Code    string       `json:"code,omitempty"`
+	Message string       `json:"message,omitempty"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError reports why one field of a request was rejected. Field is the
+// JSON name of the field, or the name of the path or query parameter.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// WeekPlayResult is returned by the next week endpoint. Champion is set once
+// the last week has been played.
+type WeekPlayResult struct {
+	PlayedMatches []Match `json:"played_matches"`
+	Champion      *Team   `json:"champion"`
+}
+
+type PlayAllResult struct {
+	PlayedMatches []MatchResult `json:"played_matches"`
+	Champion      *Team         `json:"champion"`
 }
 
 type UpdateMatchRequest struct {
-	Team1Score int `json:"team1_score"`
-	Team2Score int `json:"team2_score"`
+	Team1Score int    `json:"team1_score" validate:"min=0"`
+	Team2Score int    `json:"team2_score" validate:"min=0"`
+	Actor      string `json:"actor"`
+	Reason     string `json:"reason"`
+}
+
+type SanctionRequest struct {
+	TeamID  int    `json:"team_id" validate:"required"`
+	Type    string `json:"type" validate:"required,oneof=points_deduction forfeit"`
+	Points  int    `json:"points" validate:"required_if=Type points_deduction,gte=0"`
+	MatchID int    `json:"match_id" validate:"required_if=Type forfeit"`
+	Reason  string `json:"reason" validate:"required"`
+}
+
+type CreateTeamRequest struct {
+	Name     string            `json:"name" validate:"required,max=255"`
+	Strength int               `json:"strength" validate:"required,min=1,max=100"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+type PatchTeamRequest struct {
+	Name     *string           `json:"name" validate:"omitempty,min=1,max=255"`
+	Strength *int              `json:"strength" validate:"omitempty,min=1,max=100"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionSkip   = "skip"
+)
+
+type TeamImportOptions struct {
+	DryRun bool `json:"dry_run"`
+	Upsert bool `json:"upsert"`
+}
+
+type ImportError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type TeamImportResult struct {
+	Row    int    `json:"row"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+}
+
+type TeamImportReport struct {
+	DryRun  bool               `json:"dry_run"`
+	Created int                `json:"created"`
+	Updated int                `json:"updated"`
+	Skipped int                `json:"skipped"`
+	Teams   []TeamImportResult `json:"teams"`
+	Errors  []ImportError      `json:"errors,omitempty"`
+}
+
+type SeasonImportOptions struct {
+	LeagueName     string `json:"league_name,omitempty"`
+	UpdateStrength bool   `json:"update_strength"`
+	DryRun         bool   `json:"dry_run"`
+}
+
+type SeasonImportReport struct {
+	DryRun       bool          `json:"dry_run"`
+	Matches      int           `json:"matches"`
+	Weeks        int           `json:"weeks"`
+	TeamsCreated []string      `json:"teams_created"`
+	TeamsUpdated []string      `json:"teams_updated"`
+	OtherTeams   []string      `json:"other_teams,omitempty"`
+	Champion     string        `json:"champion,omitempty"`
+	Errors       []ImportError `json:"errors,omitempty"`
+}
+
+// ExportFilter narrows an export down to one team and/or a range of weeks.
+// Zero values leave the corresponding bound open.
+type ExportFilter struct {
+	TeamID   int
+	FromWeek int
+	ToWeek   int
+}
+
+const (
+	PeriodToday   = "today"
+	PeriodWeekend = "weekend"
+	PeriodWeek    = "week"
+)
+
+// FixtureFilter selects matches by kick-off date, either a named Period
+// starting today or the dates From to To, both included. Dates are read in
+// the timezone of the calendar.
+type FixtureFilter struct {
+	Period string
+	From   time.Time
+	To     time.Time
 }
@@ -2,9 +2,13 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"football-simulation/types"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -33,11 +37,98 @@ func WriteSuccess(w http.ResponseWriter, status int, data interface{}) {
 	WriteJSON(w, status, response)
 }
 
+// WriteError writes err with the given status. The code of a domain error is
+// passed on; other errors get a generic code for the status.
 func WriteError(w http.ResponseWriter, status int, err error) {
 	response := types.Response{
 		Status:  "error",
-		Error:   err,
-		Message: err.Error(),
+		Code:    codeForStatus(status),
+		Message: http.StatusText(status),
 	}
+
+	if err != nil {
+		response.Message = err.Error()
+
+		var domainErr *types.Error
+		if errors.As(err, &domainErr) {
+			response.Code = domainErr.Code
+		}
+	}
+
 	WriteJSON(w, status, response)
 }
+
+// WriteServiceError writes an error returned by a service with the status of
+// its kind. Any other error is logged and answered with a generic 500, so
+// driver messages never reach clients.
+func WriteServiceError(w http.ResponseWriter, err error) {
+	var domainErr *types.Error
+	if !errors.As(err, &domainErr) {
+		log.Printf("internal error: %v", err)
+		WriteError(w, http.StatusInternalServerError, nil)
+		return
+	}
+
+	WriteError(w, StatusForError(err), err)
+}
+
+func StatusForError(err error) int {
+	var domainErr *types.Error
+	if !errors.As(err, &domainErr) {
+		return http.StatusInternalServerError
+	}
+
+	switch domainErr.Kind {
+	case types.ErrorKindValidation:
+		return http.StatusBadRequest
+	case types.ErrorKindNotFound:
+		return http.StatusNotFound
+	case types.ErrorKindConflict:
+		return http.StatusConflict
+	case types.ErrorKindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case types.ErrorKindUnauthorized:
+		return http.StatusUnauthorized
+	case types.ErrorKindForbidden:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "invalid_request"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusPreconditionFailed:
+		return "precondition_failed"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	}
+	return "internal_error"
+}
+
+// ParseIfMatch returns the version carried by the If-Match header, or 0 when
+// the header is absent or "*".
+func ParseIfMatch(r *http.Request) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(header, "W/"), `"`))
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("invalid If-Match header %q", header)
+	}
+
+	return version, nil
+}
+
+func SetETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", fmt.Sprintf(`"%d"`, version))
+}
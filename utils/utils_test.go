@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"football-simulation/types"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStatusForError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{types.NewError(types.ErrorKindValidation, "same_team", "same team"), http.StatusBadRequest},
+		{types.NewError(types.ErrorKindNotFound, "match_not_found", "match not found"), http.StatusNotFound},
+		{types.NewError(types.ErrorKindConflict, "league_busy", "busy"), http.StatusConflict},
+		{types.NewError(types.ErrorKindPreconditionFailed, "precondition_failed", "stale"), http.StatusPreconditionFailed},
+		{types.NewError(types.ErrorKindUnauthorized, "unauthorized", "no key"), http.StatusUnauthorized},
+		{types.NewError(types.ErrorKindForbidden, "forbidden", "viewer"), http.StatusForbidden},
+		{types.NewError(types.ErrorKindInternal, "broken", "broken"), http.StatusInternalServerError},
+		{fmt.Errorf("loading: %w", types.NewError(types.ErrorKindNotFound, "team_not_found", "team not found")), http.StatusNotFound},
+		{errors.New("pq: connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		if got := StatusForError(tt.err); got != tt.want {
+			t.Errorf("StatusForError(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestCodeForStatus(t *testing.T) {
+	tests := map[int]string{
+		http.StatusBadRequest:          "invalid_request",
+		http.StatusNotFound:            "not_found",
+		http.StatusConflict:            "conflict",
+		http.StatusPreconditionFailed:  "precondition_failed",
+		http.StatusUnauthorized:        "unauthorized",
+		http.StatusForbidden:           "forbidden",
+		http.StatusInternalServerError: "internal_error",
+		http.StatusTeapot:              "internal_error",
+	}
+
+	for status, want := range tests {
+		if got := codeForStatus(status); got != want {
+			t.Errorf("codeForStatus(%d) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestWriteServiceError(t *testing.T) {
+	var logged bytes.Buffer
+	output := log.Writer()
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(output) })
+
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantCode    string
+		wantMessage string
+	}{
+		{"domain error", types.NewError(types.ErrorKindNotFound, "match_not_found", "match not found").Errorf("match %d not found", 42), http.StatusNotFound, "match_not_found", "match 42 not found"},
+		{"wrapped domain error", fmt.Errorf("%w: week 0", types.NewError(types.ErrorKindValidation, "invalid_snapshot", "invalid snapshot")), http.StatusBadRequest, "invalid_snapshot", "invalid snapshot: week 0"},
+		{"driver error", errors.New(`pq: relation "matches" does not exist`), http.StatusInternalServerError, "internal_error", "Internal Server Error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logged.Reset()
+			recorder := httptest.NewRecorder()
+			WriteServiceError(recorder, tt.err)
+
+			var response types.Response
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+				t.Fatalf("decoding the response: %v", err)
+			}
+			if recorder.Code != tt.wantStatus || response.Code != tt.wantCode || response.Message != tt.wantMessage {
+				t.Errorf("WriteServiceError = %d %+v, want %d %s %q", recorder.Code, response, tt.wantStatus, tt.wantCode, tt.wantMessage)
+			}
+
+			// only errors hidden from the client are logged
+			if wantLogged := tt.wantStatus == http.StatusInternalServerError; strings.Contains(logged.String(), tt.err.Error()) != wantLogged {
+				t.Errorf("log = %q, want the error logged: %v", logged.String(), wantLogged)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"football-simulation/types"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// report fields by their JSON name, the one clients send
+	Validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+}
+
+// ParseAndValidate decodes the request body into payload and runs the
+// validator tags on it, writing a 400 response with the offending fields and
+// returning false when either step fails.
+func ParseAndValidate(w http.ResponseWriter, r *http.Request, payload any) bool {
+	if err := ParseJSON(r, payload); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			WriteValidationError(w, []types.FieldError{{
+				Field:   typeErr.Field,
+				Message: fmt.Sprintf("must be of type %s", jsonType(typeErr.Type)),
+			}})
+			return false
+		}
+		WriteError(w, http.StatusBadRequest, err)
+		return false
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		WriteValidationError(w, FieldErrors(err))
+		return false
+	}
+
+	return true
+}
+
+// FieldErrors converts the error returned by Validate into one FieldError
+// per failed rule.
+func FieldErrors(err error) []types.FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []types.FieldError{{Message: err.Error()}}
+	}
+
+	fieldErrors := make([]types.FieldError, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		fieldErrors = append(fieldErrors, types.FieldError{
+			Field:   fieldErr.Field(),
+			Message: fieldMessage(fieldErr),
+		})
+	}
+	return fieldErrors
+}
+
+func WriteValidationError(w http.ResponseWriter, fieldErrors []types.FieldError) {
+	WriteJSON(w, http.StatusBadRequest, types.Response{
+		Status:  "error",
+		Code:    "validation_failed",
+		Message: "the request is invalid",
+		Errors:  fieldErrors,
+	})
+}
+
+func fieldMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "required_if":
+		condition := strings.Fields(fieldErr.Param())
+		if len(condition) == 2 {
+			return fmt.Sprintf("is required when %s is %s", strings.ToLower(condition[0]), condition[1])
+		}
+		return "is required"
+	case "required_without":
+		return fmt.Sprintf("is required when %s is not given", strings.ToLower(fieldErr.Param()))
+	case "excluded_with":
+		return fmt.Sprintf("cannot be given together with %s", strings.ToLower(fieldErr.Param()))
+	case "nefield":
+		return fmt.Sprintf("must differ from %s", snakeCase(fieldErr.Param()))
+	case "url":
+		return "must be an absolute URL"
+	case "datetime":
+		switch fieldErr.Param() {
+		case "2006-01-02":
+			return "must be a date in the format YYYY-MM-DD"
+		case "15:04":
+			return "must be a time in the format HH:MM"
+		}
+		return fmt.Sprintf("must be in the format %s", fieldErr.Param())
+	case "timezone":
+		return "must be an IANA timezone such as Europe/London"
+	case "min", "gte":
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fieldErr.Param())
+	case "max", "lte":
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fieldErr.Param(), " ", ", "))
+	}
+	return fmt.Sprintf("failed on the '%s' rule", fieldErr.Tag())
+}
+
+// snakeCase turns a struct field name such as Team1ID into its JSON name.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) && !unicode.IsUpper(rune(name[i-1])) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}